@@ -1,6 +1,14 @@
 package v1
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,13 +21,1512 @@ type ModelDeploymentSpec struct {
 	NodeSelectorLabels map[string]string `json:"nodeSelectorLabels,omitempty"`
 	Replicas           int32             `json:"replicas,omitempty"`
 	Runtime            string            `json:"runtime,omitempty"`
-	MaxModelLength     int32             `json:"maxModelLength,omitempty"`
+
+	// TemplateName names the ModelDeploymentTemplate this ModelDeployment
+	// inherits the fields it leaves unset from. The spec merged with the
+	// template is validated, and the ModelDeployment is degraded while it
+	// is invalid.
+	// +optional
+	TemplateName string `json:"templateName,omitempty"`
+
+	// MaxModelLength caps the context length served, through
+	// --max-model-len. When unset vLLM uses the context length the model was
+	// trained with, from its config.
+	// +optional
+	MaxModelLength int32 `json:"maxModelLength,omitempty"`
+
+	// GPUTopologyAware requires gpu runtime pods to land on nodes whose kubelet
+	// aligns devices to a single NUMA node. It assumes cluster admins label such
+	// nodes with kaimera.ai/topology-manager-policy=single-numa-node (i.e. the
+	// kubelet runs with --topology-manager-policy=single-numa-node).
+	GPUTopologyAware bool `json:"gpuTopologyAware,omitempty"`
+
+	// SmokeTest runs a one-shot Job sending a canned completion to the
+	// service once the deployment is ready. The result is recorded in the
+	// SmokeTest condition.
+	SmokeTest bool `json:"smokeTest,omitempty"`
+
+	// MaxSeqLenToCapture is the maximum sequence length covered by CUDA
+	// graphs. Larger values use more GPU memory.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSeqLenToCapture int32 `json:"maxSeqLenToCapture,omitempty"`
+
+	// SchedulerName selects the scheduler for the model pods, e.g. a GPU
+	// aware scheduler such as Volcano. Defaults to the cluster scheduler.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// Kueue admits the model pods through a Kueue LocalQueue
+	// +optional
+	Kueue *KueueSpec `json:"kueue,omitempty"`
+
+	// ImageDigest pins the runtime image to a digest (sha256:...) instead of
+	// a mutable tag.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	ImageDigest string `json:"imageDigest,omitempty"`
+
+	// APIKeySecretRef requires clients to present the referenced key as a
+	// bearer token. vLLM only enforces it on the /v1 API, so /metrics stays
+	// scrapeable without credentials.
+	// +optional
+	APIKeySecretRef *corev1.SecretKeySelector `json:"apiKeySecretRef,omitempty"`
+
+	// LoadFormat overrides the format vLLM loads weights in. "dummy"
+	// initialises random weights, which is useful for testing without
+	// downloading the model.
+	// +kubebuilder:validation:Enum=auto;pt;safetensors;npcache;dummy
+	// +optional
+	LoadFormat string `json:"loadFormat,omitempty"`
+
+	// Labels are added to all generated child resources, so external tooling
+	// can select them consistently
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to all generated child resources
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// PodAnnotations are added to the pod template, e.g.
+	// sidecar.istio.io/inject or linkerd.io/inject for service meshes.
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// DistributedExecutorBackend selects how vLLM runs multi-GPU workers.
+	// "mp" uses local processes. "ray" starts a local Ray instance in the pod
+	// unless RAY_ADDRESS points at an existing cluster, and needs an image
+	// with Ray installed.
+	// +kubebuilder:validation:Enum=mp;ray
+	// +optional
+	DistributedExecutorBackend string `json:"distributedExecutorBackend,omitempty"`
+
+	// GPUCount is the number of GPUs each replica of the gpu runtime
+	// requests. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	GPUCount int32 `json:"gpuCount,omitempty"`
+
+	// RampUp scales up one replica at a time, waiting for the previous
+	// replicas to become ready, so heavy models don't saturate download
+	// bandwidth or GPU allocation.
+	// +optional
+	RampUp bool `json:"rampUp,omitempty"`
+
+	// ModelCache mounts a persistent volume as the Hugging Face cache so
+	// weights survive pod restarts
+	// +optional
+	ModelCache *ModelCacheSpec `json:"modelCache,omitempty"`
+
+	// LoRAAdapters are served on top of the base model from directories of
+	// the model cache claim, each as a model of its own name, so many
+	// fine-tunes share one deployment. Requires modelCache.
+	// +optional
+	LoRAAdapters []LoRAAdapter `json:"loraAdapters,omitempty"`
+
+	// LoRAHotReload loads and unloads loraAdapters on the running replicas
+	// through the runtime's API when the list changes, instead of rolling
+	// the replicas out. The adapters loaded on every ready replica are
+	// reported in status.loraAdapters. Requires modelCache.
+	// +optional
+	LoRAHotReload bool `json:"loraHotReload,omitempty"`
+
+	// ConfigDriftCheck asks a ready replica which model it serves through
+	// the runtime's /v1/models endpoint on every reconcile, reports it in
+	// status.servedModel and sets the ConfigDrift condition when it is not
+	// modelName
+	// +optional
+	ConfigDriftCheck bool `json:"configDriftCheck,omitempty"`
+
+	// GPUProduct requires gpu runtime pods to run on nodes with this GPU
+	// model, e.g. NVIDIA-A100-SXM4-80GB
+	// +optional
+	GPUProduct string `json:"gpuProduct,omitempty"`
+
+	// MinCUDAVersion requires gpu runtime pods to run on nodes whose driver
+	// supports at least this CUDA version, e.g. 12.4, as reported by the
+	// nvidia.com/cuda.runtime.major and .minor labels of GPU feature
+	// discovery. Nodes without the labels are excluded.
+	// +kubebuilder:validation:Pattern=`^[0-9]+\.[0-9]+$`
+	// +optional
+	MinCUDAVersion string `json:"minCUDAVersion,omitempty"`
+
+	// GPUProductLabel is the node label holding the GPU model. Defaults to
+	// nvidia.com/gpu.product as set by GPU feature discovery.
+	// +optional
+	GPUProductLabel string `json:"gpuProductLabel,omitempty"`
+
+	// NodePool is the node autoscaler's pool the replicas run on, e.g. a
+	// Karpenter NodePool or a cluster autoscaler node group. The pods select
+	// the pool's label, and tolerate its taint, with the keys configured on
+	// the controller, so the autoscaler provisions nodes from the pool when
+	// they don't fit on existing nodes.
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
+
+	// Karpenter adds node requirements guiding Karpenter to provision
+	// suitable instances for the replicas when none fit
+	// +optional
+	Karpenter *KarpenterSpec `json:"karpenter,omitempty"`
+
+	// WedgedGPU treats repeated liveness probe failures of a replica as a
+	// sign of a wedged GPU on its node, which is reported with a Warning
+	// event and, optionally, taken out of scheduling
+	// +optional
+	WedgedGPU *WedgedGPUSpec `json:"wedgedGPU,omitempty"`
+
+	// ReadOnlyRootFilesystem runs the model container with a read-only root
+	// filesystem, mounting empty dirs for the paths vLLM writes to.
+	// +optional
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem,omitempty"`
+
+	// GPUResourceName is the extended resource GPUs are requested as, for
+	// clusters whose device plugin doesn't use nvidia.com/gpu. Defaults to
+	// nvidia.com/gpu.
+	// +optional
+	GPUResourceName string `json:"gpuResourceName,omitempty"`
+
+	// GPUTolerationKey is the taint key on GPU nodes tolerated by the gpu
+	// runtime. Defaults to the GPU resource name.
+	// +optional
+	GPUTolerationKey string `json:"gpuTolerationKey,omitempty"`
+
+	// Port serves the model on a single port number used by the container,
+	// the Service and its target, which also serves /metrics. By default
+	// vLLM listens on 8000 and the Service exposes it on port 80 plus a
+	// dedicated metrics port 8000.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Tracing exports OpenTelemetry spans for requests to a collector
+	// +optional
+	Tracing *TracingSpec `json:"tracing,omitempty"`
+
+	// ChatTemplate is a Jinja chat template used instead of the one shipped
+	// with the model. It is stored in a ConfigMap owned by the
+	// ModelDeployment.
+	// +optional
+	ChatTemplate string `json:"chatTemplate,omitempty"`
+
+	// SystemPrompt is added as the system message of conversations that
+	// don't start with one. It is injected through the chat template, so it
+	// requires chatTemplate.
+	// +optional
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+
+	// ResponseRole is the role of the generated messages when the request
+	// doesn't ask for one, passed as --response-role. Defaults to assistant.
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z_]*$`
+	// +kubebuilder:validation:MaxLength=32
+	// +optional
+	ResponseRole string `json:"responseRole,omitempty"`
+
+	// ReplicasPerNode runs this many replicas for every schedulable node
+	// matching the node selector (and GPU product, if set) instead of a fixed
+	// count. Replicas is ignored when it is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ReplicasPerNode int32 `json:"replicasPerNode,omitempty"`
+
+	// Hostname sets the hostname of the model pods
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// Subdomain names a headless Service in the namespace, giving the pods
+	// the DNS name <hostname>.<subdomain>.<namespace>.svc
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Subdomain string `json:"subdomain,omitempty"`
+
+	// ContainerName names the container running the model server in the
+	// pods. It may not be the name of a container the controller adds:
+	// log-shipper, audit-log-rotator, model-download or gpu-tuning.
+	// Defaults to model-server.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
+
+	// Shadow deploys a second model next to this one. The proxy mirrors a
+	// copy of every request to it and discards its responses, so a new
+	// version can be tried against production traffic.
+	// +optional
+	Shadow *ShadowSpec `json:"shadow,omitempty"`
+
+	// WorkloadType selects the workload running the model replicas: a
+	// Deployment, an Argo Rollout for progressive delivery configured by
+	// rollout, or a StatefulSet giving each replica a stable name. The Argo
+	// Rollouts CRDs must be installed, otherwise the TypesRegistered
+	// condition reports them missing. On a change, the previous workload
+	// keeps serving until all replicas of the new one are available.
+	// Defaults to Deployment.
+	// +optional
+	WorkloadType WorkloadType `json:"workloadType,omitempty"`
+
+	// Rollout configures the strategy of the Argo Rollout
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
+	// ReplicaServices creates a Service per replica, named after the
+	// ModelDeployment and the replica's ordinal, e.g. llama-replica-0,
+	// routing to that replica only, so routers can address each replica by
+	// a stable name. The Services of replicas removed by a scale down are
+	// deleted.
+	// Requires the StatefulSet workload type.
+	// +optional
+	ReplicaServices bool `json:"replicaServices,omitempty"`
+
+	// CPUFallback deploys the model on the cpu runtime when the gpu
+	// runtime's pods stay unschedulable, e.g. while GPU capacity is
+	// exhausted. The Service is switched to the fallback until enough GPU
+	// replicas are ready again, then the fallback is removed. The
+	// CPUFallback condition reports when it is serving.
+	// +optional
+	CPUFallback *CPUFallbackSpec `json:"cpuFallback,omitempty"`
+
+	// PrefixCache deploys a companion Redis the replicas share their KV
+	// cache through with LMCache, so a prefix computed by one replica is
+	// reused by the others. The cache has no password; a NetworkPolicy
+	// admits only the model pods to it.
+	// +optional
+	PrefixCache *PrefixCacheSpec `json:"prefixCache,omitempty"`
+
+	// SchedulingGates hold the model pods back from scheduling until an
+	// external controller, e.g. for quota or cost approval, removes them.
+	// Removing a gate from the spec rolls out pods without it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	SchedulingGates []corev1.PodSchedulingGate `json:"schedulingGates,omitempty"`
+
+	// MaxConcurrentRequests caps how many requests each replica processes at
+	// once. It maps to vLLM's --max-num-seqs; requests beyond it wait in
+	// vLLM's queue instead of competing for GPU memory.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentRequests int32 `json:"maxConcurrentRequests,omitempty"`
+
+	// CPUOffloadGB offloads up to this many GiB of the model weights of each
+	// GPU to host memory through vLLM's --cpu-offload-gb, so a model larger
+	// than GPU memory still fits, at the cost of speed. The runtime container
+	// requests the offloaded memory of all its GPUs; evictionPriority's
+	// memory, which replaces that request, must cover it.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CPUOffloadGB int32 `json:"cpuOffloadGB,omitempty"`
+
+	// Offline stops the runtime from contacting the Hugging Face Hub, for
+	// air-gapped clusters. The model must already be in the cache, so it
+	// requires modelCache.
+	// +optional
+	Offline bool `json:"offline,omitempty"`
+
+	// Discovery annotates the Service so external registries can find the
+	// model and route to it
+	// +optional
+	Discovery *DiscoverySpec `json:"discovery,omitempty"`
+
+	// StrictZoneBalance spreads the replicas evenly across zones with a hard
+	// topology spread constraint: a pod stays pending rather than
+	// unbalancing the zones by more than one replica. The achieved
+	// distribution is reported in status.zoneReplicas.
+	// +optional
+	StrictZoneBalance bool `json:"strictZoneBalance,omitempty"`
+
+	// ResourceClaims allocates devices to the model pods through Dynamic
+	// Resource Allocation, as an alternative to device plugin resources. The
+	// runtime container uses every claim listed.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ResourceClaims []corev1.PodResourceClaim `json:"resourceClaims,omitempty"`
+
+	// RequestTimeoutSeconds bounds how long a request may take, including
+	// long generations. The kaimera proxy and the smoke test give up on
+	// requests after it. vLLM itself has no request timeout.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RequestTimeoutSeconds int32 `json:"requestTimeoutSeconds,omitempty"`
+
+	// Architecture pins the model pods to nodes of this CPU architecture and
+	// selects the runtime image built for it. Defaults to any architecture
+	// with the amd64 image.
+	// +kubebuilder:validation:Enum=amd64;arm64
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// AutoTensorParallel shards the model across all GPUs of a replica by
+	// setting vLLM's --tensor-parallel-size to the GPU count, which must
+	// then be a power of 2. An explicit TensorParallelSize takes precedence.
+	// +optional
+	AutoTensorParallel bool `json:"autoTensorParallel,omitempty"`
+
+	// TensorParallelSize shards each layer of the model across this many
+	// GPUs of a replica. Its product with pipelineParallelSize must equal
+	// the GPU count.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TensorParallelSize int32 `json:"tensorParallelSize,omitempty"`
+
+	// PipelineParallelSize splits the layers of the model into this many
+	// stages, each on its own GPUs of a replica
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PipelineParallelSize int32 `json:"pipelineParallelSize,omitempty"`
+
+	// Entrypoint runs a script from a ConfigMap in place of vLLM for custom
+	// startup logic. The script receives the vLLM command as its arguments
+	// and is expected to exec it, e.g. with exec "$@".
+	// +optional
+	Entrypoint *EntrypointSpec `json:"entrypoint,omitempty"`
+
+	// RevisionHistoryLimit is the number of old ReplicaSets kept to allow a
+	// rollback. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// PruneReplicaSets deletes old ReplicaSets beyond revisionHistoryLimit on
+	// every reconcile instead of waiting for the Deployment controller, so
+	// nodes can evict the old runtime images sooner
+	// +optional
+	PruneReplicaSets bool `json:"pruneReplicaSets,omitempty"`
+
+	// EvictionPriority makes the model pods the last to be evicted under
+	// node pressure or preempted, by giving them a high priority class and
+	// the Guaranteed QoS class
+	// +optional
+	EvictionPriority *EvictionPrioritySpec `json:"evictionPriority,omitempty"`
+
+	// HighAvailability keeps the model serving through node drains and
+	// rollouts: a PodDisruptionBudget lets only one replica be evicted at a
+	// time, the pods get the priority class of evictionPriority, or the
+	// controller's default one, replicas prefer separate nodes and zones, and
+	// rollouts bring up a new replica before removing an old one. A single
+	// replica is still evicted by node drains, so it needs at least two
+	// replicas to keep serving. May not be combined with exclusiveNode.
+	// +optional
+	HighAvailability bool `json:"highAvailability,omitempty"`
+
+	// EgressPolicy restricts the egress of the model pods with a
+	// NetworkPolicy to the cluster DNS, the prefix cache and the allowed
+	// destinations, e.g. the model registry or HuggingFace. It takes a
+	// network plugin enforcing NetworkPolicies.
+	// +optional
+	EgressPolicy *EgressPolicySpec `json:"egressPolicy,omitempty"`
+
+	// AppProtocol is set as the appProtocol of the Service's serving port,
+	// so meshes and load balancers route it correctly, e.g. http2, grpc or
+	// kubernetes.io/h2c
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	AppProtocol string `json:"appProtocol,omitempty"`
+
+	// ClusterIP is a static cluster IP for the Service, for clients that
+	// address the model by IP. It must be free and inside the cluster's
+	// service CIDR, and can only be set when the ModelDeployment is created.
+	// +optional
+	ClusterIP string `json:"clusterIP,omitempty"`
+
+	// InternalTrafficPolicy of the Service. Local routes clients in the
+	// cluster to the replicas on their own node only, saving a hop for
+	// clients colocated with the model, and drops their traffic when no
+	// replica runs there. Defaults to Cluster.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	InternalTrafficPolicy corev1.ServiceInternalTrafficPolicy `json:"internalTrafficPolicy,omitempty"`
+
+	// Probes configures the health checks of the runtime
+	// +optional
+	Probes *ProbesSpec `json:"probes,omitempty"`
+
+	// GracefulDrain lets a replica finish its in-flight generations before
+	// it is stopped, e.g. on a rollout or scale down. A preStop hook calls
+	// the runtime's drain endpoint, if it has one, and waits until no
+	// request is running for at most the drain period, which the pod's
+	// termination grace period is extended by.
+	// +optional
+	GracefulDrain *GracefulDrainSpec `json:"gracefulDrain,omitempty"`
+
+	// Metrics configures observability resources generated for the model
+	// +optional
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
+
+	// DownloadConcurrency speeds up downloading large models from Xet
+	// storage, which backs most of HuggingFace, by having hf_xet fetch up
+	// to this many ranges of a file in parallel. It takes huggingface_hub
+	// 0.32 or newer in the runtime image, which installs hf_xet.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DownloadConcurrency int32 `json:"downloadConcurrency,omitempty"`
+
+	// ModelChecksum pins the model weights to a checksum (sha256:...). An
+	// init container downloads the model before the runtime starts and
+	// verifies it, so a tampered or changed model is never served and the
+	// Degraded condition reports ModelChecksumMismatch instead. The runtime
+	// then runs with HF_HUB_OFFLINE, so it only loads the verified files.
+	// The checksum is the sha256 of the sha256sum output of the
+	// *.safetensors and *.bin files in the model's snapshot directory,
+	// sorted by path:
+	//   find -L . -type f \( -name '*.safetensors' -o -name '*.bin' \) | LC_ALL=C sort | xargs sha256sum | sha256sum
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	ModelChecksum string `json:"modelChecksum,omitempty"`
+
+	// CUDAVisibleDevices pins the runtime to specific GPUs of the node by
+	// index or UUID, e.g. "0,1", through CUDA_VISIBLE_DEVICES. It is meant for
+	// debugging and shared nodes without the NVIDIA device plugin. It
+	// bypasses the device plugin, so nothing stops other pods from using the
+	// same GPUs; use it with care.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+(,[A-Za-z0-9-]+)*$`
+	// +optional
+	CUDAVisibleDevices string `json:"cudaVisibleDevices,omitempty"`
+
+	// CheckGPUQuota checks the namespace's ResourceQuotas before the
+	// deployment is created. When they don't leave enough GPUs for all the
+	// replicas, the deployment isn't created and the Degraded condition is
+	// set with reason QuotaExceeded, instead of leaving pods that can never
+	// be admitted.
+	// +optional
+	CheckGPUQuota bool `json:"checkGPUQuota,omitempty"`
+
+	// RestartPolicy of the model pods. ModelDeployments run as Deployments,
+	// which only support Always; Never and OnFailure are rejected by the
+	// webhook. One-shot runs such as evals are better run as a Job.
+	// +kubebuilder:validation:Enum=Always;OnFailure;Never
+	// +optional
+	RestartPolicy corev1.RestartPolicy `json:"restartPolicy,omitempty"`
+
+	// Logging configures shipping the runtime's logs without a cluster-wide
+	// logging agent
+	// +optional
+	Logging *LoggingSpec `json:"logging,omitempty"`
+
+	// AuditLogging records the requests served by the model, and the
+	// outputs generated for them, to a volume for audit. The runtime logs
+	// them with --enable-log-requests and --enable-log-outputs, which needs
+	// vLLM 0.10.2 or later.
+	// +optional
+	AuditLogging *AuditLoggingSpec `json:"auditLogging,omitempty"`
+
+	// RopeScaling is the RoPE scaling config passed to --rope-scaling as a
+	// JSON object, e.g. {"rope_type":"yarn","factor":4.0,
+	// "original_max_position_embeddings":32768}, to serve a longer context
+	// than the model was trained on. Raise maxModelLength along with it.
+	// +optional
+	RopeScaling string `json:"ropeScaling,omitempty"`
+
+	// RopeTheta overrides the RoPE base frequency through --rope-theta
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RopeTheta int64 `json:"ropeTheta,omitempty"`
+
+	// MinReadyReplicas is how many replicas must be ready before the Ready
+	// condition turns True, so an HA deployment isn't declared ready on its
+	// first replica. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReadyReplicas int32 `json:"minReadyReplicas,omitempty"`
+
+	// Profile names a preset of spec fields, e.g. llama-7b-gpu, defined by
+	// the platform team in the profiles ConfigMap of the controller. The
+	// profile is filled in on admission; fields set on the ModelDeployment
+	// take precedence, though they can't unset a profile field to its zero
+	// value.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+
+	// HostPID runs the model pods in the node's PID namespace, which
+	// profilers such as nsys need in some setups. The pods can then see and
+	// signal every process on the node, so it is only allowed when the
+	// controller runs with --allow-host-pid.
+	// +optional
+	HostPID bool `json:"hostPID,omitempty"`
+
+	// GPUTuning runs a privileged init container setting the power and
+	// clock limits of the replica's GPUs with nvidia-smi before the runtime
+	// starts, for power-capped clusters. It only applies to the gpu runtime.
+	// Privileged containers get full access to the node, so it is only
+	// allowed when the controller runs with --allow-gpu-tuning, and the
+	// namespace must admit privileged pods. The limits outlive the pod
+	// until the GPUs are reset or tuned again.
+	// +optional
+	GPUTuning *GPUTuningSpec `json:"gpuTuning,omitempty"`
+
+	// MPS runs the replicas as clients of the node's NVIDIA Multi-Process
+	// Service, so several replicas can share a GPU concurrently. It only
+	// applies to the gpu runtime. Each node needs the MPS control daemon
+	// (nvidia-cuda-mps-control -d) running on its GPUs with the
+	// CUDA_MPS_PIPE_DIRECTORY the controller runs with (--mps-pipe-directory),
+	// and a device plugin advertising each GPU more than once, e.g. with
+	// time-slicing replicas, for the replicas to be scheduled together. The
+	// pods share the node's IPC namespace and mount the pipe directory from
+	// the host, so it is only allowed when the controller runs with
+	// --allow-mps.
+	// +optional
+	MPS *MPSSpec `json:"mps,omitempty"`
+
+	// Sysctls are set in the pod's security context, e.g. a higher
+	// net.core.somaxconn for many concurrent connections. Sysctls outside
+	// the kubelet's safe set, somaxconn included, are only admitted on
+	// nodes whose kubelet allows them with --allowed-unsafe-sysctls, so the
+	// webhook warns about them.
+	// +optional
+	Sysctls []corev1.Sysctl `json:"sysctls,omitempty"`
+
+	// ExclusiveNode gives each replica a node of its own: replicas repel
+	// each other with pod anti-affinity and request all GPUs of a node
+	// instead of gpuCount. The GPU count is the smallest allocatable count
+	// among the schedulable nodes matching the ModelDeployment, recorded in
+	// status.nodeGPUs. Rollouts replace replicas one at a time without a
+	// surge. Requires the gpu runtime and may not be combined with
+	// replicasPerNode.
+	// +optional
+	ExclusiveNode bool `json:"exclusiveNode,omitempty"`
+
+	// NotificationWebhook is a URL a JSON notification is POSTed to when the
+	// ModelDeployment becomes Ready or fails, i.e. turns Degraded. Delivery
+	// is retried a few times in the background and never holds up the
+	// reconcile.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	NotificationWebhook string `json:"notificationWebhook,omitempty"`
+
+	// GPUMemoryUtilization caps the fraction of GPU memory each replica
+	// uses, e.g. "0.4", through --gpu-memory-utilization, so several small
+	// models can share a GPU, e.g. with time-slicing. No anti-affinity keeps
+	// such models apart; the webhook warns when the ModelDeployments that may
+	// share a GPU add up to more than 1.
+	// +kubebuilder:validation:Pattern=`^(0?\.[0-9]*[1-9][0-9]*|1(\.0*)?)$`
+	// +optional
+	GPUMemoryUtilization string `json:"gpuMemoryUtilization,omitempty"`
+
+	// Autoscaling hands the replica count to a HorizontalPodAutoscaler
+	// managed with the ModelDeployment, so it may not be combined with
+	// replicas, replicasPerNode or rampUp. The controller only sets the
+	// Deployment's replicas when creating it and leaves them to the
+	// autoscaler afterwards.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// WarmPool keeps extra replicas loaded with the model on top of those
+	// serving the demand, so a burst is served without waiting for a
+	// replica to start. The pool is added to replicas or replicasPerNode,
+	// or to both bounds of autoscaling, where it only stands on top of the
+	// demand at the lower bound as the autoscaler sizes the rest.
+	// +optional
+	WarmPool *WarmPoolSpec `json:"warmPool,omitempty"`
+
+	// Rightsizing periodically sizes the replica count, within bounds, for
+	// the model's request rate and latency queried from Prometheus, for
+	// clusters where an autoscaler can't scale on those metrics. It may not
+	// be combined with replicas, replicasPerNode, rampUp or autoscaling.
+	// +optional
+	Rightsizing *RightsizingSpec `json:"rightsizing,omitempty"`
+
+	// AutoRollback rolls the Deployment back to the last version whose
+	// replicas all became ready when the pods of a new version aren't ready
+	// within the deadline. The version rolled back from isn't rolled out
+	// again until the spec changes. It needs the previous ReplicaSet, see
+	// revisionHistoryLimit, and the Deployment workload type.
+	// +optional
+	AutoRollback *AutoRollbackSpec `json:"autoRollback,omitempty"`
+}
+
+// AutoRollbackSpec configures rolling back failed rollouts
+type AutoRollbackSpec struct {
+	// ProgressDeadline is how long the pods of a new version have to become
+	// ready, from when the Deployment changed to it. Defaults to 30m, the
+	// time the startup probe allows for loading the model.
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// RightsizingSpec configures rightsizing the replica count from metrics
+type RightsizingSpec struct {
+	// PrometheusURL is the base URL of the Prometheus HTTP API scraping the
+	// model's metrics, e.g. http://prometheus.monitoring:9090. The metrics
+	// are selected by the namespace and service labels set when scraped
+	// through the ServiceMonitor.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	PrometheusURL string `json:"prometheusURL"`
+
+	// MinReplicas is the lower bound of the replica count, and the count the
+	// Deployment is created with. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound of the replica count
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetRequestRate is the number of requests per second a replica is
+	// sized for, e.g. 2 or 500m
+	TargetRequestRate resource.Quantity `json:"targetRequestRate"`
+
+	// MaxLatency is the 95th percentile end to end request latency above
+	// which a replica is added, whatever the request rate
+	// +optional
+	MaxLatency *metav1.Duration `json:"maxLatency,omitempty"`
+
+	// Interval between two decisions, which is also the window the request
+	// rate and latency are measured over. Defaults to 5m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// WarmPoolSpec configures the warm pool of the model
+type WarmPoolSpec struct {
+	// Replicas is the number of warm replicas
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+}
+
+// RightsizingStatus is a replica count decided by rightsizing and the
+// metrics it was based on
+type RightsizingStatus struct {
+	// Replicas is the replica count decided on, without the warm pool
+	Replicas int32 `json:"replicas"`
+
+	// RequestRate is the number of requests per second served
+	RequestRate resource.Quantity `json:"requestRate"`
+
+	// Latency is the 95th percentile end to end request latency, if any
+	// request completed
+	// +optional
+	Latency *metav1.Duration `json:"latency,omitempty"`
+
+	// Reason is what the replica count was decided on: RequestRate,
+	// Latency, MinReplicas or MaxReplicas
+	Reason string `json:"reason"`
+
+	// DecisionTime is when the replica count was decided
+	DecisionTime metav1.Time `json:"decisionTime"`
+}
+
+// EvictionPrioritySpec configures the priority and reserved resources of the
+// model pods. The CPU and memory are both requested and set as limits, which
+// puts the pods in the Guaranteed QoS class.
+type EvictionPrioritySpec struct {
+	// PriorityClassName is the priority class of the pods. Defaults to the
+	// controller's --default-priority-class, if set.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// CPU reserved for the runtime container
+	CPU resource.Quantity `json:"cpu"`
+
+	// Memory reserved for the runtime container
+	Memory resource.Quantity `json:"memory"`
+}
+
+// EgressPolicySpec configures the destinations the model pods may reach.
+// NetworkPolicies match IP addresses rather than host names, so the model
+// registry or HuggingFace is given by its address ranges, or those of an
+// egress proxy in front of it.
+type EgressPolicySpec struct {
+	// AllowedCIDRs are the address ranges the pods may reach, e.g. of the
+	// model registry, a HuggingFace mirror or an egress proxy
+	// +kubebuilder:validation:MinItems=1
+	AllowedCIDRs []string `json:"allowedCIDRs"`
+
+	// Ports are the TCP ports the pods may reach on the allowed
+	// destinations. Defaults to 443.
+	// +optional
+	Ports []int32 `json:"ports,omitempty"`
+
+	// DNS configures the DNS servers the pods may query. Defaults to the
+	// kube-dns pods in kube-system and NodeLocal DNSCache.
+	// +optional
+	DNS *EgressDNSSpec `json:"dns,omitempty"`
 }
 
+// EgressDNSSpec selects the DNS servers of the cluster, which are labeled
+// differently across distributions
+type EgressDNSSpec struct {
+	// NamespaceSelector selects the namespaces of the DNS pods. Defaults to
+	// kube-system.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// PodSelector selects the DNS pods. Defaults to the k8s-app: kube-dns
+	// label of CoreDNS and kube-dns.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// CIDRs are DNS servers outside the pod network, e.g. a node-local
+	// cache or resolvers of the VPC. Defaults to 169.254.20.10/32, the
+	// address of NodeLocal DNSCache.
+	// +optional
+	CIDRs []string `json:"cidrs,omitempty"`
+}
+
+// AutoscalingSpec configures the HorizontalPodAutoscaler of the model
+type AutoscalingSpec struct {
+	// MinReplicas is the lower bound of the replica count, and the count the
+	// Deployment is created with. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound of the replica count
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Metrics the replica count is scaled on, e.g. a pods metric exported by
+	// vLLM through a metrics adapter. Defaults to 80% average CPU
+	// utilization.
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+}
+
+// LoggingSpec configures shipping the runtime's logs
+type LoggingSpec struct {
+	// Sidecar runs a fluent-bit sidecar shipping the runtime's output. The
+	// runtime's stdout and stderr are copied to a log file on a volume shared
+	// with the sidecar, and still show up in kubectl logs. The file is
+	// truncated once it reaches 100Mi, losing what the sidecar couldn't ship
+	// by then, e.g. while the endpoint is down.
+	// +optional
+	Sidecar *LogSidecarSpec `json:"sidecar,omitempty"`
+}
+
+// LogSidecarSpec configures the log shipper sidecar
+type LogSidecarSpec struct {
+	// Endpoint is the HTTP endpoint the logs are posted to as JSON, e.g.
+	// https://logs.example.com/ingest
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+
+	// Image of the sidecar. Defaults to fluent-bit 3.1.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// AuditLoggingSpec configures the audit log of the requests and responses
+// served by the model
+type AuditLoggingSpec struct {
+	// ClaimName is the PersistentVolumeClaim the audit logs are written to.
+	// Each replica writes a file named after its pod, so a claim shared by
+	// several replicas must be ReadWriteMany.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// RetentionDays is how long rotated logs are kept on the volume.
+	// Defaults to 30.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RetentionDays int32 `json:"retentionDays,omitempty"`
+
+	// MaxFileSizeMB is the size a log file is rotated and compressed at.
+	// Defaults to 100.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxFileSizeMB int32 `json:"maxFileSizeMB,omitempty"`
+
+	// Image of the sidecar rotating the logs, which must provide a shell,
+	// gzip and find. Defaults to busybox.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// GPUTuningSpec configures the limits set on the GPUs of each replica. At
+// least one limit must be set.
+type GPUTuningSpec struct {
+	// PowerLimitWatts caps the power draw of each GPU, within the range
+	// the board supports
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PowerLimitWatts int32 `json:"powerLimitWatts,omitempty"`
+
+	// LockedGraphicsClocks locks the graphics clock of each GPU to a range
+	// +optional
+	LockedGraphicsClocks *ClockRange `json:"lockedGraphicsClocks,omitempty"`
+
+	// Image of the init container, which must provide nvidia-smi. Defaults
+	// to the CUDA base image.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// ClockRange is a range of clock frequencies
+type ClockRange struct {
+	// MinMHz is the lowest frequency
+	// +kubebuilder:validation:Minimum=1
+	MinMHz int32 `json:"minMHz"`
+
+	// MaxMHz is the highest frequency
+	// +kubebuilder:validation:Minimum=1
+	MaxMHz int32 `json:"maxMHz"`
+}
+
+// MPSSpec configures how the replicas connect to the MPS control daemon
+type MPSSpec struct {
+	// ActiveThreadPercentage caps the share of each GPU's threads a replica
+	// can use, so replicas sharing a GPU don't starve each other
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ActiveThreadPercentage int32 `json:"activeThreadPercentage,omitempty"`
+}
+
+// EntrypointSpec references the startup script of the runtime container
+type EntrypointSpec struct {
+	// ConfigMapName is the ConfigMap in the ModelDeployment's namespace
+	// holding the script
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+
+	// Key is the ConfigMap key of the script. Defaults to start.sh.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// DiscoverySpec configures the discovery annotations of the model's Service.
+// The annotation keys are set on the controller.
+type DiscoverySpec struct {
+	// Tag groups the model for discovery, e.g. the name of the registry or
+	// environment picking it up
+	// +kubebuilder:validation:MinLength=1
+	Tag string `json:"tag"`
+}
+
+// ShadowSpec configures the shadow deployment. Everything not set here is
+// taken from the primary deployment.
+type ShadowSpec struct {
+	// ModelName is the model served by the shadow deployment
+	// +kubebuilder:validation:MinLength=1
+	ModelName string `json:"modelName"`
+
+	// ImageDigest pins the shadow's runtime image to a digest, e.g. to try a
+	// new runtime version with the same model
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	ImageDigest string `json:"imageDigest,omitempty"`
+}
+
+// WorkloadType is the kind of workload running the model replicas
+// +kubebuilder:validation:Enum=Deployment;Rollout;StatefulSet
+type WorkloadType string
+
+const (
+	// WorkloadTypeDeployment runs the replicas in a Deployment
+	WorkloadTypeDeployment WorkloadType = "Deployment"
+
+	// WorkloadTypeRollout runs the replicas in an Argo Rollout
+	WorkloadTypeRollout WorkloadType = "Rollout"
+
+	// WorkloadTypeStatefulSet runs the replicas in a StatefulSet
+	WorkloadTypeStatefulSet WorkloadType = "StatefulSet"
+)
+
+// KarpenterSpec configures the node requirements Karpenter provisions
+// nodes for
+type KarpenterSpec struct {
+	// InstanceTypes the replicas may run on, e.g. g5.xlarge or p4d.24xlarge
+	// +optional
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+
+	// InstanceFamilies the replicas may run on, e.g. g5 or p4d. Karpenter
+	// sets this label on AWS only.
+	// +optional
+	InstanceFamilies []string `json:"instanceFamilies,omitempty"`
+
+	// CapacityTypes the replicas may run on. Karpenter picks the cheapest
+	// type available when more than one is allowed.
+	// +optional
+	CapacityTypes []KarpenterCapacityType `json:"capacityTypes,omitempty"`
+
+	// DoNotDisrupt keeps Karpenter from consolidating or expiring the nodes
+	// of running replicas, which would reload the model elsewhere
+	// +optional
+	DoNotDisrupt bool `json:"doNotDisrupt,omitempty"`
+}
+
+// KarpenterCapacityType is the purchase option of an instance
+// +kubebuilder:validation:Enum=on-demand;spot;reserved
+type KarpenterCapacityType string
+
+const (
+	// KarpenterCapacityTypeOnDemand runs on on-demand instances
+	KarpenterCapacityTypeOnDemand KarpenterCapacityType = "on-demand"
+
+	// KarpenterCapacityTypeSpot runs on spot instances, which the cloud
+	// provider may reclaim
+	KarpenterCapacityTypeSpot KarpenterCapacityType = "spot"
+
+	// KarpenterCapacityTypeReserved runs on capacity reservations
+	KarpenterCapacityTypeReserved KarpenterCapacityType = "reserved"
+)
+
+// WedgedGPUSpec configures the detection of wedged GPUs
+type WedgedGPUSpec struct {
+	// LivenessFailureThreshold is the number of liveness probe failures of
+	// a pod, as recorded in its events, after which the GPUs of its node are
+	// considered wedged. The API server keeps events for an hour by
+	// default. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	LivenessFailureThreshold int32 `json:"livenessFailureThreshold,omitempty"`
+
+	// Action taken on the node once the threshold is reached. At most one
+	// node is cordoned or tainted at a time, and none when the liveness
+	// probes of all replicas fail, which points to the model rather than
+	// the GPUs. Defaults to Event.
+	// +optional
+	Action WedgedGPUAction `json:"action,omitempty"`
+}
+
+// WedgedGPUAction is what is done about a node with wedged GPUs
+// +kubebuilder:validation:Enum=Event;Cordon;Taint
+type WedgedGPUAction string
+
+const (
+	// WedgedGPUActionEvent records a Warning event on the ModelDeployment
+	WedgedGPUActionEvent WedgedGPUAction = "Event"
+
+	// WedgedGPUActionCordon also marks the node unschedulable. It needs the
+	// controller to run with --allow-node-remediation.
+	WedgedGPUActionCordon WedgedGPUAction = "Cordon"
+
+	// WedgedGPUActionTaint also taints the node with
+	// kaimera.ai/wedged-gpu:NoSchedule, leaving it to tooling watching the
+	// taint. It needs the controller to run with --allow-node-remediation.
+	WedgedGPUActionTaint WedgedGPUAction = "Taint"
+)
+
+// RolloutStrategy is how an Argo Rollout brings up a new version
+// +kubebuilder:validation:Enum=Canary;BlueGreen
+type RolloutStrategy string
+
+const (
+	// RolloutStrategyCanary moves replicas to the new version in steps
+	RolloutStrategyCanary RolloutStrategy = "Canary"
+
+	// RolloutStrategyBlueGreen brings the new version up next to the old
+	// one and switches the Service over once it is promoted
+	RolloutStrategyBlueGreen RolloutStrategy = "BlueGreen"
+)
+
+// RolloutSpec configures the Argo Rollout running the model replicas
+type RolloutSpec struct {
+	// Strategy of the rollout. BlueGreen serves the new version from the
+	// <name>-preview Service until it is promoted. Defaults to Canary.
+	// +optional
+	Strategy RolloutStrategy `json:"strategy,omitempty"`
+
+	// CanaryWeights are the percentages of replicas on the new version the
+	// canary pauses at, e.g. [20, 50]. Defaults to [25, 50, 75].
+	// +kubebuilder:validation:items:Minimum=1
+	// +kubebuilder:validation:items:Maximum=100
+	// +optional
+	CanaryWeights []int32 `json:"canaryWeights,omitempty"`
+
+	// PauseDuration of each canary step. Steps wait to be promoted, e.g.
+	// with kubectl argo rollouts promote, when unset.
+	// +optional
+	PauseDuration *metav1.Duration `json:"pauseDuration,omitempty"`
+
+	// AutoPromote switches a blue-green Service over to the new version as
+	// soon as it is ready, instead of waiting to be promoted
+	// +optional
+	AutoPromote bool `json:"autoPromote,omitempty"`
+}
+
+// CPUFallbackSpec configures the cpu runtime deployment serving the model
+// while the gpu runtime can't be scheduled
+type CPUFallbackSpec struct {
+	// After is how long a pod must have been unschedulable, with too few
+	// GPU replicas ready, before the fallback is deployed
+	// +kubebuilder:default="10m"
+	// +optional
+	After metav1.Duration `json:"after,omitempty"`
+
+	// ModelName is a smaller model to serve on the CPU. It is served under
+	// the primary's model name, so clients don't notice the switch.
+	// Defaults to the primary's model.
+	// +optional
+	ModelName string `json:"modelName,omitempty"`
+
+	// Replicas of the fallback. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// NodeSelector places the fallback on the nodes with these labels.
+	// The primary's node selection, e.g. its nodeSelectorLabels, node pool
+	// and Karpenter requirements, targets GPU nodes and doesn't apply to the
+	// fallback.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// MetricsSpec configures observability resources generated for the model
+type MetricsSpec struct {
+	// Dashboard generates a ConfigMap holding a Grafana dashboard of the
+	// model's vLLM metrics, labeled grafana_dashboard=1 for the Grafana
+	// sidecar to provision
+	// +optional
+	Dashboard bool `json:"dashboard,omitempty"`
+
+	// ServiceMonitor generates a Prometheus Operator ServiceMonitor scraping
+	// the runtime's /metrics endpoint. The monitoring.coreos.com CRDs must be
+	// installed in the cluster.
+	// +optional
+	ServiceMonitor *ServiceMonitorSpec `json:"serviceMonitor,omitempty"`
+}
+
+// PrometheusDuration is a duration in the Prometheus format, e.g. 30s or 1m30s
+// +kubebuilder:validation:Pattern="^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+type PrometheusDuration string
+
+// ServiceMonitorSpec configures how Prometheus scrapes the model's metrics
+type ServiceMonitorSpec struct {
+	// Interval between scrapes. Defaults to Prometheus' global scrape
+	// interval.
+	// +optional
+	Interval PrometheusDuration `json:"interval,omitempty"`
+
+	// ScrapeTimeout after which a scrape fails. Defaults to Prometheus'
+	// global scrape timeout, and must not exceed the interval.
+	// +optional
+	ScrapeTimeout PrometheusDuration `json:"scrapeTimeout,omitempty"`
+
+	// MetricRelabelings are applied to the scraped samples before they are
+	// ingested, e.g. to drop histogram buckets that are not needed and keep
+	// the series count down
+	// +optional
+	MetricRelabelings []RelabelConfig `json:"metricRelabelings,omitempty"`
+}
+
+// RelabelConfig is a Prometheus relabeling rule
+type RelabelConfig struct {
+	// SourceLabels whose values are concatenated with the separator and
+	// matched against the regex
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+
+	// Separator between the concatenated source label values. Defaults to ;.
+	// +optional
+	Separator string `json:"separator,omitempty"`
+
+	// Regex matched against the concatenated source label values. Defaults
+	// to (.*).
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// TargetLabel the result is written to, for the replace and hashmod
+	// actions
+	// +optional
+	TargetLabel string `json:"targetLabel,omitempty"`
+
+	// Replacement written to the target label, which may refer to the
+	// regex's capture groups. Defaults to $1.
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
+
+	// Modulus of the hash of the source label values, for the hashmod
+	// action
+	// +optional
+	Modulus uint64 `json:"modulus,omitempty"`
+
+	// Action to perform. Defaults to replace.
+	// +kubebuilder:validation:Enum=replace;keep;drop;keepequal;dropequal;hashmod;labelmap;labeldrop;labelkeep;lowercase;uppercase
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// ProbesSpec configures the health checks of the runtime
+type ProbesSpec struct {
+	// ExecCommand is run in the runtime container for the startup,
+	// readiness and liveness probes, for runtimes without an HTTP health
+	// endpoint, e.g. ["sh", "-c", "test -S /tmp/model.sock"]. The probe
+	// passes when it exits with 0. At least one of execCommand and
+	// generation must be set.
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	ExecCommand []string `json:"execCommand,omitempty"`
+
+	// Generation makes a replica ready only once it completes a test
+	// generation, so it takes no traffic before the model can actually
+	// generate. The readiness probe sends a tiny completion request to
+	// /v1/completions in place of the exec command.
+	// +optional
+	Generation *GenerationProbeSpec `json:"generation,omitempty"`
+}
+
+// GenerationProbeSpec configures the test generation of the readiness probe
+type GenerationProbeSpec struct {
+	// Prompt completed by the test generation. Defaults to "Hello".
+	// +optional
+	Prompt string `json:"prompt,omitempty"`
+
+	// MaxTokens generated for the prompt. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxTokens int32 `json:"maxTokens,omitempty"`
+
+	// ExpectedStatus is the HTTP status of a successful generation.
+	// Defaults to 200.
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=599
+	// +optional
+	ExpectedStatus int32 `json:"expectedStatus,omitempty"`
+
+	// ExpectedText must be part of the generated text, when set
+	// +optional
+	ExpectedText string `json:"expectedText,omitempty"`
+
+	// TimeoutSeconds is how long the generation may take. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// GracefulDrainSpec configures how a replica is drained before it stops
+type GracefulDrainSpec struct {
+	// Path of the runtime's drain endpoint, which is POSTed to stop it from
+	// taking new requests. Runtimes without one keep serving until the
+	// wait is over. Defaults to /drain.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Period is the longest the preStop hook waits for in-flight requests
+	// to finish. Defaults to 2m.
+	// +optional
+	Period *metav1.Duration `json:"period,omitempty"`
+}
+
+// PrefixCacheSpec configures the companion cache shared by the replicas
+type PrefixCacheSpec struct {
+	// Image of the Redis compatible cache. Defaults to redis:7.4-alpine.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// MaxMemory caps the memory of the cache. The least recently used
+	// entries are evicted once it is full. Defaults to 1Gi.
+	// +optional
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+}
+
+// TracingSpec configures OpenTelemetry tracing of the runtime
+type TracingSpec struct {
+	// Endpoint is the OTLP endpoint spans are exported to, e.g.
+	// http://otel-collector.observability:4317
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+
+	// ServiceName is reported as the service.name of the spans. Defaults to
+	// the name of the ModelDeployment.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+// ModelCacheSpec configures the volume model weights are cached on
+type ModelCacheSpec struct {
+	// ClaimName is the PersistentVolumeClaim holding the cache
+	ClaimName string `json:"claimName"`
+
+	// SubPath is the directory within the volume used by this model, so one
+	// claim can be shared by several models. Defaults to a directory derived
+	// from the model name.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+
+	// FSGroup owns the volume's files, so the runtime can write the cache
+	// when it runs as a non-root user
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// FSGroupChangePolicy controls when the volume's ownership is changed to
+	// the fsGroup. Defaults to OnRootMismatch, which skips the recursive
+	// chown of a large cache once its root is owned correctly.
+	// +kubebuilder:validation:Enum=OnRootMismatch;Always
+	// +optional
+	FSGroupChangePolicy corev1.PodFSGroupChangePolicy `json:"fsGroupChangePolicy,omitempty"`
+}
+
+// LoRAAdapter is a LoRA adapter stored on the model cache claim
+type LoRAAdapter struct {
+	// Name the adapter is served as, used as the model of requests
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][-A-Za-z0-9_./]*$`
+	Name string `json:"name"`
+
+	// Path of the adapter's directory relative to the root of the model
+	// cache claim, e.g. adapters/sql-lora
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+}
+
+// DefaultGPUResourceName is the extended resource GPUs are requested as
+// unless overridden
+const DefaultGPUResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+// The runtime images, before the architecture's tag suffix and the digest
+// are applied
+const (
+	CPURuntimeImage = "patnaikshekhar/vllm-cpu:1"
+	GPURuntimeImage = "vllm/vllm-openai:latest"
+)
+
+// RuntimeImage returns the image of the runtime the spec selects, or an
+// empty string for an unknown runtime
+func (s *ModelDeploymentSpec) RuntimeImage() string {
+	switch s.Runtime {
+	case "", "cpu":
+		return CPURuntimeImage
+	case "gpu":
+		return GPURuntimeImage
+	}
+
+	return ""
+}
+
+// ServicePort returns the port the model's Service serves the API on
+func (s *ModelDeploymentSpec) ServicePort() int32 {
+	if s.Port > 0 {
+		return s.Port
+	}
+
+	return 80
+}
+
+// RequestTimeout returns the request timeout, or 0 if requests may take any
+// amount of time
+func (s *ModelDeploymentSpec) RequestTimeout() time.Duration {
+	return time.Duration(s.RequestTimeoutSeconds) * time.Second
+}
+
+// GPUResource returns the extended resource GPUs are requested as
+func (s *ModelDeploymentSpec) GPUResource() corev1.ResourceName {
+	if s.GPUResourceName != "" {
+		return corev1.ResourceName(s.GPUResourceName)
+	}
+
+	return DefaultGPUResourceName
+}
+
+// GPUToleration returns the taint key tolerated on GPU nodes
+func (s *ModelDeploymentSpec) GPUToleration() string {
+	if s.GPUTolerationKey != "" {
+		return s.GPUTolerationKey
+	}
+
+	return string(s.GPUResource())
+}
+
+// RequestedGPUs returns the number of GPUs each replica requests, which is
+// zero for runtimes that don't use GPUs.
+func (s *ModelDeploymentSpec) RequestedGPUs() int32 {
+	if s.Runtime != "gpu" {
+		return 0
+	}
+	if s.GPUCount > 0 {
+		return s.GPUCount
+	}
+
+	return 1
+}
+
+// CPUOffloadMemory returns the host memory the model weights are offloaded
+// to, cpuOffloadGB for each GPU of a replica
+func (s *ModelDeploymentSpec) CPUOffloadMemory() resource.Quantity {
+	gpus := s.RequestedGPUs()
+	if gpus == 0 {
+		gpus = 1
+	}
+
+	return *resource.NewQuantity(int64(s.CPUOffloadGB)*int64(gpus)<<30, resource.BinarySI)
+}
+
+// KueueSpec configures admission of the model pods through Kueue
+type KueueSpec struct {
+	// QueueName is the LocalQueue the pods are submitted to
+	QueueName string `json:"queueName"`
+
+	// Suspend creates the pods with the Kueue admission scheduling gate so
+	// they are held until Kueue admits the workload.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// Condition types reported on a ModelDeployment
+const (
+	// ConditionSmokeTest reports the result of the post readiness smoke test
+	ConditionSmokeTest = "SmokeTest"
+
+	// ConditionTypesRegistered reports whether the controller's scheme knows
+	// every kind the requested features need
+	ConditionTypesRegistered = "TypesRegistered"
+
+	// ConditionDegraded reports that the Deployment or Service serving the
+	// model could not be created or updated
+	ConditionDegraded = "Degraded"
+
+	// ConditionRolloutDeferred reports that a spec change waits for the
+	// maintenance window before it is rolled out
+	ConditionRolloutDeferred = "RolloutDeferred"
+
+	// ConditionReady reports whether enough replicas are ready to serve the
+	// model, see minReadyReplicas
+	ConditionReady = "Ready"
+
+	// ConditionCPUFallback reports that the model is served in degraded mode
+	// by the cpu runtime fallback, as the gpu runtime can't be scheduled
+	ConditionCPUFallback = "CPUFallback"
+
+	// ConditionConfigDrift reports whether the running replicas serve
+	// another model than the spec, with configDriftCheck
+	ConditionConfigDrift = "ConfigDrift"
+
+	// ConditionRolledBack reports that the pods of a new version weren't
+	// ready within the deadline, so the Deployment was rolled back to the
+	// last version whose replicas all became ready, with autoRollback
+	ConditionRolledBack = "RolledBack"
+)
+
+// ModelDeploymentPhase summarises where a ModelDeployment is in coming up
+type ModelDeploymentPhase string
+
+const (
+	// PhaseBinding means the model cache claim is not bound yet, so the
+	// replicas are expected to be pending
+	PhaseBinding ModelDeploymentPhase = "Binding"
+
+	// PhaseProgressing means the replicas are starting
+	PhaseProgressing ModelDeploymentPhase = "Progressing"
+
+	// PhaseReady means enough replicas are ready to serve the model
+	PhaseReady ModelDeploymentPhase = "Ready"
+)
+
+// MaintenanceWindowAnnotation restricts rollouts of spec changes to a daily
+// UTC time range such as 22:00-04:00. Changes made outside of it are held
+// back until it opens. New ModelDeployments are deployed straight away.
+const MaintenanceWindowAnnotation = "kaimera.ai/maintenance-window"
+
+// ReconcilePriorityAnnotation is an integer priority of the ModelDeployment
+// in the controller's queue. Higher priorities are reconciled first, e.g.
+// bringing critical models up before the rest when the controller starts.
+// Defaults to 0.
+const ReconcilePriorityAnnotation = "kaimera.ai/reconcile-priority"
+
 // ModelDeploymentStatus defines the observed state of ModelDeployment
 type ModelDeploymentStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase summarises the Ready condition: Binding while the model cache
+	// claim waits to be bound, then Progressing until the replicas are
+	// Ready
+	// +optional
+	Phase ModelDeploymentPhase `json:"phase,omitempty"`
+
+	// LoRAAdapters are the names of the adapters loaded on every ready
+	// replica, with loraHotReload
+	// +optional
+	LoRAAdapters []string `json:"loraAdapters,omitempty"`
+
+	// ServedModel is the model a ready replica reported serving, with
+	// configDriftCheck
+	// +optional
+	ServedModel string `json:"servedModel,omitempty"`
+
+	// RampReplicas is the replica count the deployment has been ramped up
+	// to so far
+	// +optional
+	RampReplicas int32 `json:"rampReplicas,omitempty"`
+
+	// NodeReplicas is the replica count derived from the matching nodes when
+	// replicasPerNode is set
+	// +optional
+	NodeReplicas int32 `json:"nodeReplicas,omitempty"`
+
+	// NodeGPUs is the GPU count of a whole node requested by each replica
+	// when exclusiveNode is set
+	// +optional
+	NodeGPUs int32 `json:"nodeGPUs,omitempty"`
+
+	// WarmReplicas is the number of ready replicas beyond those serving the
+	// demand, up to the size of the warm pool
+	// +optional
+	WarmReplicas int32 `json:"warmReplicas,omitempty"`
+
+	// Rightsizing is the last decision of rightsizing
+	// +optional
+	Rightsizing *RightsizingStatus `json:"rightsizing,omitempty"`
+
+	// RightsizingQueryTime is when Prometheus was last queried for
+	// rightsizing, successfully or not. It isn't queried again before the
+	// interval passes.
+	// +optional
+	RightsizingQueryTime *metav1.Time `json:"rightsizingQueryTime,omitempty"`
+
+	// ZoneReplicas is the number of scheduled replicas per zone when
+	// strictZoneBalance is set
+	// +optional
+	ZoneReplicas map[string]int32 `json:"zoneReplicas,omitempty"`
+
+	// WedgedGPUNode is the node last taken out of scheduling for wedged
+	// GPUs. No other node is cordoned or tainted for the ModelDeployment
+	// until it is schedulable again.
+	// +optional
+	WedgedGPUNode string `json:"wedgedGPUNode,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the children were
+	// last reconciled to
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// RolloutGeneration is the generation of the spec the current rollout
+	// was started for
+	// +optional
+	RolloutGeneration int64 `json:"rolloutGeneration,omitempty"`
+
+	// LastKnownGoodSpecHash is the hash of the pod template generated from
+	// the spec which last rolled out with all replicas ready, with
+	// autoRollback
+	// +optional
+	LastKnownGoodSpecHash string `json:"lastKnownGoodSpecHash,omitempty"`
+
+	// RolledBackSpecHash is the hash of the pod template the Deployment was
+	// rolled back from, which isn't rolled out again until the spec changes
+	// +optional
+	RolledBackSpecHash string `json:"rolledBackSpecHash,omitempty"`
+
+	// SpecHash is the hash of the pod template the Deployment runs, with
+	// autoRollback
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+
+	// SpecHashTime is when the Deployment changed to the pod template of
+	// specHash, which the progress deadline of autoRollback is measured from
+	// +optional
+	SpecHashTime *metav1.Time `json:"specHashTime,omitempty"`
+
+	// DeploymentStartTime is when the current rollout started
+	// +optional
+	DeploymentStartTime *metav1.Time `json:"deploymentStartTime,omitempty"`
+
+	// ReadyTime is when all replicas of the current rollout became ready
+	// +optional
+	ReadyTime *metav1.Time `json:"readyTime,omitempty"`
+
+	// TimeToReady is how long the current rollout took to become ready
+	// +optional
+	TimeToReady *metav1.Duration `json:"timeToReady,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -34,6 +1541,95 @@ type ModelDeployment struct {
 	Status ModelDeploymentStatus `json:"status,omitempty"`
 }
 
+// ShadowName returns the name of the shadow Deployment and Service
+func (md *ModelDeployment) ShadowName() string {
+	return md.Name + "-shadow"
+}
+
+// RolloutPreviewName returns the name of the Service of the new version
+// during a blue-green rollout
+func (md *ModelDeployment) RolloutPreviewName() string {
+	return md.Name + "-preview"
+}
+
+// ReplicaServiceName returns the name of the Service of the replica with the
+// given ordinal
+func (md *ModelDeployment) ReplicaServiceName(ordinal int32) string {
+	return fmt.Sprintf("%s-replica-%d", md.Name, ordinal)
+}
+
+// CPUFallbackName returns the name of the cpu runtime fallback Deployment
+func (md *ModelDeployment) CPUFallbackName() string {
+	return md.Name + "-cpu-fallback"
+}
+
+// EgressPolicyName returns the name of the egress NetworkPolicy
+func (md *ModelDeployment) EgressPolicyName() string {
+	return md.Name + "-egress"
+}
+
+// PrefixCacheName returns the name of the prefix cache Deployment and Service
+func (md *ModelDeployment) PrefixCacheName() string {
+	return md.Name + "-prefix-cache"
+}
+
+// maxResourceNameLength is the longest name of a generated resource, the
+// DNS label limit of Service names
+const maxResourceNameLength = 63
+
+// PrefixedName returns the name of a resource generated for a ModelDeployment,
+// e.g. its Service or ShadowName, behind the prefix the controller is
+// configured with. Names longer than a DNS label are shortened, ending in a
+// hash of the full name so they stay unique.
+func PrefixedName(prefix, name string) string {
+	full := prefix + name
+	if len(full) <= maxResourceNameLength {
+		return full
+	}
+
+	hash := sha256.Sum256([]byte(full))
+	suffix := fmt.Sprintf("-%x", hash[:4])
+	return strings.TrimRight(full[:maxResourceNameLength-len(suffix)], "-.") + suffix
+}
+
+// ImageRepository returns an image reference without its tag or digest
+func ImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// A colon after the last slash separates the tag; one before it belongs
+	// to a registry port.
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+
+	return image
+}
+
+// ImageRegistryAllowed reports whether image is pulled from one of the
+// registries, each a registry host such as nvcr.io, optionally followed by
+// a path such as docker.io/vllm. Images naming no registry host are pulled
+// from docker.io. Any image is allowed when there are no registries.
+func ImageRegistryAllowed(image string, registries []string) bool {
+	if len(registries) == 0 {
+		return true
+	}
+
+	repository := ImageRepository(image)
+	host, _, found := strings.Cut(repository, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		repository = "docker.io/" + repository
+	}
+	for _, registry := range registries {
+		registry = strings.TrimSuffix(registry, "/")
+		if repository == registry || strings.HasPrefix(repository, registry+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
 // +kubebuilder:object:root=true
 
 // ModelDeploymentList contains a list of ModelDeployment
@@ -0,0 +1,794 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+)
+
+// log is for logging in this package.
+var modeldeploymentlog = logf.Log.WithName("modeldeployment-resource")
+
+// WebhookOptions configures the optional checks of the ModelDeployment
+// validating webhook
+// +kubebuilder:object:generate=false
+type WebhookOptions struct {
+	// ValidateGPUCapacity rejects gpu runtime deployments requesting more
+	// GPUs than any schedulable node offers. It lists nodes on admission, so
+	// it is opt-in.
+	ValidateGPUCapacity bool
+
+	// AllowHostPID admits ModelDeployments running in the node's PID
+	// namespace
+	AllowHostPID bool
+
+	// AllowGPUTuning admits ModelDeployments running a privileged init
+	// container to set GPU power and clock limits
+	AllowGPUTuning bool
+
+	// AllowMPS admits ModelDeployments connecting to the node's MPS control
+	// daemon through its IPC namespace and a host path
+	AllowMPS bool
+
+	// AllowNodeRemediation admits ModelDeployments cordoning or tainting
+	// the nodes of their wedged GPUs
+	AllowNodeRemediation bool
+
+	// ProfilesConfigMap holds the profiles ModelDeployments can reference,
+	// one key per profile with a YAML spec fragment as its value. Profiles
+	// are disabled when unset.
+	ProfilesConfigMap types.NamespacedName
+
+	// ServiceCIDR is the cluster's service CIDR static cluster IPs are
+	// checked against. The check is skipped when unset.
+	ServiceCIDR *net.IPNet
+
+	// AllowedImageRegistries are the registries, optionally with a path,
+	// the runtime image and the images set in the spec must be pulled from.
+	// Any registry is allowed when empty.
+	AllowedImageRegistries []string
+
+	// NodePoolLabel is the node label ModelDeployments setting nodePool
+	// select, which their nodeSelectorLabels may not select as well. The
+	// check is skipped when unset.
+	NodePoolLabel string
+}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks
+// with the manager. Both fail closed, so a profile is never skipped and an
+// invalid spec never admitted, with a short timeout so that clients get a
+// retriable error quickly while the webhook server is unavailable.
+func (r *ModelDeployment) SetupWebhookWithManager(mgr ctrl.Manager, opts WebhookOptions) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&ModelDeploymentDefaulter{
+			Client:         mgr.GetAPIReader(),
+			WebhookOptions: opts,
+		}).
+		WithValidator(&ModelDeploymentValidator{
+			Client:         mgr.GetClient(),
+			WebhookOptions: opts,
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-kaimera-ai-v1-modeldeployment,mutating=true,failurePolicy=fail,sideEffects=None,timeoutSeconds=5,groups=kaimera.ai,resources=modeldeployments,verbs=create;update,versions=v1,name=mmodeldeployment.kb.io,admissionReviewVersions=v1
+
+// ModelDeploymentDefaulter fills in the profile of ModelDeployments on
+// admission
+// +kubebuilder:object:generate=false
+type ModelDeploymentDefaulter struct {
+	// Client reads the profiles ConfigMap. It is read directly rather than
+	// from the cache, so the manager doesn't watch every ConfigMap.
+	Client client.Reader
+	WebhookOptions
+}
+
+var _ admission.CustomDefaulter = &ModelDeploymentDefaulter{}
+
+// Default implements admission.CustomDefaulter
+func (d *ModelDeploymentDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	md, ok := obj.(*ModelDeployment)
+	if !ok {
+		return fmt.Errorf("expected a ModelDeployment but got a %T", obj)
+	}
+	if md.Spec.Profile == "" {
+		return nil
+	}
+	modeldeploymentlog.Info("default", "name", md.Name, "profile", md.Spec.Profile)
+
+	profile, err := d.profile(ctx, md.Spec.Profile)
+	if err != nil {
+		return err
+	}
+
+	spec, err := mergeProfile(profile, md.Spec)
+	if err != nil {
+		return err
+	}
+	md.Spec = spec
+	return nil
+}
+
+// profile reads the named profile from the profiles ConfigMap
+func (d *ModelDeploymentDefaulter) profile(ctx context.Context, name string) (*ModelDeploymentSpec, error) {
+	path := field.NewPath("spec", "profile")
+	if d.ProfilesConfigMap.Name == "" {
+		return nil, field.Invalid(path, name, "profiles are not enabled on the controller")
+	}
+
+	cm := corev1.ConfigMap{}
+	err := d.Client.Get(ctx, d.ProfilesConfigMap, &cm)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := cm.Data[name]
+	if !ok {
+		return nil, field.NotFound(path, name)
+	}
+
+	profile := &ModelDeploymentSpec{}
+	err = yaml.UnmarshalStrict([]byte(value), profile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+
+	return profile, nil
+}
+
+// mergeProfile returns the profile overridden by the fields set in spec.
+// Nested structs and maps are merged, lists are replaced.
+func mergeProfile(profile *ModelDeploymentSpec, spec ModelDeploymentSpec) (ModelDeploymentSpec, error) {
+	overrides, err := json.Marshal(spec)
+	if err != nil {
+		return spec, err
+	}
+
+	merged := *profile.DeepCopy()
+	err = json.Unmarshal(overrides, &merged)
+	if err != nil {
+		return spec, err
+	}
+	merged.Profile = spec.Profile
+
+	return merged, nil
+}
+
+// +kubebuilder:webhook:path=/validate-kaimera-ai-v1-modeldeployment,mutating=false,failurePolicy=fail,sideEffects=None,timeoutSeconds=5,groups=kaimera.ai,resources=modeldeployments,verbs=create;update,versions=v1,name=vmodeldeployment.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// ModelDeploymentValidator validates ModelDeployments on admission
+// +kubebuilder:object:generate=false
+type ModelDeploymentValidator struct {
+	// Client is used to list nodes. The manager's client serves these from
+	// its cache.
+	Client client.Reader
+	WebhookOptions
+}
+
+var _ admission.CustomValidator = &ModelDeploymentValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *ModelDeploymentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	md, ok := obj.(*ModelDeployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelDeployment but got a %T", obj)
+	}
+	modeldeploymentlog.Info("validate create", "name", md.Name)
+
+	md, err := v.resolveTemplate(ctx, md)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(v.colocationWarnings(ctx, md), sysctlWarnings(md)...), v.Validate(ctx, md)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *ModelDeploymentValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	md, ok := newObj.(*ModelDeployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelDeployment but got a %T", newObj)
+	}
+	modeldeploymentlog.Info("validate update", "name", md.Name)
+
+	old, ok := oldObj.(*ModelDeployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelDeployment but got a %T", oldObj)
+	}
+	// The Service got its cluster IP when it was created and can't change it
+	if md.Spec.ClusterIP != old.Spec.ClusterIP {
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "ModelDeployment"},
+			md.Name, field.ErrorList{field.Forbidden(field.NewPath("spec", "clusterIP"), "may not be changed after creation")})
+	}
+
+	md, err := v.resolveTemplate(ctx, md)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(v.colocationWarnings(ctx, md), sysctlWarnings(md)...), v.Validate(ctx, md)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *ModelDeploymentValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// resolveTemplate returns md with its spec merged over that of its template,
+// as the controller runs it. A ModelDeployment whose template doesn't exist
+// yet is validated as written; the controller reports the missing template.
+func (v *ModelDeploymentValidator) resolveTemplate(ctx context.Context, md *ModelDeployment) (*ModelDeployment, error) {
+	if md.Spec.TemplateName == "" || v.Client == nil {
+		return md, nil
+	}
+
+	template := ModelDeploymentTemplate{}
+	err := v.Client.Get(ctx, client.ObjectKey{Name: md.Spec.TemplateName}, &template)
+	if apierrors.IsNotFound(err) {
+		return md, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := MergeTemplateSpec(&template.Spec, &md.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("merging ModelDeploymentTemplate %q: %w", template.Name, err)
+	}
+	merged := md.DeepCopy()
+	merged.Spec = *spec
+	return merged, nil
+}
+
+// Validate validates the spec of md, which the controller also calls with
+// the spec resolved from its template
+func (v *ModelDeploymentValidator) Validate(ctx context.Context, md *ModelDeployment) error {
+	var allErrs field.ErrorList
+
+	if md.Spec.Offline && md.Spec.ModelCache == nil {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "modelCache"),
+			"offline mode loads the model from a pre-populated cache"))
+	}
+
+	if md.Spec.RestartPolicy != "" && md.Spec.RestartPolicy != corev1.RestartPolicyAlways {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "restartPolicy"), md.Spec.RestartPolicy,
+			[]string{string(corev1.RestartPolicyAlways)}))
+	}
+
+	if md.Spec.ExclusiveNode && md.Spec.Runtime != "gpu" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "exclusiveNode"), md.Spec.ExclusiveNode,
+			"requires the gpu runtime"))
+	}
+
+	if autoscaling := md.Spec.Autoscaling; autoscaling != nil && autoscaling.MinReplicas != nil &&
+		*autoscaling.MinReplicas > autoscaling.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "autoscaling", "minReplicas"),
+			*autoscaling.MinReplicas, "must not be greater than maxReplicas"))
+	}
+
+	if rightsizing := md.Spec.Rightsizing; rightsizing != nil {
+		path := field.NewPath("spec", "rightsizing")
+		if rightsizing.MinReplicas != nil && *rightsizing.MinReplicas > rightsizing.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(path.Child("minReplicas"),
+				*rightsizing.MinReplicas, "must not be greater than maxReplicas"))
+		}
+		if rightsizing.TargetRequestRate.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("targetRequestRate"),
+				rightsizing.TargetRequestRate.String(), "must be positive"))
+		}
+		if rightsizing.Interval != nil && rightsizing.Interval.Duration < time.Minute {
+			allErrs = append(allErrs, field.Invalid(path.Child("interval"),
+				rightsizing.Interval.Duration.String(), "must be at least 1m"))
+		}
+	}
+
+	allErrs = append(allErrs, validateExclusiveFields(&md.Spec)...)
+
+	if md.Spec.ClusterIP != "" {
+		path := field.NewPath("spec", "clusterIP")
+		ip := net.ParseIP(md.Spec.ClusterIP)
+		if ip == nil {
+			allErrs = append(allErrs, field.Invalid(path, md.Spec.ClusterIP, "must be a valid IP address"))
+		} else if v.ServiceCIDR != nil && !v.ServiceCIDR.Contains(ip) {
+			allErrs = append(allErrs, field.Invalid(path, md.Spec.ClusterIP,
+				fmt.Sprintf("must be in the service CIDR %s", v.ServiceCIDR)))
+		}
+	}
+
+	if md.Spec.EgressPolicy != nil {
+		for i, cidr := range md.Spec.EgressPolicy.AllowedCIDRs {
+			_, _, err := net.ParseCIDR(cidr)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "egressPolicy", "allowedCIDRs").Index(i),
+					cidr, "must be a CIDR such as 203.0.113.0/24"))
+			}
+		}
+		if dns := md.Spec.EgressPolicy.DNS; dns != nil {
+			for i, cidr := range dns.CIDRs {
+				_, _, err := net.ParseCIDR(cidr)
+				if err != nil {
+					allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "egressPolicy", "dns", "cidrs").Index(i),
+						cidr, "must be a CIDR such as 169.254.20.10/32"))
+				}
+			}
+		}
+		for i, port := range md.Spec.EgressPolicy.Ports {
+			if port < 1 || port > 65535 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "egressPolicy", "ports").Index(i),
+					port, "must be between 1 and 65535"))
+			}
+		}
+	}
+
+	if md.Spec.SystemPrompt != "" && md.Spec.ChatTemplate == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "chatTemplate"),
+			"the system prompt is injected through the chat template"))
+	}
+
+	if (len(md.Spec.LoRAAdapters) > 0 || md.Spec.LoRAHotReload) && md.Spec.ModelCache == nil {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "modelCache"),
+			"LoRA adapters are loaded from the model cache claim"))
+	}
+	seenAdapters := map[string]bool{}
+	for i, adapter := range md.Spec.LoRAAdapters {
+		path := field.NewPath("spec", "loraAdapters").Index(i).Child("name")
+		if seenAdapters[adapter.Name] {
+			allErrs = append(allErrs, field.Duplicate(path, adapter.Name))
+		}
+		if adapter.Name == md.Spec.ModelName {
+			allErrs = append(allErrs, field.Invalid(path, adapter.Name, "must differ from the base model name"))
+		}
+		seenAdapters[adapter.Name] = true
+	}
+
+	if md.Spec.Metrics != nil && md.Spec.Metrics.ServiceMonitor != nil {
+		allErrs = append(allErrs, validateServiceMonitor(md.Spec.Metrics.ServiceMonitor)...)
+	}
+
+	seenSysctls := map[string]bool{}
+	for i, sysctl := range md.Spec.Sysctls {
+		path := field.NewPath("spec", "sysctls").Index(i).Child("name")
+		if !sysctlNameRegexp.MatchString(sysctl.Name) {
+			allErrs = append(allErrs, field.Invalid(path, sysctl.Name, "must be a sysctl name such as net.core.somaxconn"))
+		} else if seenSysctls[sysctl.Name] {
+			allErrs = append(allErrs, field.Duplicate(path, sysctl.Name))
+		}
+		seenSysctls[sysctl.Name] = true
+	}
+
+	if slices.Contains(reservedContainerNames, md.Spec.ContainerName) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "containerName"), md.Spec.ContainerName,
+			"is the name of a container the controller adds to the pods"))
+	}
+
+	allErrs = append(allErrs, v.validatePrivileges(&md.Spec)...)
+
+	if md.Spec.Rollout != nil && md.Spec.WorkloadType != WorkloadTypeRollout {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "workloadType"), md.Spec.WorkloadType,
+			"must be Rollout to configure the rollout"))
+	}
+
+	if md.Spec.AutoRollback != nil && md.Spec.WorkloadType != "" && md.Spec.WorkloadType != WorkloadTypeDeployment {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "workloadType"), md.Spec.WorkloadType,
+			"must be Deployment to roll back failed rollouts automatically"))
+	}
+
+	if md.Spec.ReplicaServices && md.Spec.WorkloadType != WorkloadTypeStatefulSet {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "workloadType"), md.Spec.WorkloadType,
+			"must be StatefulSet to address replicas through their own Service"))
+	}
+
+	if md.Spec.CPUFallback != nil && md.Spec.Runtime != "gpu" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "runtime"), md.Spec.Runtime,
+			"must be gpu to fall back to the cpu runtime"))
+	}
+
+	if md.Spec.GPUTuning != nil {
+		allErrs = append(allErrs, v.validateGPUTuning(md)...)
+	}
+
+	if md.Spec.CPUOffloadGB < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "cpuOffloadGB"), md.Spec.CPUOffloadGB,
+			"must not be negative"))
+	} else if offload := md.Spec.CPUOffloadMemory(); md.Spec.CPUOffloadGB > 0 && md.Spec.EvictionPriority != nil &&
+		md.Spec.EvictionPriority.Memory.Cmp(offload) < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "evictionPriority", "memory"),
+			md.Spec.EvictionPriority.Memory.String(),
+			fmt.Sprintf("must cover the %s of host memory the model is offloaded to", offload.String())))
+	}
+
+	if md.Spec.MPS != nil && md.Spec.Runtime != "gpu" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "runtime"), md.Spec.Runtime,
+			"must be gpu to share GPUs through MPS"))
+	}
+
+	if md.Spec.RopeScaling != "" {
+		var ropeScaling map[string]interface{}
+		if err := json.Unmarshal([]byte(md.Spec.RopeScaling), &ropeScaling); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "ropeScaling"), md.Spec.RopeScaling,
+				fmt.Sprintf("must be a JSON object: %v", err)))
+		}
+	}
+
+	if gpus := md.Spec.RequestedGPUs(); md.Spec.AutoTensorParallel && gpus&(gpus-1) != 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "gpuCount"), gpus,
+			"must be a power of 2 for autoTensorParallel"))
+	}
+	if probes := md.Spec.Probes; probes != nil && len(probes.ExecCommand) == 0 && probes.Generation == nil {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "probes"),
+			"one of execCommand or generation must be set"))
+	}
+
+	if fieldErr := validateParallelism(&md.Spec); fieldErr != nil {
+		allErrs = append(allErrs, fieldErr)
+	}
+
+	// The pool is set as the value of a node label and a taint
+	if md.Spec.NodePool != "" {
+		for _, msg := range validation.IsValidLabelValue(md.Spec.NodePool) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "nodePool"), md.Spec.NodePool, msg))
+		}
+		if value, ok := md.Spec.NodeSelectorLabels[v.NodePoolLabel]; ok && v.NodePoolLabel != "" {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "nodeSelectorLabels").Key(v.NodePoolLabel), value,
+				"conflicts with spec.nodePool, which selects the same label"))
+		}
+	}
+
+	// The nodes of an autoscaled pool may not exist until the pods need them
+	if v.ValidateGPUCapacity && md.Spec.NodePool == "" {
+		fieldErr, err := v.validateGPUCapacity(ctx, md)
+		if err != nil {
+			return err
+		}
+		if fieldErr != nil {
+			allErrs = append(allErrs, fieldErr)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: GroupVersion.Group, Kind: "ModelDeployment"},
+		md.Name, allErrs)
+}
+
+// reservedContainerNames are the names of the sidecars and init containers
+// the controller runs next to the model server, which it may not be named
+var reservedContainerNames = []string{"log-shipper", "audit-log-rotator", "model-download", "gpu-tuning"}
+
+// sysctlNameRegexp matches sysctl names in dot or slash notation, as the
+// API server validates them
+var sysctlNameRegexp = regexp.MustCompile(`^([a-z0-9]([-_a-z0-9]*[a-z0-9])?[\./])*[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`)
+
+// safeSysctls are the sysctls the kubelet allows by default
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.tcp_syncookies":             true,
+	"net.ipv4.ping_group_range":           true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.ip_local_reserved_ports":    true,
+	"net.ipv4.tcp_keepalive_time":         true,
+	"net.ipv4.tcp_fin_timeout":            true,
+	"net.ipv4.tcp_keepalive_intvl":        true,
+	"net.ipv4.tcp_keepalive_probes":       true,
+}
+
+// sysctlWarnings warns about the sysctls pods are only admitted with on
+// nodes allowing them explicitly
+func sysctlWarnings(md *ModelDeployment) admission.Warnings {
+	var unsafe []string
+	for _, sysctl := range md.Spec.Sysctls {
+		if !safeSysctls[strings.ReplaceAll(sysctl.Name, "/", ".")] {
+			unsafe = append(unsafe, sysctl.Name)
+		}
+	}
+	if len(unsafe) == 0 {
+		return nil
+	}
+
+	return admission.Warnings{fmt.Sprintf(
+		"spec.sysctls: %s are unsafe sysctls; the model pods are rejected on nodes whose kubelet does not allow them with --allowed-unsafe-sysctls",
+		strings.Join(unsafe, ", "))}
+}
+
+// validateParallelism returns an error if the GPUs vLLM shards the model
+// across, the tensor times the pipeline parallel size, are not the GPUs of a
+// replica, which vLLM fails on at startup
+func validateParallelism(spec *ModelDeploymentSpec) *field.Error {
+	if spec.Runtime != "gpu" || (spec.TensorParallelSize == 0 && spec.PipelineParallelSize == 0) {
+		return nil
+	}
+
+	tensor := max(spec.TensorParallelSize, 1)
+	pipeline := max(spec.PipelineParallelSize, 1)
+	gpus := spec.RequestedGPUs()
+	if gpus == tensor*pipeline {
+		return nil
+	}
+
+	return field.Invalid(field.NewPath("spec", "gpuCount"), gpus,
+		fmt.Sprintf("must equal tensorParallelSize times pipelineParallelSize, %d x %d = %d", tensor, pipeline, tensor*pipeline))
+}
+
+// specField is a spec field taking part in an exclusivity rule
+type specField struct {
+	name  string
+	isSet func(spec *ModelDeploymentSpec) bool
+}
+
+var (
+	replicasField           = specField{"replicas", func(spec *ModelDeploymentSpec) bool { return spec.Replicas > 0 }}
+	replicasPerNodeField    = specField{"replicasPerNode", func(spec *ModelDeploymentSpec) bool { return spec.ReplicasPerNode > 0 }}
+	autoscalingField        = specField{"autoscaling", func(spec *ModelDeploymentSpec) bool { return spec.Autoscaling != nil }}
+	rampUpField             = specField{"rampUp", func(spec *ModelDeploymentSpec) bool { return spec.RampUp }}
+	exclusiveNodeField      = specField{"exclusiveNode", func(spec *ModelDeploymentSpec) bool { return spec.ExclusiveNode }}
+	highAvailabilityField   = specField{"highAvailability", func(spec *ModelDeploymentSpec) bool { return spec.HighAvailability }}
+	rightsizingField        = specField{"rightsizing", func(spec *ModelDeploymentSpec) bool { return spec.Rightsizing != nil }}
+	autoTensorParallelField = specField{"autoTensorParallel", func(spec *ModelDeploymentSpec) bool { return spec.AutoTensorParallel }}
+	tensorParallelSizeField = specField{"tensorParallelSize", func(spec *ModelDeploymentSpec) bool { return spec.TensorParallelSize > 0 }}
+	pipelineParallelField   = specField{"pipelineParallelSize", func(spec *ModelDeploymentSpec) bool { return spec.PipelineParallelSize > 0 }}
+)
+
+// exclusiveFields lists the groups of spec fields of which at most one may
+// be set. New rules belong here rather than in validate.
+var exclusiveFields = [][]specField{
+	// The autoscaler owns the replica count
+	{autoscalingField, replicasField},
+	{autoscalingField, replicasPerNodeField},
+	{autoscalingField, rampUpField},
+	// So does rightsizing
+	{rightsizingField, replicasField},
+	{rightsizingField, replicasPerNodeField},
+	{rightsizingField, rampUpField},
+	{rightsizingField, autoscalingField},
+	// Exclusive replicas repel each other, so only one fits on a node
+	{exclusiveNodeField, replicasPerNodeField},
+	// Rollouts of exclusive replicas can't surge, as no node is free
+	{highAvailabilityField, exclusiveNodeField},
+	// Automatic tensor parallelism shards each layer across all GPUs
+	{autoTensorParallelField, tensorParallelSizeField},
+	{autoTensorParallelField, pipelineParallelField},
+}
+
+// validateExclusiveFields returns an error for every group of exclusive
+// fields with more than one of them set, naming all of the set ones
+func validateExclusiveFields(spec *ModelDeploymentSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, fields := range exclusiveFields {
+		var set []string
+		for _, f := range fields {
+			if f.isSet(spec) {
+				set = append(set, field.NewPath("spec", f.name).String())
+			}
+		}
+		if len(set) > 1 {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", fields[0].name),
+				fmt.Sprintf("%s are mutually exclusive", strings.Join(set, ", "))))
+		}
+	}
+
+	return allErrs
+}
+
+// colocationWarnings warns when the ModelDeployments capping their GPU
+// memory that may share a GPU with md, as they select the same nodes, add up
+// to more than the whole GPU. It is best effort: a failure to list is only
+// logged, and models may share a GPU with ones selecting other nodes too.
+func (v *ModelDeploymentValidator) colocationWarnings(ctx context.Context, md *ModelDeployment) admission.Warnings {
+	if md.Spec.GPUMemoryUtilization == "" || v.Client == nil {
+		return nil
+	}
+
+	mds := ModelDeploymentList{}
+	err := v.Client.List(ctx, &mds)
+	if err != nil {
+		modeldeploymentlog.Error(err, "unable to list model deployments for colocation")
+		return nil
+	}
+
+	total := gpuMemoryFraction(md)
+	var colocated []string
+	for _, other := range mds.Items {
+		if other.Namespace == md.Namespace && other.Name == md.Name {
+			continue
+		}
+		if other.Spec.GPUMemoryUtilization == "" || other.Spec.Runtime != md.Spec.Runtime ||
+			other.Spec.GPUProduct != md.Spec.GPUProduct ||
+			!equality.Semantic.DeepEqual(other.Spec.NodeSelectorLabels, md.Spec.NodeSelectorLabels) {
+			continue
+		}
+
+		total += gpuMemoryFraction(&other)
+		colocated = append(colocated, other.Namespace+"/"+other.Name)
+	}
+
+	if total <= 1 {
+		return nil
+	}
+
+	return admission.Warnings{fmt.Sprintf(
+		"spec.gpuMemoryUtilization: together with %s, which may share a GPU, the GPU memory utilization adds up to %.2f",
+		strings.Join(colocated, ", "), total)}
+}
+
+func gpuMemoryFraction(md *ModelDeployment) float64 {
+	fraction, err := strconv.ParseFloat(md.Spec.GPUMemoryUtilization, 64)
+	if err != nil {
+		return 0
+	}
+
+	return fraction
+}
+
+// validateGPUCapacity checks that at least one schedulable node matching the
+// node selector has enough allocatable GPUs for a single replica.
+func (v *ModelDeploymentValidator) validateGPUCapacity(ctx context.Context, md *ModelDeployment) (*field.Error, error) {
+	requested := md.Spec.RequestedGPUs()
+	if requested == 0 {
+		return nil, nil
+	}
+
+	nodes := corev1.NodeList{}
+	err := v.Client.List(ctx, &nodes, client.MatchingLabels(md.Spec.NodeSelectorLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	resourceName := md.Spec.GPUResource()
+	var largest int64
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		if gpus, ok := node.Status.Allocatable[resourceName]; ok && gpus.Value() > largest {
+			largest = gpus.Value()
+		}
+	}
+
+	if int64(requested) > largest {
+		return field.Invalid(field.NewPath("spec", "gpuCount"), requested,
+			fmt.Sprintf("no schedulable node can fit %d %s, the largest offers %d", requested, resourceName, largest)), nil
+	}
+
+	return nil, nil
+}
+
+// validateServiceMonitor checks the scrape timeout fits in the interval,
+// which Prometheus otherwise rejects when loading the whole configuration
+func validateServiceMonitor(sm *ServiceMonitorSpec) field.ErrorList {
+	if sm.Interval == "" || sm.ScrapeTimeout == "" {
+		return nil
+	}
+
+	interval, err := model.ParseDuration(string(sm.Interval))
+	if err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec", "metrics", "serviceMonitor", "interval"),
+			sm.Interval, err.Error())}
+	}
+	path := field.NewPath("spec", "metrics", "serviceMonitor", "scrapeTimeout")
+	timeout, err := model.ParseDuration(string(sm.ScrapeTimeout))
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, sm.ScrapeTimeout, err.Error())}
+	}
+	if timeout > interval {
+		return field.ErrorList{field.Invalid(path, sm.ScrapeTimeout, "must not exceed the interval")}
+	}
+
+	return nil
+}
+
+// validatePrivileges checks spec only uses the host access and node
+// remediation allowed on the cluster, and images from the allowed
+// registries. Templates are held to the same checks.
+func (v *ModelDeploymentValidator) validatePrivileges(spec *ModelDeploymentSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.HostPID && !v.AllowHostPID {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "hostPID"),
+			"hostPID is not allowed on this cluster"))
+	}
+	if spec.GPUTuning != nil && !v.AllowGPUTuning {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "gpuTuning"),
+			"GPU tuning is not allowed on this cluster"))
+	}
+	if spec.MPS != nil && !v.AllowMPS {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "mps"),
+			"MPS is not allowed on this cluster"))
+	}
+	if wedged := spec.WedgedGPU; wedged != nil && wedged.Action != "" && wedged.Action != WedgedGPUActionEvent &&
+		!v.AllowNodeRemediation {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "wedgedGPU", "action"),
+			fmt.Sprintf("%s is not allowed on this cluster", wedged.Action)))
+	}
+
+	return append(allErrs, v.validateImageRegistries(spec)...)
+}
+
+// validateImageRegistries checks the runtime image and the images set in the
+// spec are pulled from the allowed registries
+func (v *ModelDeploymentValidator) validateImageRegistries(spec *ModelDeploymentSpec) field.ErrorList {
+	if len(v.AllowedImageRegistries) == 0 {
+		return nil
+	}
+
+	type specImage struct {
+		path  *field.Path
+		image string
+	}
+	images := []specImage{{field.NewPath("spec", "runtime"), spec.RuntimeImage()}}
+	if spec.CPUFallback != nil {
+		images = append(images, specImage{field.NewPath("spec", "cpuFallback"), CPURuntimeImage})
+	}
+	if spec.Logging != nil && spec.Logging.Sidecar != nil {
+		images = append(images, specImage{field.NewPath("spec", "logging", "sidecar", "image"), spec.Logging.Sidecar.Image})
+	}
+	if spec.AuditLogging != nil {
+		images = append(images, specImage{field.NewPath("spec", "auditLogging", "image"), spec.AuditLogging.Image})
+	}
+	if spec.GPUTuning != nil {
+		images = append(images, specImage{field.NewPath("spec", "gpuTuning", "image"), spec.GPUTuning.Image})
+	}
+	if spec.PrefixCache != nil {
+		images = append(images, specImage{field.NewPath("spec", "prefixCache", "image"), spec.PrefixCache.Image})
+	}
+
+	var allErrs field.ErrorList
+	for _, image := range images {
+		if image.image == "" || ImageRegistryAllowed(image.image, v.AllowedImageRegistries) {
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(image.path, fmt.Sprintf(
+			"image %s is not from an allowed registry; allowed registries are %s",
+			image.image, strings.Join(v.AllowedImageRegistries, ", "))))
+	}
+
+	return allErrs
+}
+
+// validateGPUTuning checks GPU tuning sets a limit on GPUs
+func (v *ModelDeploymentValidator) validateGPUTuning(md *ModelDeployment) field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec", "gpuTuning")
+	tuning := md.Spec.GPUTuning
+
+	if md.Spec.Runtime != "gpu" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "runtime"), md.Spec.Runtime,
+			"must be gpu to tune the GPUs"))
+	}
+	if tuning.PowerLimitWatts == 0 && tuning.LockedGraphicsClocks == nil {
+		allErrs = append(allErrs, field.Required(path, "set powerLimitWatts or lockedGraphicsClocks"))
+	}
+	if clocks := tuning.LockedGraphicsClocks; clocks != nil && clocks.MinMHz > clocks.MaxMHz {
+		allErrs = append(allErrs, field.Invalid(path.Child("lockedGraphicsClocks", "minMHz"), clocks.MinMHz,
+			"must not exceed maxMHz"))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,894 @@
+package v1
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func gpuNode(name string, gpus string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				DefaultGPUResourceName: resource.MustParse(gpus),
+			},
+		},
+	}
+}
+
+var _ = Describe("ModelDeployment Webhook", func() {
+	ctx := context.Background()
+
+	var md *ModelDeployment
+
+	BeforeEach(func() {
+		md = &ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-webhook",
+				Namespace: "default",
+			},
+			Spec: ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Runtime:   "gpu",
+			},
+		}
+	})
+
+	Context("When validating GPU capacity", func() {
+		var validator *ModelDeploymentValidator
+
+		BeforeEach(func() {
+			cordoned := gpuNode("cordoned", "8", nil)
+			cordoned.Spec.Unschedulable = true
+
+			validator = &ModelDeploymentValidator{
+				Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+					gpuNode("small", "2", map[string]string{"pool": "small"}),
+					gpuNode("large", "4", map[string]string{"pool": "large"}),
+					cordoned,
+				).Build(),
+				WebhookOptions: WebhookOptions{ValidateGPUCapacity: true},
+			}
+		})
+
+		It("should accept requests that fit on a schedulable node", func() {
+			md.Spec.GPUCount = 4
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject requests larger than any schedulable node", func() {
+			md.Spec.GPUCount = 8
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("the largest offers 4"))
+		})
+
+		It("should only consider nodes matching the node selector", func() {
+			md.Spec.GPUCount = 4
+			md.Spec.NodeSelectorLabels = map[string]string{"pool": "small"}
+
+			_, err := validator.ValidateUpdate(ctx, md.DeepCopy(), md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("should check the configured gpu resource", func() {
+			md.Spec.GPUResourceName = "amd.com/gpu"
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("amd.com/gpu"))
+		})
+
+		It("should leave capacity to the autoscaler of the node pool", func() {
+			md.Spec.GPUCount = 8
+			md.Spec.NodePool = "h100"
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should ignore deployments without GPUs", func() {
+			md.Spec.Runtime = "cpu"
+			md.Spec.GPUCount = 8
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should skip the check unless enabled", func() {
+			validator.ValidateGPUCapacity = false
+			validator.Client = fake.NewClientBuilder().WithScheme(testScheme).WithLists(&corev1.NodeList{}).Build()
+			md.Spec.GPUCount = 8
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating offline mode", func() {
+		validator := &ModelDeploymentValidator{}
+
+		It("should require a model cache", func() {
+			md.Spec.Offline = true
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.modelCache"))
+
+			md.Spec.ModelCache = &ModelCacheSpec{ClaimName: "models"}
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating automatic tensor parallelism", func() {
+		validator := &ModelDeploymentValidator{}
+
+		It("should require a power of 2 GPU count", func() {
+			md.Spec.AutoTensorParallel = true
+			md.Spec.GPUCount = 6
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("power of 2"))
+
+			md.Spec.GPUCount = 4
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating tensor and pipeline parallelism", func() {
+		DescribeTable("should require the GPU count to be their product",
+			func(gpuCount, tensor, pipeline int32, valid bool) {
+				md.Spec.Runtime = "gpu"
+				md.Spec.GPUCount = gpuCount
+				md.Spec.TensorParallelSize = tensor
+				md.Spec.PipelineParallelSize = pipeline
+
+				_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+				if valid {
+					Expect(err).NotTo(HaveOccurred())
+					return
+				}
+				Expect(apierrors.IsInvalid(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("spec.gpuCount"))
+				Expect(err.Error()).To(ContainSubstring("must equal tensorParallelSize times pipelineParallelSize"))
+			},
+			Entry("neither set", int32(4), int32(0), int32(0), true),
+			Entry("matching product", int32(8), int32(4), int32(2), true),
+			Entry("tensor parallel only", int32(4), int32(4), int32(0), true),
+			Entry("pipeline parallel only", int32(2), int32(0), int32(2), true),
+			Entry("one GPU by default", int32(0), int32(1), int32(1), true),
+			Entry("product above the GPU count", int32(4), int32(4), int32(2), false),
+			Entry("product below the GPU count", int32(8), int32(2), int32(2), false),
+			Entry("tensor parallel without the GPUs", int32(0), int32(2), int32(0), false),
+		)
+
+		It("should report the expected product", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 6
+			md.Spec.TensorParallelSize = 4
+			md.Spec.PipelineParallelSize = 2
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err.Error()).To(ContainSubstring("4 x 2 = 8"))
+		})
+
+		It("should not be combined with automatic tensor parallelism", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 4
+			md.Spec.AutoTensorParallel = true
+			md.Spec.PipelineParallelSize = 2
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.autoTensorParallel, spec.pipelineParallelSize are mutually exclusive"))
+		})
+	})
+
+	Context("When validating automatic rollbacks", func() {
+		It("should require the Deployment workload type", func() {
+			md.Spec.AutoRollback = &AutoRollbackSpec{}
+			md.Spec.WorkloadType = WorkloadTypeStatefulSet
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("must be Deployment to roll back failed rollouts automatically"))
+
+			md.Spec.WorkloadType = WorkloadTypeDeployment
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating probes", func() {
+		It("should require an exec command or a test generation", func() {
+			md.Spec.Probes = &ProbesSpec{}
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.probes: Required value"))
+
+			md.Spec.Probes.Generation = &GenerationProbeSpec{}
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the restart policy", func() {
+		validator := &ModelDeploymentValidator{}
+
+		It("should only allow Always", func() {
+			md.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.restartPolicy"))
+
+			md.Spec.RestartPolicy = corev1.RestartPolicyAlways
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating rope scaling", func() {
+		validator := &ModelDeploymentValidator{}
+
+		It("should require a JSON object", func() {
+			md.Spec.RopeScaling = `{"rope_type": "yarn", "factor": 4.0`
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.ropeScaling"))
+
+			md.Spec.RopeScaling = `"yarn"`
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+			md.Spec.RopeScaling = `{"rope_type": "yarn", "factor": 4.0}`
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When defaulting from a profile", func() {
+		var defaulter *ModelDeploymentDefaulter
+
+		BeforeEach(func() {
+			profiles := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kaimera-profiles",
+					Namespace: "kaimera-system",
+				},
+				Data: map[string]string{
+					"llama-7b-gpu": `
+modelName: meta-llama/Llama-2-7b-hf
+runtime: gpu
+gpuCount: 2
+maxModelLength: 4096
+labels:
+  tier: standard
+`,
+					"broken": "gpuCont: 2",
+				},
+			}
+
+			defaulter = &ModelDeploymentDefaulter{
+				Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(profiles).Build(),
+				WebhookOptions: WebhookOptions{
+					ProfilesConfigMap: types.NamespacedName{Namespace: "kaimera-system", Name: "kaimera-profiles"},
+				},
+			}
+		})
+
+		It("should fill in the profile", func() {
+			md.Spec = ModelDeploymentSpec{Profile: "llama-7b-gpu"}
+
+			Expect(defaulter.Default(ctx, md)).To(Succeed())
+			Expect(md.Spec.ModelName).To(Equal("meta-llama/Llama-2-7b-hf"))
+			Expect(md.Spec.Runtime).To(Equal("gpu"))
+			Expect(md.Spec.GPUCount).To(BeEquivalentTo(2))
+			Expect(md.Spec.Profile).To(Equal("llama-7b-gpu"))
+		})
+
+		It("should let the ModelDeployment override the profile", func() {
+			md.Spec = ModelDeploymentSpec{
+				Profile:  "llama-7b-gpu",
+				GPUCount: 4,
+				Labels:   map[string]string{"team": "search"},
+			}
+
+			Expect(defaulter.Default(ctx, md)).To(Succeed())
+			Expect(md.Spec.GPUCount).To(BeEquivalentTo(4))
+			Expect(md.Spec.MaxModelLength).To(BeEquivalentTo(4096))
+			Expect(md.Spec.Labels).To(Equal(map[string]string{"tier": "standard", "team": "search"}))
+		})
+
+		It("should reject unknown and invalid profiles", func() {
+			md.Spec.Profile = "missing"
+			err := defaulter.Default(ctx, md)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.profile"))
+
+			md.Spec.Profile = "broken"
+			err = defaulter.Default(ctx, md)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("gpuCont"))
+		})
+
+		It("should leave ModelDeployments without a profile alone", func() {
+			spec := md.Spec.DeepCopy()
+			Expect((&ModelDeploymentDefaulter{}).Default(ctx, md)).To(Succeed())
+			Expect(md.Spec).To(Equal(*spec))
+		})
+	})
+
+	Context("When validating hostPID", func() {
+		It("should only allow hostPID when enabled on the controller", func() {
+			md.Spec.HostPID = true
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.hostPID"))
+
+			validator := &ModelDeploymentValidator{WebhookOptions: WebhookOptions{AllowHostPID: true}}
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the container name", func() {
+		It("should reject the names of the containers added by the controller", func() {
+			for _, name := range []string{"log-shipper", "audit-log-rotator", "model-download", "gpu-tuning"} {
+				md.Spec.ContainerName = name
+				_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+				Expect(apierrors.IsInvalid(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("spec.containerName"))
+			}
+
+			md.Spec.ContainerName = "vllm"
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating exclusive nodes", func() {
+		It("should require the gpu runtime", func() {
+			md.Spec.ExclusiveNode = true
+			md.Spec.Runtime = "cpu"
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.exclusiveNode"))
+
+			md.Spec.Runtime = "gpu"
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating colocated GPU memory", func() {
+		colocated := func(name string, utilization string, nodeSelector map[string]string) *ModelDeployment {
+			return &ModelDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: ModelDeploymentSpec{
+					ModelName:            "facebook/opt-125m",
+					Runtime:              "gpu",
+					NodeSelectorLabels:   nodeSelector,
+					GPUMemoryUtilization: utilization,
+				},
+			}
+		}
+
+		var validator *ModelDeploymentValidator
+
+		BeforeEach(func() {
+			validator = &ModelDeploymentValidator{
+				Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+					colocated("small-a", "0.3", nil),
+					colocated("small-b", "0.3", nil),
+					colocated("elsewhere", "0.9", map[string]string{"pool": "large"}),
+				).Build(),
+			}
+		})
+
+		It("should warn when the models sharing a GPU exceed it", func() {
+			md.Spec.GPUMemoryUtilization = "0.5"
+
+			warnings, err := validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("default/small-a, default/small-b"))
+			Expect(warnings[0]).To(ContainSubstring("1.10"))
+		})
+
+		It("should not warn when the models fit", func() {
+			md.Spec.GPUMemoryUtilization = "0.4"
+
+			warnings, err := validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	Context("When validating a ModelDeployment with a template", func() {
+		It("should validate the spec merged with the template", func() {
+			template := &ModelDeploymentTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "autoscaled"},
+				Spec: ModelDeploymentSpec{
+					Autoscaling: &AutoscalingSpec{MaxReplicas: 4},
+					HostPID:     true,
+				},
+			}
+			validator := &ModelDeploymentValidator{
+				Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(template).Build(),
+			}
+			md.Spec.TemplateName = "autoscaled"
+			md.Spec.Replicas = 2
+
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.autoscaling, spec.replicas are mutually exclusive"))
+			Expect(err.Error()).To(ContainSubstring("spec.hostPID"))
+			Expect(md.Spec.Autoscaling).To(BeNil())
+
+			By("validating the spec as written until the template exists")
+			md.Spec.TemplateName = "missing"
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating autoscaling", func() {
+		It("should reject minReplicas above maxReplicas", func() {
+			minReplicas := int32(4)
+			md.Spec.Autoscaling = &AutoscalingSpec{MinReplicas: &minReplicas, MaxReplicas: 2}
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.autoscaling.minReplicas"))
+
+			md.Spec.Autoscaling.MaxReplicas = 4
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+	})
+
+	Context("When validating mutually exclusive fields", func() {
+		DescribeTable("should reject setting more than one of them",
+			func(set func(spec *ModelDeploymentSpec), conflicting string) {
+				set(&md.Spec)
+
+				_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+				Expect(apierrors.IsInvalid(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring(conflicting + " are mutually exclusive"))
+			},
+			Entry("autoscaling and replicas", func(spec *ModelDeploymentSpec) {
+				spec.Autoscaling = &AutoscalingSpec{MaxReplicas: 4}
+				spec.Replicas = 2
+			}, "spec.autoscaling, spec.replicas"),
+			Entry("autoscaling and replicasPerNode", func(spec *ModelDeploymentSpec) {
+				spec.Autoscaling = &AutoscalingSpec{MaxReplicas: 4}
+				spec.ReplicasPerNode = 1
+			}, "spec.autoscaling, spec.replicasPerNode"),
+			Entry("autoscaling and rampUp", func(spec *ModelDeploymentSpec) {
+				spec.Autoscaling = &AutoscalingSpec{MaxReplicas: 4}
+				spec.RampUp = true
+			}, "spec.autoscaling, spec.rampUp"),
+			Entry("exclusiveNode and replicasPerNode", func(spec *ModelDeploymentSpec) {
+				spec.ExclusiveNode = true
+				spec.ReplicasPerNode = 1
+			}, "spec.exclusiveNode, spec.replicasPerNode"),
+			Entry("highAvailability and exclusiveNode", func(spec *ModelDeploymentSpec) {
+				spec.HighAvailability = true
+				spec.ExclusiveNode = true
+			}, "spec.highAvailability, spec.exclusiveNode"),
+		)
+
+		It("should accept each field on its own", func() {
+			md.Spec.Autoscaling = &AutoscalingSpec{MaxReplicas: 4}
+			md.Spec.ExclusiveNode = true
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating a static cluster IP", func() {
+		It("should require an IP in the service CIDR", func() {
+			_, serviceCIDR, err := net.ParseCIDR("10.96.0.0/12")
+			Expect(err).NotTo(HaveOccurred())
+			validator := &ModelDeploymentValidator{WebhookOptions: WebhookOptions{ServiceCIDR: serviceCIDR}}
+
+			md.Spec.ClusterIP = "not-an-ip"
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.clusterIP"))
+
+			md.Spec.ClusterIP = "192.168.0.10"
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("10.96.0.0/12"))
+
+			md.Spec.ClusterIP = "10.96.100.10"
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should not allow changing it after creation", func() {
+			md.Spec.ClusterIP = "10.96.100.10"
+			updated := md.DeepCopy()
+			updated.Spec.ClusterIP = "10.96.100.11"
+
+			_, err := (&ModelDeploymentValidator{}).ValidateUpdate(ctx, md, updated)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.clusterIP"))
+
+			By("not allowing it to be removed")
+			updated.Spec.ClusterIP = ""
+			_, err = (&ModelDeploymentValidator{}).ValidateUpdate(ctx, md, updated)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+			By("not allowing it to be added")
+			_, err = (&ModelDeploymentValidator{}).ValidateUpdate(ctx, updated, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+			updated.Spec.ClusterIP = md.Spec.ClusterIP
+			_, err = (&ModelDeploymentValidator{}).ValidateUpdate(ctx, md, updated)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating sysctls", func() {
+		It("should reject invalid and duplicate names", func() {
+			md.Spec.Sysctls = []corev1.Sysctl{
+				{Name: "net.core.somaxconn", Value: "4096"},
+				{Name: "Net Core", Value: "1"},
+				{Name: "net.core.somaxconn", Value: "1024"},
+			}
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.sysctls[1].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.sysctls[2].name: Duplicate value"))
+		})
+
+		It("should warn about unsafe sysctls", func() {
+			md.Spec.Sysctls = []corev1.Sysctl{
+				{Name: "net.ipv4.tcp_keepalive_time", Value: "60"},
+				{Name: "net.core.somaxconn", Value: "4096"},
+			}
+
+			warnings, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("net.core.somaxconn"))
+			Expect(warnings[0]).NotTo(ContainSubstring("tcp_keepalive_time"))
+		})
+	})
+
+	Context("When validating LoRA adapters", func() {
+		It("should require unique names and the model cache", func() {
+			md.Spec.LoRAAdapters = []LoRAAdapter{
+				{Name: "sql", Path: "adapters/sql"},
+				{Name: "sql", Path: "adapters/sql-v2"},
+			}
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.modelCache: Required value"))
+			Expect(err.Error()).To(ContainSubstring("spec.loraAdapters[1].name: Duplicate value"))
+
+			md.Spec.ModelCache = &ModelCacheSpec{ClaimName: "models"}
+			md.Spec.LoRAAdapters[1].Name = "sql-v2"
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the system prompt", func() {
+		It("should require a chat template to inject it into", func() {
+			md.Spec.SystemPrompt = "You are a helpful assistant."
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.chatTemplate"))
+
+			md.Spec.ChatTemplate = "{{ messages }}"
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the ServiceMonitor", func() {
+		It("should reject a scrape timeout longer than the interval", func() {
+			md.Spec.Metrics = &MetricsSpec{ServiceMonitor: &ServiceMonitorSpec{Interval: "30s", ScrapeTimeout: "1m"}}
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.metrics.serviceMonitor.scrapeTimeout"))
+
+			md.Spec.Metrics.ServiceMonitor.ScrapeTimeout = "30s"
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When generating the webhook configurations", func() {
+		It("should fail closed with a short timeout", func() {
+			manifests, err := os.ReadFile("../../config/webhook/manifests.yaml")
+			Expect(err).NotTo(HaveOccurred())
+
+			var webhooks int
+			for _, doc := range strings.Split(string(manifests), "---\n") {
+				if strings.TrimSpace(doc) == "" {
+					continue
+				}
+				config := struct {
+					Webhooks []admissionregistrationv1.ValidatingWebhook
+				}{}
+				Expect(yaml.Unmarshal([]byte(doc), &config)).To(Succeed())
+
+				for _, webhook := range config.Webhooks {
+					webhooks++
+					Expect(webhook.FailurePolicy).NotTo(BeNil(), webhook.Name)
+					Expect(*webhook.FailurePolicy).To(Equal(admissionregistrationv1.Fail), webhook.Name)
+					Expect(webhook.TimeoutSeconds).NotTo(BeNil(), webhook.Name)
+					Expect(*webhook.TimeoutSeconds).To(BeNumerically("==", 5), webhook.Name)
+				}
+			}
+			Expect(webhooks).To(Equal(3))
+		})
+	})
+
+	Context("When validating GPU tuning", func() {
+		It("should only admit it when allowed on the controller", func() {
+			md.Spec.GPUTuning = &GPUTuningSpec{LockedGraphicsClocks: &ClockRange{MinMHz: 1400, MaxMHz: 1000}}
+
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.gpuTuning: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.gpuTuning.lockedGraphicsClocks.minMHz"))
+
+			validator := &ModelDeploymentValidator{WebhookOptions: WebhookOptions{AllowGPUTuning: true}}
+			md.Spec.GPUTuning = &GPUTuningSpec{PowerLimitWatts: 300}
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+
+			md.Spec.GPUTuning = &GPUTuningSpec{}
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.gpuTuning: Required value"))
+		})
+	})
+
+	Context("When validating the CPU fallback", func() {
+		It("should require the gpu runtime", func() {
+			md.Spec.CPUFallback = &CPUFallbackSpec{}
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+
+			md.Spec.Runtime = "cpu"
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.runtime"))
+		})
+	})
+
+	Context("When validating the rollout", func() {
+		It("should require the Rollout workload type", func() {
+			md.Spec.Rollout = &RolloutSpec{Strategy: RolloutStrategyBlueGreen}
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.workloadType"))
+
+			md.Spec.WorkloadType = WorkloadTypeRollout
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating MPS", func() {
+		It("should only admit it for the gpu runtime when allowed on the controller", func() {
+			md.Spec.MPS = &MPSSpec{}
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.mps: Forbidden"))
+
+			validator := &ModelDeploymentValidator{WebhookOptions: WebhookOptions{AllowMPS: true}}
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+
+			md.Spec.Runtime = "cpu"
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.runtime"))
+		})
+	})
+
+	Context("When validating the egress policy", func() {
+		It("should reject destinations that aren't CIDRs and invalid ports", func() {
+			md.Spec.EgressPolicy = &EgressPolicySpec{
+				AllowedCIDRs: []string{"203.0.113.0/24", "huggingface.co"},
+				Ports:        []int32{443, 70000},
+				DNS:          &EgressDNSSpec{CIDRs: []string{"169.254.20.10"}},
+			}
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.egressPolicy.allowedCIDRs[1]"))
+			Expect(err.Error()).To(ContainSubstring("spec.egressPolicy.ports[1]"))
+			Expect(err.Error()).To(ContainSubstring("spec.egressPolicy.dns.cidrs[0]"))
+
+			md.Spec.EgressPolicy.AllowedCIDRs = []string{"203.0.113.0/24"}
+			md.Spec.EgressPolicy.Ports = []int32{443}
+			md.Spec.EgressPolicy.DNS.CIDRs = []string{"169.254.20.10/32"}
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the node pool", func() {
+		It("should reject names that aren't label values", func() {
+			md.Spec.NodePool = "gpu pool"
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.nodePool"))
+
+			md.Spec.NodePool = "gpu-pool"
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject node selector labels on the node pool label", func() {
+			validator := &ModelDeploymentValidator{WebhookOptions: WebhookOptions{NodePoolLabel: "karpenter.sh/nodepool"}}
+			md.Spec.NodePool = "h100"
+			md.Spec.NodeSelectorLabels = map[string]string{"karpenter.sh/nodepool": "a100", "team": "ml"}
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.nodeSelectorLabels[karpenter.sh/nodepool]"))
+
+			delete(md.Spec.NodeSelectorLabels, "karpenter.sh/nodepool")
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating image registries", func() {
+		DescribeTable("should match images against the allowed registries",
+			func(image string, allowed bool) {
+				Expect(ImageRegistryAllowed(image, []string{"nvcr.io", "docker.io/vllm", "registry.local:5000/"})).To(Equal(allowed))
+			},
+			Entry("a registry host", "nvcr.io/nvidia/cuda:12.4.1-base-ubuntu22.04", true),
+			Entry("an image from docker.io", "vllm/vllm-openai:latest", true),
+			Entry("an image from docker.io by digest", "docker.io/vllm/vllm-openai@sha256:abc", true),
+			Entry("a registry with a port", "registry.local:5000/models/vllm:v1", true),
+			Entry("another docker.io organization", "patnaikshekhar/vllm-cpu:1", false),
+			Entry("an official docker.io image", "redis:7.4-alpine", false),
+			Entry("a registry sharing a prefix", "nvcr.io.example.com/cuda:12", false),
+		)
+
+		It("should only admit images from the allowed registries", func() {
+			validator := &ModelDeploymentValidator{WebhookOptions: WebhookOptions{
+				AllowedImageRegistries: []string{"docker.io/vllm", "nvcr.io"},
+			}}
+
+			md.Spec.Runtime = "gpu"
+			_, err := validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+
+			md.Spec.PrefixCache = &PrefixCacheSpec{Image: "ghcr.io/example/redis:7"}
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.prefixCache.image: Forbidden: image ghcr.io/example/redis:7 " +
+				"is not from an allowed registry; allowed registries are docker.io/vllm, nvcr.io"))
+
+			md.Spec.PrefixCache = nil
+			md.Spec.Runtime = "cpu"
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.runtime: Forbidden: image patnaikshekhar/vllm-cpu:1"))
+		})
+	})
+
+	Context("When validating CPU offload", func() {
+		It("should reject a negative size", func() {
+			md.Spec.CPUOffloadGB = -1
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.cpuOffloadGB"))
+		})
+
+		It("should require the reserved memory to cover the offloaded weights", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 2
+			md.Spec.CPUOffloadGB = 8
+			md.Spec.EvictionPriority = &EvictionPrioritySpec{
+				CPU:    resource.MustParse("4"),
+				Memory: resource.MustParse("12Gi"),
+			}
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.evictionPriority.memory: Invalid value: \"12Gi\": " +
+				"must cover the 16Gi of host memory the model is offloaded to"))
+
+			md.Spec.EvictionPriority.Memory = resource.MustParse("24Gi")
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating replica Services", func() {
+		It("should require the StatefulSet workload type", func() {
+			md.Spec.ReplicaServices = true
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.workloadType"))
+
+			md.Spec.WorkloadType = WorkloadTypeStatefulSet
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating wedged GPU detection", func() {
+		It("should only cordon nodes when node remediation is allowed", func() {
+			md.Spec.WedgedGPU = &WedgedGPUSpec{}
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+
+			md.Spec.WedgedGPU.Action = WedgedGPUActionCordon
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.wedgedGPU.action: Forbidden: Cordon is not allowed on this cluster"))
+
+			validator := &ModelDeploymentValidator{WebhookOptions: WebhookOptions{AllowNodeRemediation: true}}
+			_, err = validator.ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating rightsizing", func() {
+		It("should reject inverted bounds and a replica count it would override", func() {
+			minReplicas := int32(4)
+			md.Spec.Replicas = 2
+			md.Spec.Rightsizing = &RightsizingSpec{
+				PrometheusURL:     "http://prometheus.monitoring:9090",
+				MinReplicas:       &minReplicas,
+				MaxReplicas:       3,
+				TargetRequestRate: resource.MustParse("0"),
+			}
+			_, err := (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.rightsizing.minReplicas"))
+			Expect(err.Error()).To(ContainSubstring("spec.rightsizing.targetRequestRate"))
+			Expect(err.Error()).To(ContainSubstring("spec.rightsizing, spec.replicas are mutually exclusive"))
+
+			md.Spec.Replicas = 0
+			md.Spec.Rightsizing.MaxReplicas = 8
+			md.Spec.Rightsizing.TargetRequestRate = resource.MustParse("500m")
+			_, err = (&ModelDeploymentValidator{}).ValidateCreate(ctx, md)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// ModelDeploymentTemplate holds defaults shared by the ModelDeployments
+// naming it in their spec.templateName, e.g. the runtime, GPUs and
+// scheduling of a class of models
+type ModelDeploymentTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the spec the ModelDeployments inherit. Fields they set
+	// override it, and their maps are merged into its maps key by key. As
+	// unset and false can't be told apart, a ModelDeployment can't turn off
+	// a boolean its template turns on. The templateName of a template is
+	// ignored.
+	Spec ModelDeploymentSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelDeploymentTemplateList contains a list of ModelDeploymentTemplate
+type ModelDeploymentTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelDeploymentTemplate `json:"items"`
+}
+
+// MergeTemplateSpec returns the spec of a template overridden by the fields
+// set in spec. Objects, such as maps and nested specs, are merged field by
+// field; any other value set in spec replaces the template's.
+func MergeTemplateSpec(template, spec *ModelDeploymentSpec) (*ModelDeploymentSpec, error) {
+	base, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+	if err != nil {
+		return nil, err
+	}
+	// Templates don't chain
+	delete(base, "templateName")
+
+	merged := &ModelDeploymentSpec{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(mergeObjects(base, overrides), merged)
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func mergeObjects(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		baseObject, baseIsObject := merged[key].(map[string]interface{})
+		object, isObject := value.(map[string]interface{})
+		if baseIsObject && isObject {
+			merged[key] = mergeObjects(baseObject, object)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelDeploymentTemplate{}, &ModelDeploymentTemplateList{})
+}
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var modeldeploymenttemplatelog = logf.Log.WithName("modeldeploymenttemplate-resource")
+
+// SetupWebhookWithManager registers the validating webhook with the manager
+func (r *ModelDeploymentTemplate) SetupWebhookWithManager(mgr ctrl.Manager, opts WebhookOptions) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&ModelDeploymentTemplateValidator{WebhookOptions: opts}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-kaimera-ai-v1-modeldeploymenttemplate,mutating=false,failurePolicy=fail,sideEffects=None,timeoutSeconds=5,groups=kaimera.ai,resources=modeldeploymenttemplates,verbs=create;update,versions=v1,name=vmodeldeploymenttemplate.kb.io,admissionReviewVersions=v1
+
+// ModelDeploymentTemplateValidator validates ModelDeploymentTemplates on
+// admission. A template only holds part of a spec, so it is held to the
+// checks of what the cluster allows; the spec merged with a ModelDeployment
+// is validated whole by the ModelDeployment webhook and the controller.
+// +kubebuilder:object:generate=false
+type ModelDeploymentTemplateValidator struct {
+	WebhookOptions
+}
+
+var _ admission.CustomValidator = &ModelDeploymentTemplateValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *ModelDeploymentTemplateValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	template, ok := obj.(*ModelDeploymentTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelDeploymentTemplate but got a %T", obj)
+	}
+	modeldeploymenttemplatelog.Info("validate create", "name", template.Name)
+
+	return nil, v.validate(template)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *ModelDeploymentTemplateValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	template, ok := newObj.(*ModelDeploymentTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelDeploymentTemplate but got a %T", newObj)
+	}
+	modeldeploymenttemplatelog.Info("validate update", "name", template.Name)
+
+	return nil, v.validate(template)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *ModelDeploymentTemplateValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ModelDeploymentTemplateValidator) validate(template *ModelDeploymentTemplate) error {
+	mdValidator := ModelDeploymentValidator{WebhookOptions: v.WebhookOptions}
+	allErrs := mdValidator.validatePrivileges(&template.Spec)
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: GroupVersion.Group, Kind: "ModelDeploymentTemplate"},
+		template.Name, allErrs)
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ModelDeploymentTemplate Webhook", func() {
+	ctx := context.Background()
+
+	var template *ModelDeploymentTemplate
+
+	BeforeEach(func() {
+		template = &ModelDeploymentTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "h100"},
+			Spec: ModelDeploymentSpec{
+				Runtime:  "gpu",
+				GPUCount: 2,
+			},
+		}
+	})
+
+	It("should admit a partial spec", func() {
+		_, err := (&ModelDeploymentTemplateValidator{}).ValidateCreate(ctx, template)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only allow host access when enabled on the controller", func() {
+		template.Spec.HostPID = true
+		template.Spec.GPUTuning = &GPUTuningSpec{PowerLimitWatts: 300}
+		template.Spec.MPS = &MPSSpec{}
+		template.Spec.WedgedGPU = &WedgedGPUSpec{Action: WedgedGPUActionCordon}
+
+		_, err := (&ModelDeploymentTemplateValidator{}).ValidateUpdate(ctx, template, template)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("ModelDeploymentTemplate.kaimera.ai \"h100\" is invalid"))
+		for _, path := range []string{"spec.hostPID", "spec.gpuTuning", "spec.mps", "spec.wedgedGPU.action"} {
+			Expect(err.Error()).To(ContainSubstring(path))
+		}
+
+		validator := &ModelDeploymentTemplateValidator{WebhookOptions: WebhookOptions{
+			AllowHostPID:         true,
+			AllowGPUTuning:       true,
+			AllowMPS:             true,
+			AllowNodeRemediation: true,
+		}}
+		_, err = validator.ValidateUpdate(ctx, template, template)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject runtimes from other registries", func() {
+		validator := &ModelDeploymentTemplateValidator{WebhookOptions: WebhookOptions{
+			AllowedImageRegistries: []string{"registry.example.com"},
+		}}
+
+		_, err := validator.ValidateCreate(ctx, template)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("image vllm/vllm-openai:latest is not from an allowed registry"))
+	})
+})
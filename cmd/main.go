@@ -5,17 +5,30 @@ import (
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"path"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -25,6 +38,7 @@ import (
 	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
 	"github.com/kaimera-ai/kaimera/internal/controller"
 	"github.com/kaimera-ai/kaimera/internal/proxy"
+	"github.com/kaimera-ai/kaimera/internal/version"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -47,6 +61,26 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var validateGPUCapacity bool
+	var allowHostPID bool
+	var allowGPUTuning bool
+	var allowMPS bool
+	var allowNodeRemediation bool
+	var mpsPipeDirectory string
+	var rateLimiterBaseDelay time.Duration
+	var rateLimiterMaxDelay time.Duration
+	var gracefulShutdownTimeout time.Duration
+	var profilesConfigMap string
+	var serviceCIDR string
+	var discoveryTagAnnotation string
+	var discoveryModelAnnotation string
+	var resourceNamePrefix string
+	var allowedImageRegistries string
+	var nodePoolLabel string
+	var nodePoolTaint string
+	var defaultPriorityClass string
+	var tracingEndpoint string
+	var tracingInsecure bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -58,6 +92,58 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&validateGPUCapacity, "validate-gpu-capacity", false,
+		"If set, the webhook rejects ModelDeployments requesting more GPUs than any schedulable node offers.")
+	flag.BoolVar(&allowHostPID, "allow-host-pid", false,
+		"If set, ModelDeployments can run with hostPID, e.g. for GPU profilers.")
+	flag.BoolVar(&allowGPUTuning, "allow-gpu-tuning", false,
+		"If set, ModelDeployments can run a privileged init container to set GPU power and clock limits.")
+	flag.BoolVar(&allowMPS, "allow-mps", false,
+		"If set, ModelDeployments can share GPUs through the nodes' MPS control daemon.")
+	flag.StringVar(&mpsPipeDirectory, "mps-pipe-directory", "/tmp/nvidia-mps",
+		"The node directory the MPS control daemons listen in, the CUDA_MPS_PIPE_DIRECTORY they run with. "+
+			"It is mounted read-only into the pods of ModelDeployments setting mps.")
+	flag.BoolVar(&allowNodeRemediation, "allow-node-remediation", false,
+		"If set, ModelDeployments can cordon or taint the nodes of GPUs wedged by repeated liveness failures. "+
+			"The controller needs the permission to patch nodes, see config/rbac/node_remediation_role.yaml.")
+	flag.DurationVar(&rateLimiterBaseDelay, "rate-limiter-base-delay", 5*time.Millisecond,
+		"The initial delay before retrying a failed reconcile.")
+	flag.DurationVar(&rateLimiterMaxDelay, "rate-limiter-max-delay", 1000*time.Second,
+		"The maximum delay between retries of a failed reconcile.")
+	flag.StringVar(&discoveryTagAnnotation, "discovery-tag-annotation", "kaimera.ai/discovery-tag",
+		"The Service annotation carrying the discovery tag of ModelDeployments that enable discovery.")
+	flag.StringVar(&discoveryModelAnnotation, "discovery-model-annotation", "kaimera.ai/served-model-name",
+		"The Service annotation carrying the served model name of ModelDeployments that enable discovery.")
+	flag.StringVar(&profilesConfigMap, "profiles-configmap", "",
+		"The namespace/name of the ConfigMap holding the profiles ModelDeployments can reference. "+
+			"Profiles are disabled when unset.")
+	flag.StringVar(&serviceCIDR, "service-cidr", "",
+		"The cluster's service CIDR, which the webhook checks static cluster IPs against. "+
+			"The check is skipped when unset.")
+	flag.StringVar(&resourceNamePrefix, "resource-name-prefix", "",
+		"A prefix, e.g. ml-, prepended to the names of the resources generated for ModelDeployments. "+
+			"Names longer than 63 characters are shortened with a hash to stay unique.")
+	flag.StringVar(&allowedImageRegistries, "allowed-image-registries", "",
+		"A comma separated list of registries, optionally with a path, e.g. nvcr.io,docker.io/vllm, "+
+			"the images of ModelDeployments must be pulled from. Images without a registry are pulled from docker.io. "+
+			"Any registry is allowed when unset.")
+	flag.StringVar(&nodePoolLabel, "node-pool-label", "karpenter.sh/nodepool",
+		"The node label the node autoscaler sets to the name of a node's pool, "+
+			"e.g. cloud.google.com/gke-nodepool, selected by ModelDeployments setting nodePool.")
+	flag.StringVar(&nodePoolTaint, "node-pool-taint", "",
+		"The key of the taint the node autoscaler sets on the nodes of a pool, with the pool name as value, "+
+			"tolerated by ModelDeployments setting nodePool. No taint is tolerated when unset.")
+	flag.StringVar(&defaultPriorityClass, "default-priority-class", "",
+		"The priority class of the pods of ModelDeployments setting evictionPriority without a class, "+
+			"or highAvailability. The pods keep the cluster's default priority when unset.")
+	flag.StringVar(&tracingEndpoint, "tracing-endpoint", "",
+		"The host:port of an OTLP gRPC collector, e.g. otel-collector.observability:4317, "+
+			"to export a trace of each reconcile to. Tracing is disabled when unset.")
+	flag.BoolVar(&tracingInsecure, "tracing-insecure", false,
+		"If set, traces are exported to the tracing endpoint without TLS.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 30*time.Second,
+		"How long to wait for in-flight reconciles to finish on shutdown. "+
+			"Keep it below the pod's terminationGracePeriodSeconds.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -65,6 +151,38 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	setupLog.Info("starting kaimera", "version", version.Version, "commit", version.Commit)
+
+	// Generated Services are named after the prefix, so it must start a
+	// valid DNS label
+	if resourceNamePrefix != "" {
+		if errs := validation.IsDNS1035Label(resourceNamePrefix + "a"); len(errs) > 0 {
+			setupLog.Error(fmt.Errorf("%s", strings.Join(errs, "; ")), "invalid --resource-name-prefix", "value", resourceNamePrefix)
+			os.Exit(1)
+		}
+	}
+
+	for name, key := range map[string]string{"node-pool-label": nodePoolLabel, "node-pool-taint": nodePoolTaint} {
+		if key == "" {
+			continue
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			setupLog.Error(fmt.Errorf("%s", strings.Join(errs, "; ")), "invalid --"+name, "value", key)
+			os.Exit(1)
+		}
+	}
+
+	if !path.IsAbs(mpsPipeDirectory) {
+		setupLog.Error(fmt.Errorf("must be an absolute path"), "invalid --mps-pipe-directory", "value", mpsPipeDirectory)
+		os.Exit(1)
+	}
+
+	var registries []string
+	for _, registry := range strings.Split(allowedImageRegistries, ",") {
+		if registry = strings.TrimSpace(registry); registry != "" {
+			registries = append(registries, registry)
+		}
+	}
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -116,6 +234,17 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "7b76e57c.kaimera.ai",
+		// On SIGTERM the manager stops handing out work and waits for
+		// in-flight reconciles to finish, so a restart doesn't leave
+		// children half applied.
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
+		// Events are only listed to count the probe failures of model pods,
+		// which isn't worth caching all the events of the cluster for
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor: []client.Object{&corev1.Event{}},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -133,13 +262,99 @@ func main() {
 		os.Exit(1)
 	}
 
+	var tracerProvider trace.TracerProvider
+	if tracingEndpoint != "" {
+		provider, err := newTracerProvider(tracingEndpoint, tracingInsecure)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing", "endpoint", tracingEndpoint)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := provider.Shutdown(context.Background()); err != nil {
+				setupLog.Error(err, "unable to flush traces")
+			}
+		}()
+		tracerProvider = provider
+	}
+
+	var profiles types.NamespacedName
+	if profilesConfigMap != "" {
+		namespace, name, ok := strings.Cut(profilesConfigMap, "/")
+		if !ok {
+			setupLog.Error(nil, "--profiles-configmap must be namespace/name", "value", profilesConfigMap)
+			os.Exit(1)
+		}
+		profiles = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	var serviceNet *net.IPNet
+	if serviceCIDR != "" {
+		_, serviceNet, err = net.ParseCIDR(serviceCIDR)
+		if err != nil {
+			setupLog.Error(err, "invalid --service-cidr", "value", serviceCIDR)
+			os.Exit(1)
+		}
+	}
+
+	webhookOptions := kaimeraaiv1.WebhookOptions{
+		ValidateGPUCapacity: validateGPUCapacity,
+		ProfilesConfigMap:   profiles,
+		AllowHostPID:        allowHostPID,
+		AllowGPUTuning:      allowGPUTuning,
+		AllowMPS:            allowMPS,
+		ServiceCIDR:         serviceNet,
+
+		AllowedImageRegistries: registries,
+		AllowNodeRemediation:   allowNodeRemediation,
+		NodePoolLabel:          nodePoolLabel,
+	}
+
 	if err = (&controller.ModelDeploymentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		RateLimiterBaseDelay: rateLimiterBaseDelay,
+		RateLimiterMaxDelay:  rateLimiterMaxDelay,
+
+		DiscoveryTagAnnotation:   discoveryTagAnnotation,
+		DiscoveryModelAnnotation: discoveryModelAnnotation,
+
+		Recorder: mgr.GetEventRecorderFor("modeldeployment-controller"),
+
+		ResourceNamePrefix:     resourceNamePrefix,
+		AllowedImageRegistries: registries,
+		NodePoolLabel:          nodePoolLabel,
+		NodePoolTaint:          nodePoolTaint,
+		DefaultPriorityClass:   defaultPriorityClass,
+		AllowNodeRemediation:   allowNodeRemediation,
+		AllowHostPID:           allowHostPID,
+		AllowGPUTuning:         allowGPUTuning,
+		AllowMPS:               allowMPS,
+		MPSPipeDirectory:       mpsPipeDirectory,
+		TracerProvider:         tracerProvider,
+		Validator: &kaimeraaiv1.ModelDeploymentValidator{
+			Client:         mgr.GetClient(),
+			WebhookOptions: webhookOptions,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ModelDeployment")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&kaimeraaiv1.ModelDeployment{}).SetupWebhookWithManager(mgr, webhookOptions); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ModelDeployment")
+			os.Exit(1)
+		}
+		if err = (&kaimeraaiv1.ModelDeploymentTemplate{}).SetupWebhookWithManager(mgr, webhookOptions); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ModelDeploymentTemplate")
+			os.Exit(1)
+		}
+		// Keeps the webhook Service routing to the previous pod until this
+		// one serves admission requests, so rollouts don't fail CR writes
+		if err = mgr.AddReadyzCheck("webhook", webhookServer.StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook ready check")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -165,7 +380,7 @@ func main() {
 
 	eg.Go(func() error {
 		setupLog.Info("starting proxy server")
-		svr := proxy.New(mgr.GetClient(), proxyLog)
+		svr := proxy.New(mgr.GetClient(), proxyLog, resourceNamePrefix)
 		err := svr.Start(":9999")
 		if err != nil {
 			return err
@@ -180,3 +395,25 @@ func main() {
 		os.Exit(-1)
 	}
 }
+
+// newTracerProvider returns a provider batching the spans of the controller
+// to the OTLP gRPC collector at endpoint
+func newTracerProvider(endpoint string, insecure bool) (*sdktrace.TracerProvider, error) {
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName("kaimera"),
+			semconv.ServiceVersion(version.Version),
+		)),
+	), nil
+}
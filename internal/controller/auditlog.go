@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	auditLogVolume           = "audit-logs"
+	auditLogMountPath        = "/var/log/audit"
+	auditLogRotatorName      = "audit-log-rotator"
+	defaultAuditLogImage     = "busybox:1.36"
+	defaultAuditRetention    = 30
+	defaultAuditMaxFileSize  = 100
+	auditLogRotationInterval = 60
+)
+
+// podNameEnv exposes the name of the pod, which the audit log files are
+// named after
+var podNameEnv = corev1.EnvVar{
+	Name: "POD_NAME",
+	ValueFrom: &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+	},
+}
+
+// auditLogFile is the file on the audit volume the runtime of a pod logs
+// to, expanded by the shell of the container. It is per pod as the volume
+// may be shared by the replicas.
+var auditLogFile = path.Join(auditLogMountPath, "${POD_NAME}.log")
+
+// generateAuditLogVolume returns the volume of the audit log claim and its
+// mount in the runtime container
+func generateAuditLogVolume(md *kaimeraaiv1.ModelDeployment) (corev1.Volume, corev1.VolumeMount) {
+	return corev1.Volume{
+		Name: auditLogVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: md.Spec.AuditLogging.ClaimName,
+			},
+		},
+	}, corev1.VolumeMount{
+		Name:      auditLogVolume,
+		MountPath: auditLogMountPath,
+	}
+}
+
+// generateAuditLogRotator returns the sidecar rotating the audit log of the
+// pod once it reaches the maximum size, and pruning the rotated logs of all
+// replicas past the retention. The log is copied and truncated in place, as
+// the runtime keeps it open.
+func generateAuditLogRotator(md *kaimeraaiv1.ModelDeployment) corev1.Container {
+	spec := md.Spec.AuditLogging
+	retention := spec.RetentionDays
+	if retention == 0 {
+		retention = defaultAuditRetention
+	}
+	maxFileSize := spec.MaxFileSizeMB
+	if maxFileSize == 0 {
+		maxFileSize = defaultAuditMaxFileSize
+	}
+	image := spec.Image
+	if image == "" {
+		image = defaultAuditLogImage
+	}
+
+	script := fmt.Sprintf(`while true; do
+  if [ "$(stat -c %%s "%[1]s" 2>/dev/null || echo 0)" -ge %[2]d ]; then
+    rotated="%[1]s.$(date +%%Y%%m%%d%%H%%M%%S)"
+    cp "%[1]s" "$rotated" && : > "%[1]s" && gzip "$rotated"
+  fi
+  find %[3]s -name '*.log.*' -mtime +%[4]d -exec rm -f {} +
+  sleep %[5]d
+done`, auditLogFile, int64(maxFileSize)*1024*1024, auditLogMountPath, retention, auditLogRotationInterval)
+
+	return corev1.Container{
+		Name:    auditLogRotatorName,
+		Image:   image,
+		Command: []string{"/bin/sh", "-c", script},
+		Env:     []corev1.EnvVar{podNameEnv},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      auditLogVolume,
+				MountPath: auditLogMountPath,
+			},
+		},
+	}
+}
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	// specHashAnnotation records the hash of the pod template generated from
+	// the spec on the Deployment. The Deployment controller copies it to
+	// the ReplicaSet of each version, so the version to roll back to can be
+	// found.
+	specHashAnnotation = "kaimera.ai/spec-hash"
+
+	// defaultProgressDeadline matches the time the startup probe allows for
+	// loading the model
+	defaultProgressDeadline = 30 * time.Minute
+)
+
+// podTemplateHash returns a hash of the pod template
+func podTemplateHash(template *corev1.PodTemplateSpec) string {
+	// Marshalling a pod template can't fail
+	data, _ := json.Marshal(template)
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16]
+}
+
+// rolloutSucceeded returns whether all replicas of the Deployment run its
+// current version and are available, with no replicas of older versions left
+func rolloutSucceeded(dp *appsv1.Deployment) bool {
+	return rolloutComplete(dp) &&
+		dp.Status.Replicas == dp.Status.UpdatedReplicas &&
+		dp.Status.AvailableReplicas >= dp.Status.UpdatedReplicas
+}
+
+// setSpecHash annotates deploy with the hash of its pod template when md
+// rolls back failed rollouts. A version that was rolled back from is not
+// rolled out again: deploy keeps the template of current, the existing
+// Deployment, until the spec changes.
+func setSpecHash(deploy, current *appsv1.Deployment, md *kaimeraaiv1.ModelDeployment) {
+	if md.Spec.AutoRollback == nil {
+		return
+	}
+
+	hash := podTemplateHash(&deploy.Spec.Template)
+	if current != nil && hash == md.Status.RolledBackSpecHash {
+		deploy.Spec.Template = current.Spec.Template
+		deploy.Annotations[changeCauseAnnotation] = current.Annotations[changeCauseAnnotation]
+		hash = current.Annotations[specHashAnnotation]
+	}
+	deploy.Annotations[specHashAnnotation] = hash
+}
+
+// reconcileAutoRollback records the last version of the Deployment whose
+// replicas all became ready, and rolls back to it once the pods of a newer
+// version aren't ready within the progress deadline, measured from when the
+// Deployment changed to that version. It returns how long until the
+// deadline of a rollout in progress.
+func (r *ModelDeploymentReconciler) reconcileAutoRollback(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (time.Duration, error) {
+	if md.Spec.AutoRollback == nil || (md.Spec.WorkloadType != "" && md.Spec.WorkloadType != kaimeraaiv1.WorkloadTypeDeployment) {
+		removed := meta.RemoveStatusCondition(&md.Status.Conditions, kaimeraaiv1.ConditionRolledBack)
+		if !removed && md.Status.LastKnownGoodSpecHash == "" && md.Status.RolledBackSpecHash == "" && md.Status.SpecHash == "" {
+			return 0, nil
+		}
+		md.Status.LastKnownGoodSpecHash = ""
+		md.Status.RolledBackSpecHash = ""
+		md.Status.SpecHash = ""
+		md.Status.SpecHashTime = nil
+		return 0, r.updateStatus(ctx, md)
+	}
+
+	// Read after the children were applied, so the decision is made on the
+	// version just rolled out
+	dp := &appsv1.Deployment{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.Name)}, dp)
+	if err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+
+	hash := dp.Annotations[specHashAnnotation]
+	if hash == "" {
+		return 0, nil
+	}
+	if hash != md.Status.SpecHash {
+		now := r.now()
+		md.Status.SpecHash = hash
+		md.Status.SpecHashTime = &now
+		err = r.updateStatus(ctx, md)
+		if err != nil {
+			return 0, err
+		}
+	}
+	good := md.Status.LastKnownGoodSpecHash
+	if rolloutSucceeded(dp) {
+		if hash == good {
+			return 0, nil
+		}
+		md.Status.LastKnownGoodSpecHash = hash
+		return 0, r.updateStatus(ctx, md)
+	}
+	if good == "" || hash == good {
+		return 0, nil
+	}
+	if md.Status.RolledBackSpecHash != "" {
+		// A new version rolls out since the last rollback
+		md.Status.RolledBackSpecHash = ""
+		meta.RemoveStatusCondition(&md.Status.Conditions, kaimeraaiv1.ConditionRolledBack)
+		err := r.updateStatus(ctx, md)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	replicaSets := appsv1.ReplicaSetList{}
+	err = r.List(ctx, &replicaSets, client.InNamespace(dp.Namespace), client.MatchingLabels(dp.Spec.Selector.MatchLabels))
+	if err != nil {
+		return 0, err
+	}
+	var rolling, previous *appsv1.ReplicaSet
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if !metav1.IsControlledBy(rs, dp) {
+			continue
+		}
+		switch rs.Annotations[specHashAnnotation] {
+		case hash:
+			rolling = rs
+		case good:
+			previous = rs
+		}
+	}
+	// The Deployment controller hasn't started the rollout yet
+	if rolling == nil {
+		return 0, nil
+	}
+
+	deadline := defaultProgressDeadline
+	if md.Spec.AutoRollback.ProgressDeadline != nil {
+		deadline = md.Spec.AutoRollback.ProgressDeadline.Duration
+	}
+	// A ReplicaSet kept in the history is reused when the Deployment goes
+	// back to its version, so its age says nothing about the rollout
+	elapsed := r.now().Sub(md.Status.SpecHashTime.Time)
+	if elapsed < deadline {
+		return deadline - elapsed, nil
+	}
+
+	cond := metav1.Condition{
+		Type:               kaimeraaiv1.ConditionRolledBack,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ProgressDeadlineExceeded",
+		Message:            fmt.Sprintf("pods of version %s were not ready within %s, rolled back to version %s", hash, deadline, good),
+		ObservedGeneration: md.Generation,
+	}
+	if previous == nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "RevisionNotFound"
+		cond.Message = fmt.Sprintf("pods of version %s were not ready within %s, but the ReplicaSet of version %s to roll back to is gone", hash, deadline, good)
+		if !meta.SetStatusCondition(&md.Status.Conditions, cond) {
+			return 0, nil
+		}
+		r.warningEvent(md, "RollbackFailed", cond.Message)
+		return 0, r.updateStatus(ctx, md)
+	}
+
+	log.FromContext(ctx).Info("rolling back deployment", "from", hash, "to", good)
+	dp.Spec.Template = *previous.Spec.Template.DeepCopy()
+	delete(dp.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	annotations := map[string]string{}
+	for k, v := range dp.Annotations {
+		annotations[k] = v
+	}
+	annotations[specHashAnnotation] = good
+	annotations[changeCauseAnnotation] = fmt.Sprintf("rolled back to version %s", good)
+	dp.Annotations = annotations
+	err = r.update(ctx, dp)
+	if err != nil {
+		return 0, err
+	}
+
+	md.Status.RolledBackSpecHash = hash
+	meta.SetStatusCondition(&md.Status.Conditions, cond)
+	r.warningEvent(md, "RolledBack", cond.Message)
+	return 0, r.updateStatus(ctx, md)
+}
@@ -0,0 +1,319 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// bumpDeploymentGeneration has the fake client bump the generation of
+// Deployments whose spec changes, as the API server does, so a rollout
+// isn't complete before the Deployment controller observed it
+var bumpDeploymentGeneration = interceptor.Funcs{
+	Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+		if dp, ok := obj.(*appsv1.Deployment); ok {
+			dp.Generation = 1
+		}
+		return c.Create(ctx, obj, opts...)
+	},
+	Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+		if dp, ok := obj.(*appsv1.Deployment); ok {
+			current := appsv1.Deployment{}
+			err := c.Get(ctx, client.ObjectKeyFromObject(dp), &current)
+			if err != nil {
+				return err
+			}
+			dp.Generation = current.Generation
+			if !equality.Semantic.DeepEqual(dp.Spec, current.Spec) {
+				dp.Generation++
+			}
+		}
+		return c.Update(ctx, obj, opts...)
+	},
+}
+
+var _ = Describe("ModelDeployment automatic rollback", func() {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var reconciler *ModelDeploymentReconciler
+	var recorder *record.FakeRecorder
+	var fakeClock *clocktesting.FakePassiveClock
+	var md *kaimeraaiv1.ModelDeployment
+	var key client.ObjectKey
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "rollback",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Replicas:  1,
+				AutoRollback: &kaimeraaiv1.AutoRollbackSpec{
+					ProgressDeadline: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+		}
+		key = client.ObjectKeyFromObject(md)
+
+		recorder = record.NewFakeRecorder(10)
+		fakeClock = clocktesting.NewFakePassiveClock(now)
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				WithInterceptorFuncs(bumpDeploymentGeneration).
+				Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+			Clock:    fakeClock,
+		}
+	})
+
+	reconcileModel := func() ctrl.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	// startRollout creates the ReplicaSet the Deployment controller would
+	// for the current version of the Deployment
+	startRollout := func(name string) *appsv1.Deployment {
+		dp := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+
+		rs := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         dp.Namespace,
+				Labels:            dp.Spec.Selector.MatchLabels,
+				Annotations:       map[string]string{specHashAnnotation: dp.Annotations[specHashAnnotation]},
+				CreationTimestamp: metav1.NewTime(fakeClock.Now()),
+			},
+			Spec: appsv1.ReplicaSetSpec{
+				Selector: dp.Spec.Selector,
+				Template: *dp.Spec.Template.DeepCopy(),
+			},
+		}
+		rs.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = name
+		Expect(ctrl.SetControllerReference(dp, rs, scheme.Scheme)).To(Succeed())
+		Expect(reconciler.Create(ctx, rs)).To(Succeed())
+		return dp
+	}
+
+	updateModel := func(model string) {
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.ModelName = model
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+	}
+
+	// deploymentStatus returns the status of dp with replicas, of which
+	// updated run its current version, all of them available
+	deploymentStatus := func(dp *appsv1.Deployment, replicas, updated int32) appsv1.DeploymentStatus {
+		return appsv1.DeploymentStatus{
+			ObservedGeneration: dp.Generation,
+			Replicas:           replicas,
+			UpdatedReplicas:    updated,
+			ReadyReplicas:      replicas,
+			AvailableReplicas:  replicas,
+		}
+	}
+
+	servedModel := func() string {
+		dp := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		return dp.Spec.Template.Spec.Containers[0].Command[4]
+	}
+
+	It("should roll back to the last ready version once the deadline passes", func() {
+		reconcileModel()
+		dp := startRollout("rollback-good")
+		good := dp.Annotations[specHashAnnotation]
+		Expect(good).To(Equal(podTemplateHash(&dp.Spec.Template)))
+
+		dp.Status = deploymentStatus(dp, 1, 1)
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+		reconcileModel()
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(md.Status.LastKnownGoodSpecHash).To(Equal(good))
+
+		By("waiting for the pods of a new version until the deadline")
+		updateModel("facebook/opt-350m")
+		reconcileModel()
+		dp = startRollout("rollback-bad")
+		bad := dp.Annotations[specHashAnnotation]
+		Expect(bad).NotTo(Equal(good))
+		dp.Status = deploymentStatus(dp, 2, 1)
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+		fakeClock.SetTime(now.Add(4 * time.Minute))
+		Expect(reconcileModel().RequeueAfter).To(Equal(6 * time.Minute))
+		Expect(servedModel()).To(Equal("facebook/opt-350m"))
+
+		By("rolling back once it passes")
+		fakeClock.SetTime(now.Add(11 * time.Minute))
+		reconcileModel()
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		Expect(servedModel()).To(Equal("facebook/opt-125m"))
+		Expect(dp.Annotations).To(HaveKeyWithValue(specHashAnnotation, good))
+		Expect(dp.Spec.Template.Labels).NotTo(HaveKey(appsv1.DefaultDeploymentUniqueLabelKey))
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(md.Status.LastKnownGoodSpecHash).To(Equal(good))
+		Expect(md.Status.RolledBackSpecHash).To(Equal(bad))
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionRolledBack)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("ProgressDeadlineExceeded"))
+		Expect(cond.Message).To(Equal("pods of version " + bad + " were not ready within 10m0s, rolled back to version " + good))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning RolledBack")))
+
+		By("not rolling out the failed version again")
+		reconcileModel()
+		Expect(servedModel()).To(Equal("facebook/opt-125m"))
+
+		By("rolling out the next change of the spec")
+		updateModel("facebook/opt-1.3b")
+		reconcileModel()
+		Expect(servedModel()).To(Equal("facebook/opt-1.3b"))
+		reconcileModel()
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(md.Status.RolledBackSpecHash).To(BeEmpty())
+		Expect(meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionRolledBack)).To(BeNil())
+	})
+
+	It("should report a version it can't roll back to", func() {
+		reconcileModel()
+		dp := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		dp.Status = deploymentStatus(dp, 1, 1)
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+		reconcileModel()
+
+		updateModel("facebook/opt-350m")
+		reconcileModel()
+		dp = startRollout("rollback-bad")
+		dp.Status = deploymentStatus(dp, 2, 1)
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+		fakeClock.SetTime(now.Add(11 * time.Minute))
+		reconcileModel()
+		Expect(servedModel()).To(Equal("facebook/opt-350m"))
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(md.Status.RolledBackSpecHash).To(BeEmpty())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionRolledBack)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("RevisionNotFound"))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning RollbackFailed")))
+	})
+
+	It("should measure the deadline from when the Deployment went back to a previous version", func() {
+		reconcileModel()
+		dp := startRollout("rollback-first")
+		first := dp.Annotations[specHashAnnotation]
+		dp.Status = deploymentStatus(dp, 1, 1)
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+		reconcileModel()
+
+		updateModel("facebook/opt-350m")
+		reconcileModel()
+		dp = startRollout("rollback-second")
+		second := dp.Annotations[specHashAnnotation]
+		dp.Status = deploymentStatus(dp, 1, 1)
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+		reconcileModel()
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(md.Status.LastKnownGoodSpecHash).To(Equal(second))
+
+		By("reusing the ReplicaSet of the first version an hour later")
+		fakeClock.SetTime(now.Add(time.Hour))
+		updateModel("facebook/opt-125m")
+		Expect(reconcileModel().RequeueAfter).To(Equal(10 * time.Minute))
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		Expect(dp.Annotations).To(HaveKeyWithValue(specHashAnnotation, first))
+		dp.Status = deploymentStatus(dp, 2, 1)
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+
+		fakeClock.SetTime(now.Add(time.Hour + 4*time.Minute))
+		Expect(reconcileModel().RequeueAfter).To(Equal(6 * time.Minute))
+		Expect(servedModel()).To(Equal("facebook/opt-125m"))
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(md.Status.SpecHash).To(Equal(first))
+		Expect(md.Status.SpecHashTime.Time).To(BeTemporally("==", now.Add(time.Hour)))
+
+		By("rolling back once the deadline passes")
+		fakeClock.SetTime(now.Add(time.Hour + 11*time.Minute))
+		reconcileModel()
+		Expect(servedModel()).To(Equal("facebook/opt-350m"))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning RolledBack")))
+	})
+
+	DescribeTable("should roll back a version whose pods fail to start",
+		func(reason string) {
+			reconcileModel()
+			dp := startRollout("rollback-good")
+			dp.Status = deploymentStatus(dp, 1, 1)
+			Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+			reconcileModel()
+
+			updateModel("facebook/opt-350m")
+			reconcileModel()
+			dp = startRollout("rollback-bad")
+			dp.Status = deploymentStatus(dp, 2, 1)
+			Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rollback-bad-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "rollback", modelDeploymentLabel: "rollback"},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:  "vllm",
+							State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+						},
+					},
+				},
+			}
+			Expect(reconciler.Create(ctx, pod)).To(Succeed())
+
+			By("reporting the pods as degraded until the deadline")
+			fakeClock.SetTime(now.Add(4 * time.Minute))
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(BeAssignableToTypeOf(&degradedError{}))
+			Expect(servedModel()).To(Equal("facebook/opt-350m"))
+
+			By("rolling back once it passes")
+			fakeClock.SetTime(now.Add(11 * time.Minute))
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(BeAssignableToTypeOf(&degradedError{}))
+			Expect(servedModel()).To(Equal("facebook/opt-125m"))
+			Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(md.Status.Conditions, kaimeraaiv1.ConditionRolledBack)).To(BeTrue())
+		},
+		Entry("in CrashLoopBackOff", crashLoopBackOffReason),
+		Entry("in ImagePullBackOff", imagePullBackOffReason),
+	)
+})
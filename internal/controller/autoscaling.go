@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// minReplicas returns the lower bound of the autoscaled replica count,
+// warm pool included
+func minReplicas(md *kaimeraaiv1.ModelDeployment) int32 {
+	if md.Spec.Autoscaling.MinReplicas != nil {
+		return *md.Spec.Autoscaling.MinReplicas + warmPoolReplicas(md)
+	}
+
+	return 1 + warmPoolReplicas(md)
+}
+
+// preserveAutoscaledReplicas keeps the replica count the autoscaler chose
+// when the deployment is updated, rather than reverting it
+func preserveAutoscaledReplicas(deploy, current *appsv1.Deployment, md *kaimeraaiv1.ModelDeployment) {
+	if md.Spec.Autoscaling == nil || current == nil || current.Spec.Replicas == nil {
+		return
+	}
+
+	replicas := *current.Spec.Replicas
+	deploy.Spec.Replicas = &replicas
+}
+
+// reconcileAutoscaling keeps the HorizontalPodAutoscaler in sync with the
+// spec, and removes it once autoscaling is turned off
+func (r *ModelDeploymentReconciler) reconcileAutoscaling(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.Autoscaling == nil {
+		hpa := autoscalingv2.HorizontalPodAutoscaler{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.Name)}, &hpa)
+		if err != nil || !metav1.IsControlledBy(&hpa, md) {
+			return client.IgnoreNotFound(err)
+		}
+
+		log.FromContext(ctx).Info("deleting horizontal pod autoscaler")
+		return client.IgnoreNotFound(r.Delete(ctx, &hpa))
+	}
+
+	hpa, err := r.generateHPA(md)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(ctx, hpa, &autoscalingv2.HorizontalPodAutoscaler{})
+}
+
+func (r *ModelDeploymentReconciler) generateHPA(md *kaimeraaiv1.ModelDeployment) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	min := minReplicas(md)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.resourceName(md.Name),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: r.scaleTargetRef(md),
+			MinReplicas:    &min,
+			MaxReplicas:    md.Spec.Autoscaling.MaxReplicas + warmPoolReplicas(md),
+			Metrics:        md.Spec.Autoscaling.Metrics,
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, hpa, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return hpa, nil
+}
+
+// scaleTargetRef returns the workload running the model's replicas
+func (r *ModelDeploymentReconciler) scaleTargetRef(md *kaimeraaiv1.ModelDeployment) autoscalingv2.CrossVersionObjectReference {
+	switch md.Spec.WorkloadType {
+	case kaimeraaiv1.WorkloadTypeRollout:
+		return autoscalingv2.CrossVersionObjectReference{
+			APIVersion: rolloutGVK.GroupVersion().String(),
+			Kind:       rolloutGVK.Kind,
+			Name:       r.resourceName(md.Name),
+		}
+	case kaimeraaiv1.WorkloadTypeStatefulSet:
+		return autoscalingv2.CrossVersionObjectReference{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "StatefulSet",
+			Name:       r.resourceName(md.Name),
+		}
+	}
+
+	return autoscalingv2.CrossVersionObjectReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       "Deployment",
+		Name:       r.resourceName(md.Name),
+	}
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment autoscaling", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		minReplicas := int32(2)
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "autoscaled",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Autoscaling: &kaimeraaiv1.AutoscalingSpec{
+					MinReplicas: &minReplicas,
+					MaxReplicas: 8,
+				},
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should not fight the autoscaler over the replica count", func() {
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		Expect(reconciler.Get(ctx, key, hpa)).To(Succeed())
+		Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(md.Name))
+		Expect(*hpa.Spec.MinReplicas).To(BeEquivalentTo(2))
+		Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(8))
+		Expect(metav1.IsControlledBy(hpa, md)).To(BeTrue())
+
+		deploy := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, deploy)).To(Succeed())
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(2))
+
+		By("scaling the deployment as the autoscaler would")
+		scaled := int32(5)
+		deploy.Spec.Replicas = &scaled
+		Expect(reconciler.Update(ctx, deploy)).To(Succeed())
+
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, deploy)).To(Succeed())
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(5))
+	})
+
+	It("should remove the autoscaler and restore the replicas once disabled", func() {
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.Autoscaling = nil
+		md.Spec.Replicas = 3
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{}))).To(BeTrue())
+
+		deploy := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, deploy)).To(Succeed())
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(3))
+	})
+})
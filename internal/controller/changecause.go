@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	// changeCauseAnnotation is copied to each ReplicaSet and shown by
+	// kubectl rollout history
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+
+	// modelNameAnnotation records the model a Deployment serves, so a model
+	// change can be told apart from other changes to the arguments
+	modelNameAnnotation = "kaimera.ai/model-name"
+)
+
+// setChangeCause annotates deploy with a human readable description of how it
+// differs from current, the existing Deployment or nil if there is none.
+func setChangeCause(deploy, current *appsv1.Deployment, md *kaimeraaiv1.ModelDeployment) {
+	annotations := map[string]string{}
+	for k, v := range deploy.Annotations {
+		annotations[k] = v
+	}
+	annotations[modelNameAnnotation] = md.Spec.ModelName
+	annotations[changeCauseAnnotation] = changeCause(deploy, current, md)
+	deploy.Annotations = annotations
+}
+
+func changeCause(deploy, current *appsv1.Deployment, md *kaimeraaiv1.ModelDeployment) string {
+	if current == nil || len(current.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Sprintf("model %s deployed", md.Spec.ModelName)
+	}
+
+	container := deploy.Spec.Template.Spec.Containers[0]
+	currentContainer := current.Spec.Template.Spec.Containers[0]
+	switch {
+	case current.Annotations[modelNameAnnotation] != md.Spec.ModelName:
+		return fmt.Sprintf("model updated to %s", md.Spec.ModelName)
+	case currentContainer.Image != container.Image:
+		return fmt.Sprintf("image updated to %s", container.Image)
+	case !equality.Semantic.DeepEqual(currentContainer.Command, container.Command):
+		return "runtime arguments updated"
+	case !equality.Semantic.DeepEqual(currentContainer.Env, container.Env):
+		return "environment updated"
+	default:
+		return current.Annotations[changeCauseAnnotation]
+	}
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment change cause", func() {
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		reconciler = &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cause",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:   "facebook/opt-125m",
+				Annotations: map[string]string{"team": "ml"},
+			},
+		}
+	})
+
+	It("should describe the change that triggered each rollout", func() {
+		first, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		setChangeCause(first, nil, md)
+		Expect(first.Annotations).To(HaveKeyWithValue(changeCauseAnnotation, "model facebook/opt-125m deployed"))
+		Expect(first.Annotations).To(HaveKeyWithValue("team", "ml"))
+		Expect(md.Spec.Annotations).NotTo(HaveKey(changeCauseAnnotation))
+
+		By("naming a new model")
+		md.Spec.ModelName = "facebook/opt-350m"
+		second, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		setChangeCause(second, first, md)
+		Expect(second.Annotations).To(HaveKeyWithValue(changeCauseAnnotation, "model updated to facebook/opt-350m"))
+
+		By("naming a new image")
+		md.Spec.ImageDigest = "sha256:" + strings.Repeat("a", 64)
+		third, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		setChangeCause(third, second, md)
+		Expect(third.Annotations).To(HaveKeyWithValue(changeCauseAnnotation,
+			"image updated to patnaikshekhar/vllm-cpu@sha256:"+strings.Repeat("a", 64)))
+
+		By("keeping the cause when nothing relevant changed")
+		md.Spec.Replicas = 3
+		fourth, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		setChangeCause(fourth, third, md)
+		Expect(fourth.Annotations[changeCauseAnnotation]).To(Equal(third.Annotations[changeCauseAnnotation]))
+	})
+})
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	chatTemplateVolume    = "chat-template"
+	chatTemplateMountPath = "/etc/kaimera/chat-template"
+	chatTemplateKey       = "chat_template.jinja"
+)
+
+// reconcileChatTemplate keeps the ConfigMap holding the inline chat template
+// in sync with the spec, and removes it once the template is cleared.
+func (r *ModelDeploymentReconciler) reconcileChatTemplate(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.ChatTemplate == "" {
+		cm := corev1.ConfigMap{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.chatTemplateConfigMapName(md)}, &cm)
+		if err != nil || !metav1.IsControlledBy(&cm, md) {
+			return client.IgnoreNotFound(err)
+		}
+
+		log.FromContext(ctx).Info("deleting chat template configmap")
+		return client.IgnoreNotFound(r.Delete(ctx, &cm))
+	}
+
+	cm, err := r.generateChatTemplateConfigMap(md)
+	if err != nil {
+		return err
+	}
+
+	return r.applyConfigMap(ctx, cm)
+}
+
+// applyConfigMap creates the ConfigMap or brings the data of an existing one
+// in line with it
+func (r *ModelDeploymentReconciler) applyConfigMap(ctx context.Context, cm *corev1.ConfigMap) error {
+	existing := corev1.ConfigMap{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(cm), &existing)
+	if errors.IsNotFound(err) {
+		log.FromContext(ctx).Info("creating configmap", "configmap", cm.Name)
+		return r.Create(ctx, cm)
+	}
+	if err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(existing.Data, cm.Data) &&
+		equality.Semantic.DeepEqual(existing.Labels, cm.Labels) &&
+		equality.Semantic.DeepEqual(existing.Annotations, cm.Annotations) {
+		return nil
+	}
+
+	existing.Data = cm.Data
+	existing.Labels = cm.Labels
+	existing.Annotations = cm.Annotations
+	return r.Update(ctx, &existing)
+}
+
+func (r *ModelDeploymentReconciler) chatTemplateConfigMapName(md *kaimeraaiv1.ModelDeployment) string {
+	return r.resourceName(md.Name + "-chat-template")
+}
+
+// chatTemplate returns the chat template of the spec, prefixed with a
+// statement adding the system prompt to conversations without one
+func chatTemplate(md *kaimeraaiv1.ModelDeployment) string {
+	if md.Spec.SystemPrompt == "" {
+		return md.Spec.ChatTemplate
+	}
+
+	// A JSON string is also a valid Jinja string literal
+	var prompt strings.Builder
+	encoder := json.NewEncoder(&prompt)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(md.Spec.SystemPrompt)
+
+	return fmt.Sprintf("{%%- if not messages or messages[0]['role'] != 'system' %%}"+
+		"{%%- set messages = [{'role': 'system', 'content': %s}] + messages %%}"+
+		"{%%- endif %%}\n", strings.TrimSpace(prompt.String())) + md.Spec.ChatTemplate
+}
+
+func (r *ModelDeploymentReconciler) generateChatTemplateConfigMap(md *kaimeraaiv1.ModelDeployment) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.chatTemplateConfigMapName(md),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Data: map[string]string{
+			chatTemplateKey: chatTemplate(md),
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, cm, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm, nil
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment chat template", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "chat",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:    "facebook/opt-125m",
+				ChatTemplate: "{% for message in messages %}{{ message.content }}{% endfor %}",
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should create an owned configmap and mount it", func() {
+		Expect(reconciler.reconcileChatTemplate(ctx, md)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.chatTemplateConfigMapName(md)}
+		Expect(reconciler.Get(ctx, key, cm)).To(Succeed())
+		Expect(cm.Data).To(HaveKeyWithValue(chatTemplateKey, md.Spec.ChatTemplate))
+		Expect(metav1.IsControlledBy(cm, md)).To(BeTrue())
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		podSpec := deploy.Spec.Template.Spec
+		Expect(podSpec.Volumes).To(ContainElement(HaveField("VolumeSource.ConfigMap.Name", cm.Name)))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      chatTemplateVolume,
+			MountPath: chatTemplateMountPath,
+			ReadOnly:  true,
+		}))
+		Expect(podSpec.Containers[0].Command).To(ContainElements("--chat-template", "/etc/kaimera/chat-template/chat_template.jinja"))
+	})
+
+	It("should update the configmap and remove it once cleared", func() {
+		Expect(reconciler.reconcileChatTemplate(ctx, md)).To(Succeed())
+
+		md.Spec.ChatTemplate = "{{ messages[-1].content }}"
+		Expect(reconciler.reconcileChatTemplate(ctx, md)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.chatTemplateConfigMapName(md)}
+		Expect(reconciler.Get(ctx, key, cm)).To(Succeed())
+		Expect(cm.Data).To(HaveKeyWithValue(chatTemplateKey, "{{ messages[-1].content }}"))
+
+		md.Spec.ChatTemplate = ""
+		Expect(reconciler.reconcileChatTemplate(ctx, md)).To(Succeed())
+		err := reconciler.Get(ctx, key, cm)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should inject the system prompt and response role", func() {
+		md.Spec.SystemPrompt = `You are "Kai", a helpful assistant.`
+		md.Spec.ResponseRole = "assistant"
+		Expect(reconciler.reconcileChatTemplate(ctx, md)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.chatTemplateConfigMapName(md)}
+		Expect(reconciler.Get(ctx, key, cm)).To(Succeed())
+		Expect(cm.Data[chatTemplateKey]).To(Equal(
+			"{%- if not messages or messages[0]['role'] != 'system' %}" +
+				`{%- set messages = [{'role': 'system', 'content': "You are \"Kai\", a helpful assistant."}] + messages %}` +
+				"{%- endif %}\n" + md.Spec.ChatTemplate))
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		container := deploy.Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(ContainElements("--response-role", "assistant"))
+		Expect(container.Command).To(ContainElements("--chat-template", "/etc/kaimera/chat-template/chat_template.jinja"))
+		Expect(container.VolumeMounts).To(ContainElement(HaveField("Name", chatTemplateVolume)))
+	})
+})
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	modelDownloadName = "model-download"
+
+	// checksumMismatchExitCode is the exit code of the download init
+	// container when the weights don't match the checksum
+	checksumMismatchExitCode = 3
+
+	modelChecksumMismatchReason = "ModelChecksumMismatch"
+)
+
+// modelChecksumScript downloads the model given as first argument into the
+// Hugging Face cache and compares the checksum of its weights to the second
+// argument, reporting a mismatch through the termination message
+var modelChecksumScript = fmt.Sprintf(`set -e
+snapshot=$(python3 -c 'import sys; from huggingface_hub import snapshot_download; print(snapshot_download(sys.argv[1]))' "$1")
+cd "$snapshot"
+actual="sha256:$(find -L . -type f \( -name '*.safetensors' -o -name '*.bin' \) | LC_ALL=C sort | xargs -r sha256sum | sha256sum | cut -d ' ' -f 1)"
+if [ "$actual" != "$2" ]; then
+  echo "weights of $1 have checksum $actual, expected $2" | tee /dev/termination-log
+  exit %d
+fi`, checksumMismatchExitCode)
+
+// generateModelDownload returns the init container downloading and verifying
+// the model with the runtime's image, environment and cache mounts, so the
+// runtime then loads the verified weights from the cache
+func generateModelDownload(md *kaimeraaiv1.ModelDeployment, image string, env []corev1.EnvVar, volumeMounts []corev1.VolumeMount) corev1.Container {
+	var cacheMounts []corev1.VolumeMount
+	for _, mount := range volumeMounts {
+		if mount.MountPath == modelCacheMountPath {
+			cacheMounts = append(cacheMounts, mount)
+		}
+	}
+
+	return corev1.Container{
+		Name:            modelDownloadName,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/bin/sh", "-c", modelChecksumScript, "sh", md.Spec.ModelName, md.Spec.ModelChecksum},
+		Env:             env,
+		VolumeMounts:    cacheMounts,
+	}
+}
+
+// checkModelChecksum fails with a ModelChecksumMismatch reason when a model
+// pod's download init container found the weights don't match the checksum
+func (r *ModelDeploymentReconciler) checkModelChecksum(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.ModelChecksum == "" {
+		return nil
+	}
+
+	pods := corev1.PodList{}
+	err := r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{"app": md.Name})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.Name != modelDownloadName {
+				continue
+			}
+
+			terminated := status.State.Terminated
+			if terminated == nil {
+				terminated = status.LastTerminationState.Terminated
+			}
+			if terminated == nil || terminated.ExitCode != checksumMismatchExitCode {
+				continue
+			}
+
+			message := fmt.Sprintf("pod %q refused to serve the model: %s", pod.Name, strings.TrimSpace(terminated.Message))
+			r.warningEvent(md, modelChecksumMismatchReason, message)
+			return &degradedError{reason: modelChecksumMismatchReason, message: message}
+		}
+	}
+
+	return nil
+}
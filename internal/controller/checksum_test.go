@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment model checksum", func() {
+	ctx := context.Background()
+	checksum := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "verified",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:     "facebook/opt-125m",
+				ModelChecksum: checksum,
+			},
+		}
+		reconciler = &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+	})
+
+	It("should verify the weights in an init container before serving", func() {
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		initContainers := deploy.Spec.Template.Spec.InitContainers
+		Expect(initContainers).To(HaveLen(1))
+		download := initContainers[0]
+		Expect(download.Name).To(Equal("model-download"))
+		Expect(download.Image).To(Equal(deploy.Spec.Template.Spec.Containers[0].Image))
+		Expect(download.Command).To(Equal([]string{
+			"/bin/sh", "-c", modelChecksumScript, "sh", "facebook/opt-125m", checksum,
+		}))
+		Expect(modelChecksumScript).To(ContainSubstring("snapshot_download"))
+		Expect(modelChecksumScript).To(ContainSubstring("LC_ALL=C sort | xargs -r sha256sum | sha256sum"))
+		Expect(modelChecksumScript).To(ContainSubstring("exit 3"))
+		Expect(download.Env).NotTo(ContainElement(HaveField("Name", "HF_HUB_OFFLINE")))
+
+		By("serving the verified weights offline")
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "HF_HUB_OFFLINE", Value: "1"}))
+
+		By("sharing the model cache with the runtime")
+		cacheMount := corev1.VolumeMount{Name: "model-cache", MountPath: "/root/.cache/huggingface"}
+		Expect(download.VolumeMounts).To(Equal([]corev1.VolumeMount{cacheMount}))
+		Expect(deploy.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(cacheMount))
+		Expect(deploy.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         "model-cache",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		}))
+	})
+
+	It("should report a checksum mismatch in the Degraded condition", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "verified-0",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "verified"},
+			},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name: "model-download",
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOffReason},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 3,
+							Message:  "weights of facebook/opt-125m have checksum sha256:ff, expected " + checksum + "\n",
+						},
+					},
+				}},
+			},
+		}
+		reconciler.Client = fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(md, pod).
+			WithStatusSubresource(md).
+			Build()
+
+		err := reconciler.checkModelChecksum(ctx, md)
+		Expect(err).To(HaveOccurred())
+		Expect(reconciler.reconcileDegraded(ctx, md, err)).To(HaveOccurred())
+
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("ModelChecksumMismatch"))
+		Expect(cond.Message).To(Equal(`pod "verified-0" refused to serve the model: ` +
+			"weights of facebook/opt-125m have checksum sha256:ff, expected " + checksum))
+	})
+})
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// childLabels returns the labels of a generated child resource: the spec's
+// labels, overridden by the ones the controller sets itself
+func childLabels(md *kaimeraaiv1.ModelDeployment, labels map[string]string) map[string]string {
+	return mergeMaps(md.Spec.Labels, labels)
+}
+
+// childAnnotations returns the annotations of a generated child resource:
+// the spec's annotations, overridden by the ones the controller sets itself
+func childAnnotations(md *kaimeraaiv1.ModelDeployment, annotations map[string]string) map[string]string {
+	return mergeMaps(md.Spec.Annotations, annotations)
+}
+
+func mergeMaps(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+
+	return merged
+}
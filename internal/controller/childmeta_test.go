@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment child labels", func() {
+	ctx := context.Background()
+
+	It("should propagate the labels and annotations to every child", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "labels",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:    "facebook/opt-125m",
+				ChatTemplate: "{{ messages }}",
+				Labels:       map[string]string{"team": "search", "app": "ignored"},
+				Annotations:  map[string]string{"owner": "search@example.com"},
+			},
+		}
+
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(md)})
+		Expect(err).NotTo(HaveOccurred())
+
+		children := []struct {
+			name string
+			obj  client.Object
+		}{
+			{md.Name, &appsv1.Deployment{}},
+			{md.Name, &corev1.Service{}},
+			{reconciler.manifestConfigMapName(md), &corev1.ConfigMap{}},
+			{reconciler.chatTemplateConfigMapName(md), &corev1.ConfigMap{}},
+			{reconciler.connectionSecretName(md), &corev1.Secret{}},
+		}
+		for _, child := range children {
+			Expect(reconciler.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: child.name}, child.obj)).To(Succeed())
+			Expect(child.obj.GetLabels()).To(HaveKeyWithValue("team", "search"), child.name)
+			Expect(child.obj.GetAnnotations()).To(HaveKeyWithValue("owner", "search@example.com"), child.name)
+		}
+
+		job, err := reconciler.generateSmokeTestJob(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Labels).To(HaveKeyWithValue("team", "search"))
+		Expect(job.Annotations).To(HaveKeyWithValue("owner", "search@example.com"))
+	})
+
+	It("should not let the spec labels override the controller's", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				Labels: map[string]string{"team": "search", "app": "ignored"},
+			},
+		}
+
+		Expect(childLabels(md, map[string]string{"app": "labels"})).To(Equal(map[string]string{
+			"team": "search",
+			"app":  "labels",
+		}))
+		Expect(childLabels(&kaimeraaiv1.ModelDeployment{}, nil)).To(BeNil())
+	})
+})
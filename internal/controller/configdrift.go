@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// reconcileConfigDrift asks a ready replica which model it serves, records it
+// in the status and reports in the ConfigDrift condition whether it is the
+// spec's model
+func (r *ModelDeploymentReconciler) reconcileConfigDrift(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if !md.Spec.ConfigDriftCheck {
+		removed := meta.RemoveStatusCondition(&md.Status.Conditions, kaimeraaiv1.ConditionConfigDrift)
+		if !removed && md.Status.ServedModel == "" {
+			return nil
+		}
+		md.Status.ServedModel = ""
+		return r.updateStatus(ctx, md)
+	}
+
+	pods := corev1.PodList{}
+	err := r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{"app": md.Name})
+	if err != nil {
+		return err
+	}
+
+	var pod *corev1.Pod
+	for i := range pods.Items {
+		if podReady(&pods.Items[i]) {
+			pod = &pods.Items[i]
+			break
+		}
+	}
+	if pod == nil {
+		return nil
+	}
+
+	apiKey, err := r.apiKey(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	runtime := runtimeAPI{baseURL: runtimeBaseURL(md, pod), apiKey: string(apiKey)}
+	served, err := runtime.servedModel(ctx)
+	if err != nil {
+		return fmt.Errorf("querying the model served by pod %s: %w", pod.Name, err)
+	}
+
+	cond := metav1.Condition{
+		Type:               kaimeraaiv1.ConditionConfigDrift,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ModelMatches",
+		Message:            fmt.Sprintf("pod %s serves %s", pod.Name, served),
+		ObservedGeneration: md.Generation,
+	}
+	if served != md.Spec.ModelName {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "ModelMismatch"
+		cond.Message = fmt.Sprintf("pod %s serves %s instead of %s", pod.Name, served, md.Spec.ModelName)
+	}
+
+	changed := meta.SetStatusCondition(&md.Status.Conditions, cond)
+	if !changed && md.Status.ServedModel == served {
+		return nil
+	}
+	md.Status.ServedModel = served
+	return r.updateStatus(ctx, md)
+}
+
+// servedModel returns the base model the runtime serves, leaving out its
+// LoRA adapters
+func (rt runtimeAPI) servedModel(ctx context.Context) (string, error) {
+	models := struct {
+		Data []servedModel `json:"data"`
+	}{}
+	err := rt.do(ctx, http.MethodGet, modelsPath, nil, &models)
+	if err != nil {
+		return "", err
+	}
+
+	for _, model := range models.Data {
+		if model.Parent == nil {
+			return model.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s lists no base model", modelsPath)
+}
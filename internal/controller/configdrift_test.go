@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment config drift", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+	var runtime *stubRuntime
+
+	BeforeEach(func() {
+		runtime = &stubRuntime{base: "facebook/opt-125m", loaded: map[string]string{"sql": "adapters/sql"}}
+		server := httptest.NewServer(runtime)
+		DeferCleanup(server.Close)
+
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		host, portString, err := net.SplitHostPort(serverURL.Host)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(portString)
+		Expect(err).NotTo(HaveOccurred())
+
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "drift",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:        "facebook/opt-125m",
+				Port:             int32(port),
+				ConfigDriftCheck: true,
+			},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "drift-0",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "drift"},
+			},
+			Status: corev1.PodStatus{
+				PodIP:      host,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, pod).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should record the served model when it matches the spec", func() {
+		Expect(reconciler.reconcileConfigDrift(ctx, md)).To(Succeed())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), md)).To(Succeed())
+		Expect(md.Status.ServedModel).To(Equal("facebook/opt-125m"))
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionConfigDrift)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("ModelMatches"))
+	})
+
+	It("should flag a replica serving another model", func() {
+		runtime.base = "facebook/opt-350m"
+		Expect(reconciler.reconcileConfigDrift(ctx, md)).To(Succeed())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), md)).To(Succeed())
+		Expect(md.Status.ServedModel).To(Equal("facebook/opt-350m"))
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionConfigDrift)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("ModelMismatch"))
+		Expect(cond.Message).To(Equal("pod drift-0 serves facebook/opt-350m instead of facebook/opt-125m"))
+
+		By("clearing the status once the check is turned off")
+		md.Spec.ConfigDriftCheck = false
+		Expect(reconciler.reconcileConfigDrift(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), md)).To(Succeed())
+		Expect(md.Status.ServedModel).To(BeEmpty())
+		Expect(meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionConfigDrift)).To(BeNil())
+	})
+})
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// Keys of the connection Secret. They follow the OpenAI client conventions,
+// so apps using envFrom pick them up without further configuration.
+const (
+	connectionBaseURLKey = "OPENAI_BASE_URL"
+	connectionAPIKeyKey  = "OPENAI_API_KEY"
+	connectionModelKey   = "MODEL_NAME"
+)
+
+// reconcileConnectionSecret keeps a Secret holding everything a client needs
+// to call the model in sync with the spec
+func (r *ModelDeploymentReconciler) reconcileConnectionSecret(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	secret, err := r.generateConnectionSecret(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(ctx, secret, &corev1.Secret{})
+}
+
+func (r *ModelDeploymentReconciler) connectionSecretName(md *kaimeraaiv1.ModelDeployment) string {
+	return r.resourceName(md.Name + "-connection")
+}
+
+func (r *ModelDeploymentReconciler) generateConnectionSecret(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (*corev1.Secret, error) {
+	data := map[string][]byte{
+		connectionBaseURLKey: []byte(r.serviceEndpoint(md) + "/v1"),
+		connectionModelKey:   []byte(md.Spec.ModelName),
+	}
+
+	apiKey, err := r.apiKey(ctx, md)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != nil {
+		data[connectionAPIKeyKey] = apiKey
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.connectionSecretName(md),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+
+	err = ctrl.SetControllerReference(md, secret, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// apiKey returns the API key the runtime requires, or nil if it requires
+// none
+func (r *ModelDeploymentReconciler) apiKey(ctx context.Context, md *kaimeraaiv1.ModelDeployment) ([]byte, error) {
+	ref := md.Spec.APIKeySecretRef
+	if ref == nil {
+		return nil, nil
+	}
+
+	apiKeySecret := corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: ref.Name}, &apiKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, ok := apiKeySecret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("secret %q has no key %q", ref.Name, ref.Key)
+	}
+
+	return apiKey, nil
+}
+
+// modelDeploymentsPerSecret maps a Secret to the ModelDeployments taking
+// their API key from it, so their connection Secrets follow a rotated key
+func (r *ModelDeploymentReconciler) modelDeploymentsPerSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	mds := kaimeraaiv1.ModelDeploymentList{}
+	err := r.List(ctx, &mds, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to list model deployments for secret event")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, md := range mds.Items {
+		if md.Spec.APIKeySecretRef != nil && md.Spec.APIKeySecretRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&md)})
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment connection secret", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "opt",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+			},
+		}
+		apiKey := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "opt-api-key", Namespace: "default"},
+			Data:       map[string][]byte{"key": []byte("s3cr3t")},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md, apiKey).Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should hold the endpoint and model and follow spec changes", func() {
+		Expect(reconciler.reconcileConnectionSecret(ctx, md)).To(Succeed())
+
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.connectionSecretName(md)}
+		Expect(reconciler.Get(ctx, key, secret)).To(Succeed())
+		Expect(metav1.IsControlledBy(secret, md)).To(BeTrue())
+		Expect(secret.Data).To(Equal(map[string][]byte{
+			connectionBaseURLKey: []byte("http://opt.default:80/v1"),
+			connectionModelKey:   []byte("facebook/opt-125m"),
+		}))
+
+		By("adding the api key once one is required")
+		md.Spec.Port = 8080
+		md.Spec.APIKeySecretRef = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "opt-api-key"},
+			Key:                  "key",
+		}
+		Expect(reconciler.reconcileConnectionSecret(ctx, md)).To(Succeed())
+
+		Expect(reconciler.Get(ctx, key, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue(connectionBaseURLKey, []byte("http://opt.default:8080/v1")))
+		Expect(secret.Data).To(HaveKeyWithValue(connectionAPIKeyKey, []byte("s3cr3t")))
+
+		By("following a rotated api key")
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		apiKey := &corev1.Secret{}
+		Expect(reconciler.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: "opt-api-key"}, apiKey)).To(Succeed())
+		Expect(reconciler.modelDeploymentsPerSecret(ctx, apiKey)).To(ConsistOf(
+			reconcile.Request{NamespacedName: client.ObjectKeyFromObject(md)},
+		))
+		Expect(reconciler.modelDeploymentsPerSecret(ctx, secret)).To(BeEmpty())
+		apiKey.Data["key"] = []byte("r0t4t3d")
+		Expect(reconciler.Update(ctx, apiKey)).To(Succeed())
+		Expect(reconciler.reconcileConnectionSecret(ctx, md)).To(Succeed())
+
+		Expect(reconciler.Get(ctx, key, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue(connectionAPIKeyKey, []byte("r0t4t3d")))
+	})
+
+	It("should fail on a missing api key", func() {
+		md.Spec.APIKeySecretRef = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "opt-api-key"},
+			Key:                  "missing",
+		}
+
+		Expect(reconciler.reconcileConnectionSecret(ctx, md)).To(MatchError(ContainSubstring(`no key "missing"`)))
+	})
+})
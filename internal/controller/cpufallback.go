@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	defaultCPUFallbackAfter = 10 * time.Minute
+
+	// cpuFallbackPollInterval is how often pods are checked while the gpu
+	// replicas come up, as the controller doesn't watch pods
+	cpuFallbackPollInterval = 30 * time.Second
+)
+
+// reconcileCPUFallback serves the model from the cpu runtime once a gpu pod
+// has been unschedulable for the configured time while too few replicas are
+// ready, and goes back to the gpu runtime once enough of its replicas are
+// ready again. current is the gpu runtime's Deployment, or nil if there is
+// none yet. It returns when to check the pods again while the gpu replicas
+// are not ready.
+func (r *ModelDeploymentReconciler) reconcileCPUFallback(ctx context.Context, md *kaimeraaiv1.ModelDeployment, current *appsv1.Deployment) (time.Duration, error) {
+	if md.Spec.CPUFallback == nil || md.Spec.Runtime != "gpu" {
+		err := r.deleteCPUFallback(ctx, md)
+		if err != nil {
+			return 0, err
+		}
+		if !meta.RemoveStatusCondition(&md.Status.Conditions, kaimeraaiv1.ConditionCPUFallback) {
+			return 0, nil
+		}
+		return 0, r.updateStatus(ctx, md)
+	}
+
+	if current != nil && current.Status.ReadyReplicas >= minReadyReplicas(md) {
+		if cpuFallbackActive(md) {
+			log.FromContext(ctx).Info("gpu replicas are ready, removing the cpu fallback")
+		}
+		err := r.setCPUFallbackCondition(ctx, md, metav1.ConditionFalse, "GPUReplicasReady",
+			fmt.Sprintf("%d gpu replicas are ready", current.Status.ReadyReplicas))
+		if err != nil {
+			return 0, err
+		}
+		return 0, r.deleteCPUFallback(ctx, md)
+	}
+
+	if !cpuFallbackActive(md) {
+		since, err := r.unschedulableSince(ctx, md)
+		if err != nil {
+			return 0, err
+		}
+		if since == nil {
+			return cpuFallbackPollInterval, nil
+		}
+
+		after := defaultCPUFallbackAfter
+		if md.Spec.CPUFallback.After.Duration > 0 {
+			after = md.Spec.CPUFallback.After.Duration
+		}
+		wait := after - r.now().Sub(since.Time)
+		if wait > 0 {
+			return wait, nil
+		}
+
+		message := fmt.Sprintf("gpu pods have been unschedulable since %s, serving from the cpu runtime", since.UTC().Format(time.RFC3339))
+		log.FromContext(ctx).Info("deploying the cpu fallback", "unschedulableSince", since)
+		r.warningEvent(md, "GPUUnschedulable", message)
+		err = r.setCPUFallbackCondition(ctx, md, metav1.ConditionTrue, "GPUUnschedulable", message)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	deploy, err := r.generateCPUFallbackDeployment(md)
+	if err != nil {
+		return 0, err
+	}
+	err = r.checkImageRegistries(deploy)
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, r.apply(ctx, deploy, &appsv1.Deployment{})
+}
+
+// cpuFallbackActive reports whether the cpu fallback is serving the model
+func cpuFallbackActive(md *kaimeraaiv1.ModelDeployment) bool {
+	return md.Spec.CPUFallback != nil &&
+		meta.IsStatusConditionTrue(md.Status.Conditions, kaimeraaiv1.ConditionCPUFallback)
+}
+
+// servingApp returns the app label of the pods the Service routes to
+func servingApp(md *kaimeraaiv1.ModelDeployment) string {
+	if cpuFallbackActive(md) {
+		return md.CPUFallbackName()
+	}
+
+	return md.Name
+}
+
+func (r *ModelDeploymentReconciler) setCPUFallbackCondition(ctx context.Context, md *kaimeraaiv1.ModelDeployment, status metav1.ConditionStatus, reason, message string) error {
+	changed := meta.SetStatusCondition(&md.Status.Conditions, metav1.Condition{
+		Type:               kaimeraaiv1.ConditionCPUFallback,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: md.Generation,
+	})
+	if !changed {
+		return nil
+	}
+
+	return r.updateStatus(ctx, md)
+}
+
+// unschedulableSince returns when the first of the model's pods that are
+// still unschedulable became so, or nil if none is
+func (r *ModelDeploymentReconciler) unschedulableSince(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (*metav1.Time, error) {
+	pods := corev1.PodList{}
+	err := r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{"app": md.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	var since *metav1.Time
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type != corev1.PodScheduled || cond.Status != corev1.ConditionFalse ||
+				cond.Reason != corev1.PodReasonUnschedulable {
+				continue
+			}
+			if since == nil || cond.LastTransitionTime.Before(since) {
+				transition := cond.LastTransitionTime
+				since = &transition
+			}
+		}
+	}
+
+	return since, nil
+}
+
+func (r *ModelDeploymentReconciler) deleteCPUFallback(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	deploy := appsv1.Deployment{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.CPUFallbackName())}, &deploy)
+	if err != nil || !metav1.IsControlledBy(&deploy, md) {
+		return client.IgnoreNotFound(err)
+	}
+
+	log.FromContext(ctx).Info("deleting cpu fallback deployment")
+	return client.IgnoreNotFound(r.Delete(ctx, &deploy))
+}
+
+// cpuFallbackModelDeployment returns the ModelDeployment the fallback is
+// generated from: the primary on the cpu runtime and the fallback's nodes,
+// without the features that need GPUs or only apply to the primary
+func cpuFallbackModelDeployment(md *kaimeraaiv1.ModelDeployment) *kaimeraaiv1.ModelDeployment {
+	fallback := md.DeepCopy()
+	fallback.Name = md.CPUFallbackName()
+	fallback.Spec.Runtime = "cpu"
+	if md.Spec.CPUFallback.ModelName != "" {
+		fallback.Spec.ModelName = md.Spec.CPUFallback.ModelName
+		fallback.Spec.ModelChecksum = ""
+	}
+	fallback.Spec.Replicas = 1
+	if md.Spec.CPUFallback.Replicas > 0 {
+		fallback.Spec.Replicas = md.Spec.CPUFallback.Replicas
+	}
+	fallback.Spec.NodeSelectorLabels = md.Spec.CPUFallback.NodeSelector
+	fallback.Spec.NodePool = ""
+	fallback.Spec.Karpenter = nil
+	fallback.Spec.GPUProduct = ""
+	fallback.Spec.GPUProductLabel = ""
+	fallback.Spec.GPUTopologyAware = false
+	fallback.Spec.MinCUDAVersion = ""
+	fallback.Spec.ReplicasPerNode = 0
+	fallback.Spec.RampUp = false
+	fallback.Spec.Autoscaling = nil
+	fallback.Spec.Rightsizing = nil
+	fallback.Spec.ExclusiveNode = false
+	fallback.Spec.AutoTensorParallel = false
+	fallback.Spec.TensorParallelSize = 0
+	fallback.Spec.PipelineParallelSize = 0
+	fallback.Spec.GPUMemoryUtilization = ""
+	fallback.Spec.CUDAVisibleDevices = ""
+	fallback.Spec.GPUTuning = nil
+	fallback.Spec.MPS = nil
+	fallback.Spec.ResourceClaims = nil
+	fallback.Spec.Hostname = ""
+	fallback.Spec.PrefixCache = nil
+	fallback.Spec.Shadow = nil
+	fallback.Spec.CPUFallback = nil
+
+	return fallback
+}
+
+func (r *ModelDeploymentReconciler) generateCPUFallbackDeployment(md *kaimeraaiv1.ModelDeployment) (*appsv1.Deployment, error) {
+	// Clients keep asking for the primary's model, so the fallback answers
+	// to it even when it serves a smaller one
+	return r.generateAliasDeployment(md, cpuFallbackModelDeployment(md))
+}
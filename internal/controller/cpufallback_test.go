@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment CPU fallback", func() {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+	var pod *corev1.Pod
+	var gpuDeployment *appsv1.Deployment
+
+	fallbackKey := client.ObjectKey{Namespace: "default", Name: "fallback-cpu-fallback"}
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fallback",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "meta-llama/Llama-3.1-8B-Instruct",
+				Runtime:   "gpu",
+				CPUFallback: &kaimeraaiv1.CPUFallbackSpec{
+					After:     metav1.Duration{Duration: 5 * time.Minute},
+					ModelName: "facebook/opt-125m",
+				},
+			},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fallback-0",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "fallback"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             corev1.PodReasonUnschedulable,
+					LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Minute)),
+				}},
+			},
+		}
+		gpuDeployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: "default"},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, pod).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+			Clock:  clocktesting.NewFakePassiveClock(now),
+		}
+	})
+
+	It("should fall back to the cpu runtime once the gpu pods stay unschedulable", func() {
+		wait, err := reconciler.reconcileCPUFallback(ctx, md, gpuDeployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(3 * time.Minute))
+		Expect(errors.IsNotFound(reconciler.Get(ctx, fallbackKey, &appsv1.Deployment{}))).To(BeTrue())
+
+		By("waiting out the configured time")
+		reconciler.Clock = clocktesting.NewFakePassiveClock(now.Add(3 * time.Minute))
+		wait, err = reconciler.reconcileCPUFallback(ctx, md, gpuDeployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionCPUFallback)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("GPUUnschedulable"))
+
+		fallback := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, fallbackKey, fallback)).To(Succeed())
+		Expect(metav1.IsControlledBy(fallback, md)).To(BeTrue())
+		container := fallback.Spec.Template.Spec.Containers[0]
+		Expect(container.Image).To(Equal("patnaikshekhar/vllm-cpu:1"))
+		Expect(container.Command).To(ContainElement("facebook/opt-125m"))
+		Expect(container.Command).To(ContainElements("--served-model-name", "meta-llama/Llama-3.1-8B-Instruct"))
+		Expect(container.Resources.Limits).To(BeEmpty())
+
+		svc, err := reconciler.generateService(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{"app": "fallback-cpu-fallback"}))
+	})
+
+	It("should schedule the fallback on its own nodes", func() {
+		md.Spec.NodeSelectorLabels = map[string]string{"accelerator": "h100"}
+		md.Spec.NodePool = "gpu"
+		md.Spec.GPUProduct = "NVIDIA-H100-80GB-HBM3"
+		md.Spec.Karpenter = &kaimeraaiv1.KarpenterSpec{InstanceFamilies: []string{"p5"}}
+		md.Spec.CPUFallback.NodeSelector = map[string]string{"pool": "cpu"}
+		reconciler.NodePoolTaint = "pool.example.com/name"
+
+		fallback, err := reconciler.generateCPUFallbackDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		podSpec := fallback.Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(map[string]string{"pool": "cpu"}))
+		Expect(podSpec.Affinity).To(BeNil())
+		Expect(podSpec.Tolerations).To(BeEmpty())
+	})
+
+	It("should go back to the gpu runtime once its replicas are ready", func() {
+		reconciler.Clock = clocktesting.NewFakePassiveClock(now.Add(time.Hour))
+		_, err := reconciler.reconcileCPUFallback(ctx, md, gpuDeployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cpuFallbackActive(md)).To(BeTrue())
+
+		By("staying on the cpu runtime while the gpu pods start")
+		Expect(reconciler.Delete(ctx, pod)).To(Succeed())
+		_, err = reconciler.reconcileCPUFallback(ctx, md, gpuDeployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cpuFallbackActive(md)).To(BeTrue())
+		Expect(reconciler.Get(ctx, fallbackKey, &appsv1.Deployment{})).To(Succeed())
+
+		By("reverting once enough gpu replicas are ready")
+		gpuDeployment.Status.ReadyReplicas = 1
+		_, err = reconciler.reconcileCPUFallback(ctx, md, gpuDeployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionCPUFallback)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("GPUReplicasReady"))
+		Expect(errors.IsNotFound(reconciler.Get(ctx, fallbackKey, &appsv1.Deployment{}))).To(BeTrue())
+
+		svc, err := reconciler.generateService(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{"app": "fallback"}))
+	})
+})
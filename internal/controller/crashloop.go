@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const crashLoopBackOffReason = "CrashLoopBackOff"
+
+// checkCrashLoopBackOff fails with a CrashLoopBackOff reason when a model pod
+// keeps crashing, e.g. on bad arguments or when it runs out of memory, and
+// emits a Warning event with how its container last terminated once it
+// starts to
+func (r *ModelDeploymentReconciler) checkCrashLoopBackOff(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	pods := corev1.PodList{}
+	err := r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{"app": md.Name})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if status.State.Waiting == nil || status.State.Waiting.Reason != crashLoopBackOffReason {
+				continue
+			}
+
+			message := crashLoopMessage(&pod, &status)
+			if !degradedWith(md, crashLoopBackOffReason, message) {
+				r.warningEvent(md, crashLoopBackOffReason, message)
+			}
+			return &degradedError{reason: crashLoopBackOffReason, message: message}
+		}
+	}
+
+	return nil
+}
+
+// crashLoopMessage describes a crash looping container by how it last
+// terminated
+func crashLoopMessage(pod *corev1.Pod, status *corev1.ContainerStatus) string {
+	message := fmt.Sprintf("container %q of pod %q is in CrashLoopBackOff", status.Name, pod.Name)
+
+	terminated := status.LastTerminationState.Terminated
+	if terminated == nil {
+		return message
+	}
+
+	message += fmt.Sprintf(", last terminated with exit code %d", terminated.ExitCode)
+	if terminated.Reason != "" {
+		message += fmt.Sprintf(" (%s)", terminated.Reason)
+	}
+	if text := strings.TrimSpace(terminated.Message); text != "" {
+		message += ": " + text
+	}
+
+	return message
+}
+
+// modelDeploymentPerPod maps a model pod to its ModelDeployment, so a pod
+// that starts to crash loop or can't pull its image is reported when it
+// happens rather than on the next resync
+func (r *ModelDeploymentReconciler) modelDeploymentPerPod(_ context.Context, obj client.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[modelDeploymentLabel]
+	if !ok {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
+
+// podWaitingPredicate passes events of model pods that come and go, or
+// whose containers start or stop waiting, or wait for another reason, e.g.
+// going from ContainerCreating to CrashLoopBackOff
+func podWaitingPredicate() predicate.Predicate {
+	isModelPod := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, ok := obj.GetLabels()[modelDeploymentLabel]
+		return ok
+	})
+	waitingChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+
+			return !equality.Semantic.DeepEqual(waitingReasons(oldPod), waitingReasons(newPod))
+		},
+	}
+
+	return predicate.And(isModelPod, waitingChanged)
+}
+
+// waitingReasons returns why each waiting container of pod waits
+func waitingReasons(pod *corev1.Pod) map[string]string {
+	reasons := map[string]string{}
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil {
+			reasons[status.Name] = status.State.Waiting.Reason
+		}
+	}
+
+	return reasons
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment CrashLoopBackOff", func() {
+	ctx := context.Background()
+
+	It("should report a crash looping pod in the Degraded condition", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "crash",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+			},
+		}
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "crash-7d9f8-abcde",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "crash"},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name: "model-server",
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+						},
+						LastTerminationState: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{
+								ExitCode: 137,
+								Reason:   "OOMKilled",
+							},
+						},
+					},
+				},
+			},
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, pod).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+		}
+
+		By("creating the deployment without checking pods")
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("reporting the crash loop once the deployment exists")
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("CrashLoopBackOff"))
+		Expect(cond.Message).To(ContainSubstring("exit code 137 (OOMKilled)"))
+
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning CrashLoopBackOff")))
+
+		By("not emitting the event again while the pod keeps crash looping")
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should reconcile the ModelDeployment of a pod starting to crash loop", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "crash-7d9f8-abcde",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "crash", modelDeploymentLabel: "crash"},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "vllm",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+					},
+				},
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{}
+		Expect(reconciler.modelDeploymentPerPod(ctx, pod)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "crash"}}))
+
+		pred := podWaitingPredicate()
+		crashing := pod.DeepCopy()
+		crashing.Status.ContainerStatuses[0].State.Waiting.Reason = crashLoopBackOffReason
+		Expect(pred.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: crashing})).To(BeTrue())
+
+		By("ignoring other changes of its status")
+		restarted := crashing.DeepCopy()
+		restarted.Status.ContainerStatuses[0].RestartCount = 3
+		Expect(pred.Update(event.UpdateEvent{ObjectOld: crashing, ObjectNew: restarted})).To(BeFalse())
+
+		By("ignoring pods of other workloads")
+		other := pod.DeepCopy()
+		other.Labels = map[string]string{"app": "crash"}
+		Expect(reconciler.modelDeploymentPerPod(ctx, other)).To(BeEmpty())
+		Expect(pred.Create(event.CreateEvent{Object: other})).To(BeFalse())
+	})
+
+	It("should include the last termination message", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "crash-abcde"}}
+		status := &corev1.ContainerStatus{
+			Name: "model-server",
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Reason:   "Error",
+					Message:  "unrecognized arguments: --bad-flag\n",
+				},
+			},
+		}
+
+		Expect(crashLoopMessage(pod, status)).To(Equal(`container "model-server" of pod "crash-abcde" is in CrashLoopBackOff, ` +
+			"last terminated with exit code 1 (Error): unrecognized arguments: --bad-flag"))
+	})
+})
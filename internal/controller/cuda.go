@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// Labels GPU feature discovery sets to the highest CUDA version the node's
+// driver supports
+const (
+	cudaMajorLabel = "nvidia.com/cuda.runtime.major"
+	cudaMinorLabel = "nvidia.com/cuda.runtime.minor"
+)
+
+// minCUDAVersionAlternatives returns the node requirements of which one must
+// hold for a node's driver to support at least the spec's minimum CUDA
+// version: a higher major version, or the same one with a high enough minor
+// version. It returns nil when no minimum is set.
+func minCUDAVersionAlternatives(md *kaimeraaiv1.ModelDeployment) [][]corev1.NodeSelectorRequirement {
+	if md.Spec.Runtime != "gpu" || md.Spec.MinCUDAVersion == "" {
+		return nil
+	}
+
+	majorString, minorString, _ := strings.Cut(md.Spec.MinCUDAVersion, ".")
+	major, err := strconv.Atoi(majorString)
+	if err != nil {
+		return nil
+	}
+	minor, err := strconv.Atoi(minorString)
+	if err != nil {
+		return nil
+	}
+
+	// Node selectors only compare integers with Gt, so >= becomes > n-1
+	atLeastMajor := func(n int) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{
+			Key:      cudaMajorLabel,
+			Operator: corev1.NodeSelectorOpGt,
+			Values:   []string{strconv.Itoa(n - 1)},
+		}
+	}
+	if minor == 0 {
+		return [][]corev1.NodeSelectorRequirement{{atLeastMajor(major)}}
+	}
+
+	return [][]corev1.NodeSelectorRequirement{
+		{atLeastMajor(major + 1)},
+		{
+			{Key: cudaMajorLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{strconv.Itoa(major)}},
+			{Key: cudaMinorLabel, Operator: corev1.NodeSelectorOpGt, Values: []string{strconv.Itoa(minor - 1)}},
+		},
+	}
+}
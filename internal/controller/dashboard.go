@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// The Grafana sidecar provisions the dashboards in ConfigMaps carrying this
+// label by default
+const (
+	dashboardLabel      = "grafana_dashboard"
+	dashboardLabelValue = "1"
+)
+
+// dashboardPanel is a time series panel of the dashboard and the PromQL
+// expression it plots. The expression is a format string taking the
+// selector of the model's series.
+type dashboardPanel struct {
+	title string
+	unit  string
+	expr  string
+}
+
+var dashboardPanels = []dashboardPanel{
+	{"Running requests", "short", "sum(vllm:num_requests_running{%s})"},
+	{"Waiting requests", "short", "sum(vllm:num_requests_waiting{%s})"},
+	{"Generation throughput", "tokens/s", "sum(rate(vllm:generation_tokens_total{%s}[1m]))"},
+	{"Prompt throughput", "tokens/s", "sum(rate(vllm:prompt_tokens_total{%s}[1m]))"},
+	{"Time to first token p95", "s", "histogram_quantile(0.95, sum by (le) (rate(vllm:time_to_first_token_seconds_bucket{%s}[5m])))"},
+	{"End to end latency p95", "s", "histogram_quantile(0.95, sum by (le) (rate(vllm:e2e_request_latency_seconds_bucket{%s}[5m])))"},
+	{"KV cache usage", "percentunit", "avg(vllm:gpu_cache_usage_perc{%s})"},
+}
+
+// reconcileDashboard keeps the Grafana dashboard ConfigMap in sync with the
+// spec, and removes it once the dashboard is no longer wanted
+func (r *ModelDeploymentReconciler) reconcileDashboard(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.Metrics == nil || !md.Spec.Metrics.Dashboard {
+		cm := corev1.ConfigMap{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.dashboardConfigMapName(md)}, &cm)
+		if err != nil || !metav1.IsControlledBy(&cm, md) {
+			return client.IgnoreNotFound(err)
+		}
+
+		log.FromContext(ctx).Info("deleting dashboard configmap")
+		return client.IgnoreNotFound(r.Delete(ctx, &cm))
+	}
+
+	cm, err := r.generateDashboardConfigMap(md)
+	if err != nil {
+		return err
+	}
+
+	return r.applyConfigMap(ctx, cm)
+}
+
+func (r *ModelDeploymentReconciler) dashboardConfigMapName(md *kaimeraaiv1.ModelDeployment) string {
+	return r.resourceName(md.Name + "-dashboard")
+}
+
+// generateDashboard returns the Grafana dashboard JSON of the model's vLLM
+// metrics
+func generateDashboard(md *kaimeraaiv1.ModelDeployment) ([]byte, error) {
+	selector := fmt.Sprintf("namespace=%q, model_name=%q", md.Namespace, md.Spec.ModelName)
+
+	var panels []map[string]interface{}
+	for i, panel := range dashboardPanels {
+		panels = append(panels, map[string]interface{}{
+			"id":         i + 1,
+			"type":       "timeseries",
+			"title":      panel.title,
+			"datasource": map[string]string{"type": "prometheus", "uid": "${datasource}"},
+			"gridPos":    map[string]int{"h": 8, "w": 12, "x": 12 * (i % 2), "y": 8 * (i / 2)},
+			"fieldConfig": map[string]interface{}{
+				"defaults": map[string]string{"unit": panel.unit},
+			},
+			"targets": []map[string]string{
+				{"refId": "A", "expr": fmt.Sprintf(panel.expr, selector)},
+			},
+		})
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"uid":           string(md.UID),
+		"title":         fmt.Sprintf("%s/%s", md.Namespace, md.Name),
+		"tags":          []string{"kaimera", "vllm"},
+		"schemaVersion": 39,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-1h", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []map[string]string{
+				{"name": "datasource", "type": "datasource", "query": "prometheus"},
+			},
+		},
+		"panels": panels,
+	}, "", "  ")
+}
+
+func (r *ModelDeploymentReconciler) generateDashboardConfigMap(md *kaimeraaiv1.ModelDeployment) (*corev1.ConfigMap, error) {
+	dashboard, err := generateDashboard(md)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.dashboardConfigMapName(md),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, map[string]string{dashboardLabel: dashboardLabelValue}),
+			Annotations: childAnnotations(md, nil),
+		},
+		Data: map[string]string{
+			fmt.Sprintf("%s-%s.json", md.Namespace, md.Name): string(dashboard),
+		},
+	}
+
+	err = ctrl.SetControllerReference(md, cm, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm, nil
+}
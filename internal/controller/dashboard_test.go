@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment Grafana dashboard", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dashboard",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Metrics:   &kaimeraaiv1.MetricsSpec{Dashboard: true},
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should create a dashboard for the Grafana sidecar", func() {
+		Expect(reconciler.reconcileDashboard(ctx, md)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.dashboardConfigMapName(md)}
+		Expect(reconciler.Get(ctx, key, cm)).To(Succeed())
+		Expect(metav1.IsControlledBy(cm, md)).To(BeTrue())
+		Expect(cm.Labels).To(HaveKeyWithValue("grafana_dashboard", "1"))
+		Expect(cm.Data).To(HaveKey("default-dashboard.json"))
+
+		var dashboard struct {
+			Panels []struct {
+				Targets []struct {
+					Expr string `json:"expr"`
+				} `json:"targets"`
+			} `json:"panels"`
+		}
+		Expect(json.Unmarshal([]byte(cm.Data["default-dashboard.json"]), &dashboard)).To(Succeed())
+		Expect(dashboard.Panels).To(HaveLen(len(dashboardPanels)))
+		Expect(dashboard.Panels[0].Targets[0].Expr).To(Equal(
+			`sum(vllm:num_requests_running{namespace="default", model_name="facebook/opt-125m"})`))
+
+		By("removing it once the dashboard is turned off")
+		md.Spec.Metrics.Dashboard = false
+		Expect(reconciler.reconcileDashboard(ctx, md)).To(Succeed())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, cm))).To(BeTrue())
+	})
+})
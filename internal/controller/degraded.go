@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// degradedError is a reconcile failure with a known cause, reported as the
+// reason of the Degraded condition
+type degradedError struct {
+	reason  string
+	message string
+}
+
+func (e *degradedError) Error() string {
+	return e.message
+}
+
+// degradedWith returns whether md is already degraded for reason with
+// message, so a Warning event on the cause isn't emitted on every reconcile
+func degradedWith(md *kaimeraaiv1.ModelDeployment, reason, message string) bool {
+	cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == reason && cond.Message == message
+}
+
+// reconcileDegraded records the outcome of reconciling the children in the
+// Degraded condition, along with the generation they were reconciled to on
+// success, and passes the error on, so a failure is retried with the
+// controller's backoff while its cause stays visible in status.
+func (r *ModelDeploymentReconciler) reconcileDegraded(ctx context.Context, md *kaimeraaiv1.ModelDeployment, reconcileErr error) error {
+	cond := metav1.Condition{
+		Type:               kaimeraaiv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Reconciled",
+		Message:            "deployment and service are up to date",
+		ObservedGeneration: md.Generation,
+	}
+	if reconcileErr != nil {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "ReconcileFailed"
+		cond.Message = reconcileErr.Error()
+
+		var degradedErr *degradedError
+		if errors.As(reconcileErr, &degradedErr) {
+			cond.Reason = degradedErr.reason
+		}
+	}
+
+	failed := cond.Status == metav1.ConditionTrue &&
+		!meta.IsStatusConditionTrue(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+	changed := meta.SetStatusCondition(&md.Status.Conditions, cond)
+	// A generation waiting for the maintenance window isn't rolled out yet
+	if reconcileErr == nil && md.Status.ObservedGeneration != md.Generation && !rolloutDeferred(md) {
+		md.Status.ObservedGeneration = md.Generation
+		changed = true
+	}
+	if changed {
+		err := r.updateStatus(ctx, md)
+		if err != nil && reconcileErr == nil {
+			return err
+		}
+		if err == nil && failed {
+			r.notify(ctx, md, notificationFailed, cond.Reason, cond.Message)
+		}
+	}
+
+	return reconcileErr
+}
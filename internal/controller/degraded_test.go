@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment degraded condition", func() {
+	ctx := context.Background()
+
+	It("should surface a failed create and clear it once it succeeds", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "degraded",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+			},
+		}
+
+		quotaExceeded := true
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(md).
+			WithStatusSubresource(md).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*appsv1.Deployment); ok && quotaExceeded {
+						return errors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"},
+							obj.GetName(), fmt.Errorf("exceeded quota: compute-resources"))
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).
+			Build()
+		reconciler := &ModelDeploymentReconciler{
+			Client: fakeClient,
+			Scheme: scheme.Scheme,
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("ReconcileFailed"))
+		Expect(cond.Message).To(ContainSubstring("exceeded quota"))
+		Expect(md.Status.ObservedGeneration).To(BeZero())
+
+		By("clearing the condition once the create succeeds")
+		quotaExceeded = false
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond = meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(md.Status.ObservedGeneration).To(BeEquivalentTo(1))
+	})
+})
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	defaultDrainPath   = "/drain"
+	defaultDrainPeriod = 2 * time.Minute
+
+	// drainShutdownGrace is how long the runtime is given to shut down once
+	// the drain is over and it receives SIGTERM
+	drainShutdownGrace = 30 * time.Second
+)
+
+// drainScript POSTs to the drain endpoint given as second argument of the
+// runtime at the first, then polls its metrics until no request is running
+// or the period given as third argument, in seconds, is over
+const drainScript = `import sys, time, urllib.request
+base, path, period = sys.argv[1], sys.argv[2], float(sys.argv[3])
+try:
+    urllib.request.urlopen(urllib.request.Request(base + path, method="POST"), timeout=5)
+except Exception:
+    pass
+deadline = time.time() + period
+while time.time() < deadline:
+    try:
+        metrics = urllib.request.urlopen(base + "/metrics", timeout=5).read().decode()
+    except Exception:
+        break
+    running = sum(float(line.split()[-1]) for line in metrics.splitlines() if line.startswith("vllm:num_requests_running"))
+    if running == 0:
+        break
+    time.sleep(1)
+`
+
+// generateDrain returns the preStop hook draining the runtime listening on
+// port, and the termination grace period covering the drain and the
+// shutdown after it
+func generateDrain(md *kaimeraaiv1.ModelDeployment, port int32) (*corev1.Lifecycle, *int64) {
+	drain := md.Spec.GracefulDrain
+
+	drainPath := drain.Path
+	if drainPath == "" {
+		drainPath = defaultDrainPath
+	}
+	period := defaultDrainPeriod
+	if drain.Period != nil {
+		period = drain.Period.Duration
+	}
+
+	lifecycle := &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{
+					"python3", "-c", drainScript,
+					fmt.Sprintf("http://localhost:%d", port), drainPath,
+					strconv.FormatFloat(period.Seconds(), 'f', -1, 64),
+				},
+			},
+		},
+	}
+	gracePeriod := int64((period + drainShutdownGrace).Seconds())
+
+	return lifecycle, &gracePeriod
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment graceful drain", func() {
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "drained",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:     "facebook/opt-125m",
+				GracefulDrain: &kaimeraaiv1.GracefulDrainSpec{},
+			},
+		}
+		reconciler = &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+	})
+
+	It("should call the drain endpoint before the runtime is stopped", func() {
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		podSpec := deploy.Spec.Template.Spec
+		preStop := podSpec.Containers[0].Lifecycle.PreStop
+		Expect(preStop).NotTo(BeNil())
+		Expect(preStop.Exec.Command).To(Equal([]string{
+			"python3", "-c", drainScript, "http://localhost:8000", "/drain", "120",
+		}))
+		Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(150)))
+	})
+
+	It("should use the configured endpoint, port and period", func() {
+		md.Spec.Port = 9000
+		md.Spec.GracefulDrain = &kaimeraaiv1.GracefulDrainSpec{
+			Path:   "/v1/shutdown",
+			Period: &metav1.Duration{Duration: 90 * time.Second},
+		}
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		podSpec := deploy.Spec.Template.Spec
+		Expect(podSpec.Containers[0].Lifecycle.PreStop.Exec.Command[3:]).To(Equal([]string{
+			"http://localhost:9000", "/v1/shutdown", "90",
+		}))
+		Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(120)))
+	})
+
+	It("should keep the default termination without a drain", func() {
+		md.Spec.GracefulDrain = nil
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(deploy.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+		Expect(deploy.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNil())
+	})
+})
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const defaultEgressPort = 443
+
+// defaultDNSCIDR is the link-local address NodeLocal DNSCache listens on
+const defaultDNSCIDR = "169.254.20.10/32"
+
+// reconcileEgressPolicy keeps the egress NetworkPolicy of the model pods in
+// sync with the spec, and removes it once the egress policy is turned off
+func (r *ModelDeploymentReconciler) reconcileEgressPolicy(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.EgressPolicy == nil {
+		return r.deleteOwned(ctx, md, &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: md.Namespace, Name: r.resourceName(md.EgressPolicyName())},
+		})
+	}
+
+	policy, err := r.generateEgressPolicy(md)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(ctx, policy, &networkingv1.NetworkPolicy{})
+}
+
+// generateEgressPolicy returns the NetworkPolicy letting the model pods reach
+// the cluster DNS, the prefix cache if there is one and the allowed
+// destinations only
+func (r *ModelDeploymentReconciler) generateEgressPolicy(md *kaimeraaiv1.ModelDeployment) (*networkingv1.NetworkPolicy, error) {
+	tcp := corev1.ProtocolTCP
+	udp := corev1.ProtocolUDP
+	dnsPort := intstr.FromInt32(53)
+
+	rules := []networkingv1.NetworkPolicyEgressRule{
+		{
+			To: dnsPeers(md.Spec.EgressPolicy.DNS),
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+	}
+
+	if md.Spec.PrefixCache != nil {
+		cachePort := intstr.FromInt32(prefixCachePort)
+		rules = append(rules, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{
+				{
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: prefixCacheLabels(md),
+					},
+				},
+			},
+			Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &cachePort}},
+		})
+	}
+
+	allowed := networkingv1.NetworkPolicyEgressRule{}
+	for _, cidr := range md.Spec.EgressPolicy.AllowedCIDRs {
+		allowed.To = append(allowed.To, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+		})
+	}
+	ports := md.Spec.EgressPolicy.Ports
+	if len(ports) == 0 {
+		ports = []int32{defaultEgressPort}
+	}
+	for _, port := range ports {
+		port := intstr.FromInt32(port)
+		allowed.Ports = append(allowed.Ports, networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &port})
+	}
+	rules = append(rules, allowed)
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.resourceName(md.EgressPolicyName()),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": md.Name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      rules,
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, policy, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// dnsPeers returns the DNS servers the model pods may query, defaulting to
+// the kube-dns pods in kube-system and NodeLocal DNSCache
+func dnsPeers(dns *kaimeraaiv1.EgressDNSSpec) []networkingv1.NetworkPolicyPeer {
+	if dns == nil {
+		dns = &kaimeraaiv1.EgressDNSSpec{}
+	}
+
+	namespaceSelector := dns.NamespaceSelector
+	if namespaceSelector == nil {
+		namespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{corev1.LabelMetadataName: metav1.NamespaceSystem},
+		}
+	}
+	podSelector := dns.PodSelector
+	if podSelector == nil {
+		podSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+		}
+	}
+	cidrs := dns.CIDRs
+	if len(cidrs) == 0 {
+		cidrs = []string{defaultDNSCIDR}
+	}
+
+	peers := []networkingv1.NetworkPolicyPeer{
+		{NamespaceSelector: namespaceSelector, PodSelector: podSelector},
+	}
+	for _, cidr := range cidrs {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+		})
+	}
+
+	return peers
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment egress policy", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "restricted",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				EgressPolicy: &kaimeraaiv1.EgressPolicySpec{
+					AllowedCIDRs: []string{"203.0.113.0/24", "198.51.100.7/32"},
+				},
+			},
+		}
+	})
+
+	It("should only allow DNS and the allowed destinations", func() {
+		policy, err := (&ModelDeploymentReconciler{Scheme: scheme.Scheme}).generateEgressPolicy(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Name).To(Equal("restricted-egress"))
+		Expect(metav1.IsControlledBy(policy, md)).To(BeTrue())
+		Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"app": "restricted"}))
+		Expect(policy.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeEgress}))
+		Expect(policy.Spec.Ingress).To(BeEmpty())
+
+		rules := policy.Spec.Egress
+		Expect(rules).To(HaveLen(2))
+
+		By("allowing DNS lookups against the cluster DNS only")
+		Expect(rules[0].To).To(Equal([]networkingv1.NetworkPolicyPeer{
+			{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"},
+				},
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+				},
+			},
+			{IPBlock: &networkingv1.IPBlock{CIDR: "169.254.20.10/32"}},
+		}))
+		Expect(rules[0].Ports).To(HaveLen(2))
+		Expect(*rules[0].Ports[0].Protocol).To(Equal(corev1.ProtocolUDP))
+		Expect(*rules[0].Ports[0].Port).To(Equal(intstr.FromInt32(53)))
+		Expect(*rules[0].Ports[1].Protocol).To(Equal(corev1.ProtocolTCP))
+		Expect(*rules[0].Ports[1].Port).To(Equal(intstr.FromInt32(53)))
+
+		By("allowing HTTPS to the allowed CIDRs")
+		Expect(rules[1].To).To(Equal([]networkingv1.NetworkPolicyPeer{
+			{IPBlock: &networkingv1.IPBlock{CIDR: "203.0.113.0/24"}},
+			{IPBlock: &networkingv1.IPBlock{CIDR: "198.51.100.7/32"}},
+		}))
+		Expect(rules[1].Ports).To(HaveLen(1))
+		Expect(*rules[1].Ports[0].Port).To(Equal(intstr.FromInt32(443)))
+	})
+
+	It("should allow the prefix cache and the configured ports", func() {
+		md.Spec.PrefixCache = &kaimeraaiv1.PrefixCacheSpec{}
+		md.Spec.EgressPolicy.Ports = []int32{443, 5000}
+
+		policy, err := (&ModelDeploymentReconciler{Scheme: scheme.Scheme}).generateEgressPolicy(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		rules := policy.Spec.Egress
+		Expect(rules).To(HaveLen(3))
+		Expect(rules[1].To[0].PodSelector.MatchLabels).To(Equal(map[string]string{"app": "restricted-prefix-cache"}))
+		Expect(*rules[1].Ports[0].Port).To(Equal(intstr.FromInt32(6379)))
+		Expect(rules[2].Ports).To(HaveLen(2))
+		Expect(*rules[2].Ports[1].Port).To(Equal(intstr.FromInt32(5000)))
+	})
+
+	It("should allow the configured DNS servers", func() {
+		md.Spec.EgressPolicy.DNS = &kaimeraaiv1.EgressDNSSpec{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app.kubernetes.io/name": "coredns"},
+			},
+			CIDRs: []string{"10.0.0.2/32"},
+		}
+
+		policy, err := (&ModelDeploymentReconciler{Scheme: scheme.Scheme}).generateEgressPolicy(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		peers := policy.Spec.Egress[0].To
+		Expect(peers).To(HaveLen(2))
+		Expect(peers[0].NamespaceSelector.MatchLabels).To(Equal(map[string]string{"kubernetes.io/metadata.name": "kube-system"}))
+		Expect(peers[0].PodSelector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "coredns"}))
+		Expect(peers[1].IPBlock.CIDR).To(Equal("10.0.0.2/32"))
+	})
+
+	It("should remove the policy once turned off", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build(),
+			Scheme: scheme.Scheme,
+		}
+		key := types.NamespacedName{Namespace: md.Namespace, Name: "restricted-egress"}
+
+		Expect(reconciler.reconcileEgressPolicy(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &networkingv1.NetworkPolicy{})).To(Succeed())
+
+		md.Spec.EgressPolicy = nil
+		Expect(reconciler.reconcileEgressPolicy(ctx, md)).To(Succeed())
+		err := reconciler.Get(ctx, key, &networkingv1.NetworkPolicy{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	entrypointVolume     = "entrypoint"
+	entrypointMountPath  = "/scripts"
+	defaultEntrypointKey = "start.sh"
+)
+
+func entrypointKey(md *kaimeraaiv1.ModelDeployment) string {
+	if md.Spec.Entrypoint.Key != "" {
+		return md.Spec.Entrypoint.Key
+	}
+
+	return defaultEntrypointKey
+}
+
+// generateEntrypointVolume projects only the script out of the ConfigMap
+func generateEntrypointVolume(md *kaimeraaiv1.ModelDeployment) corev1.Volume {
+	key := entrypointKey(md)
+	return corev1.Volume{
+		Name: entrypointVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: md.Spec.Entrypoint.ConfigMapName},
+				Items:                []corev1.KeyToPath{{Key: key, Path: key}},
+			},
+		},
+	}
+}
+
+// checkEntrypointConfigMap fails with an EntrypointNotFound reason when the
+// entrypoint script is missing, as the pods would otherwise be stuck in
+// ContainerCreating.
+func (r *ModelDeploymentReconciler) checkEntrypointConfigMap(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.Entrypoint == nil {
+		return nil
+	}
+
+	cm := corev1.ConfigMap{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: md.Spec.Entrypoint.ConfigMapName}, &cm)
+	if errors.IsNotFound(err) {
+		return &degradedError{
+			reason:  "EntrypointNotFound",
+			message: fmt.Sprintf("entrypoint configmap %q does not exist", md.Spec.Entrypoint.ConfigMapName),
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cm.Data[entrypointKey(md)]; !ok {
+		return &degradedError{
+			reason:  "EntrypointNotFound",
+			message: fmt.Sprintf("entrypoint configmap %q has no key %q", cm.Name, entrypointKey(md)),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment entrypoint", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "wrapped",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:  "facebook/opt-125m",
+				Entrypoint: &kaimeraaiv1.EntrypointSpec{ConfigMapName: "startup"},
+			},
+		}
+	})
+
+	It("should run the script with the vllm command as its arguments", func() {
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		podSpec := deploy.Spec.Template.Spec
+		Expect(podSpec.Containers[0].Command).To(HaveExactElements(
+			"/bin/sh", "/scripts/start.sh",
+			"vllm", "serve", "--dtype", "auto", "facebook/opt-125m",
+		))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      entrypointVolume,
+			MountPath: "/scripts",
+			ReadOnly:  true,
+		}))
+		Expect(podSpec.Volumes).To(ContainElement(HaveField("VolumeSource.ConfigMap.Name", "startup")))
+	})
+
+	It("should require the script to exist", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+			Scheme: scheme.Scheme,
+		}
+		Expect(reconciler.checkEntrypointConfigMap(ctx, md)).To(MatchError(`entrypoint configmap "startup" does not exist`))
+
+		script := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "startup", Namespace: "default"},
+			Data:       map[string]string{"run.sh": "exec \"$@\""},
+		}
+		Expect(reconciler.Create(ctx, script)).To(Succeed())
+		Expect(reconciler.checkEntrypointConfigMap(ctx, md)).To(MatchError(ContainSubstring(`no key "start.sh"`)))
+
+		md.Spec.Entrypoint.Key = "run.sh"
+		Expect(reconciler.checkEntrypointConfigMap(ctx, md)).To(Succeed())
+	})
+})
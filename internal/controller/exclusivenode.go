@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// requestedGPUs returns the number of GPUs each replica requests: a whole
+// node's worth for exclusive nodes, once it is known
+func requestedGPUs(md *kaimeraaiv1.ModelDeployment) int32 {
+	if md.Spec.ExclusiveNode && md.Status.NodeGPUs > 0 {
+		return md.Status.NodeGPUs
+	}
+
+	return md.Spec.RequestedGPUs()
+}
+
+// reconcileNodeGPUs records the GPU count of a whole node, the smallest
+// among the schedulable nodes matching the ModelDeployment so a replica fits
+// on any of them
+func (r *ModelDeploymentReconciler) reconcileNodeGPUs(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	nodes := corev1.NodeList{}
+	err := r.List(ctx, &nodes, client.MatchingLabels(r.nodeLabels(md)))
+	if err != nil {
+		return err
+	}
+
+	var gpus int32
+	for _, node := range nodes.Items {
+		allocatable := node.Status.Allocatable[md.Spec.GPUResource()]
+		count := int32(allocatable.Value())
+		if node.Spec.Unschedulable || count == 0 {
+			continue
+		}
+		if gpus == 0 || count < gpus {
+			gpus = count
+		}
+	}
+
+	if gpus == md.Status.NodeGPUs {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("node GPUs changed", "gpus", gpus)
+	md.Status.NodeGPUs = gpus
+	return r.updateStatus(ctx, md)
+}
+
+// generateAffinity returns the node affinity for the requirements, and the
+// pod anti-affinity keeping exclusive node replicas apart or spreading highly
+// available ones
+func generateAffinity(md *kaimeraaiv1.ModelDeployment, requirements []corev1.NodeSelectorRequirement) *corev1.Affinity {
+	affinity := generateNodeAffinity(requirements, minCUDAVersionAlternatives(md))
+	antiAffinity := generateSpreadAntiAffinity(md)
+	if md.Spec.ExclusiveNode {
+		antiAffinity = &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+				{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": md.Name},
+					},
+					TopologyKey: corev1.LabelHostname,
+				},
+			},
+		}
+	}
+	if antiAffinity == nil {
+		return affinity
+	}
+
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	affinity.PodAntiAffinity = antiAffinity
+
+	return affinity
+}
+
+// generateStrategy replaces exclusive node replicas one at a time without a
+// surge, as a surge pod would find no free node, and highly available
+// replicas one at a time without taking one down first. Other deployments
+// use the Deployment defaults.
+func generateStrategy(md *kaimeraaiv1.ModelDeployment) appsv1.DeploymentStrategy {
+	if md.Spec.HighAvailability {
+		return generateHighAvailabilityStrategy()
+	}
+	if !md.Spec.ExclusiveNode {
+		return appsv1.DeploymentStrategy{}
+	}
+
+	maxSurge := intstr.FromInt32(0)
+	maxUnavailable := intstr.FromInt32(1)
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment exclusive nodes", func() {
+	ctx := context.Background()
+
+	gpuNode := func(name string, gpus string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"kubernetes.io/arch": "amd64"},
+			},
+			Spec: corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					kaimeraaiv1.DefaultGPUResourceName: resource.MustParse(gpus),
+				},
+			},
+		}
+	}
+
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "exclusive",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:     "facebook/opt-125m",
+				Runtime:       "gpu",
+				Replicas:      2,
+				ExclusiveNode: true,
+			},
+		}
+	})
+
+	It("should record the GPUs of the smallest schedulable node", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md,
+					gpuNode("large", "8", false),
+					gpuNode("small", "4", false),
+					gpuNode("cordoned", "2", true),
+					gpuNode("cpu", "0", false),
+				).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		Expect(reconciler.reconcileNodeGPUs(ctx, md)).To(Succeed())
+		Expect(md.Status.NodeGPUs).To(BeEquivalentTo(4))
+	})
+
+	It("should request the whole node and keep replicas apart", func() {
+		md.Status.NodeGPUs = 8
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		podSpec := deploy.Spec.Template.Spec
+		gpus := podSpec.Containers[0].Resources.Limits[kaimeraaiv1.DefaultGPUResourceName]
+		Expect(gpus.Value()).To(BeEquivalentTo(8))
+
+		Expect(podSpec.Affinity.PodAntiAffinity).NotTo(BeNil())
+		terms := podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		Expect(terms).To(HaveLen(1))
+		Expect(terms[0].TopologyKey).To(Equal(corev1.LabelHostname))
+		Expect(terms[0].LabelSelector.MatchLabels).To(Equal(map[string]string{"app": "exclusive"}))
+
+		Expect(deploy.Spec.Strategy.RollingUpdate.MaxSurge.IntValue()).To(BeZero())
+	})
+
+	It("should leave other deployments spread as usual", func() {
+		md.Spec.ExclusiveNode = false
+		md.Spec.GPUCount = 2
+		md.Status.NodeGPUs = 8
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		gpus := deploy.Spec.Template.Spec.Containers[0].Resources.Limits[kaimeraaiv1.DefaultGPUResourceName]
+		Expect(gpus.Value()).To(BeEquivalentTo(2))
+		Expect(deploy.Spec.Template.Spec.Affinity).To(BeNil())
+		Expect(deploy.Spec.Strategy.RollingUpdate).To(BeNil())
+	})
+})
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	gpuTuningName         = "gpu-tuning"
+	defaultGPUTuningImage = "nvcr.io/nvidia/cuda:12.4.1-base-ubuntu22.04"
+)
+
+// gpuTuningCommand returns the nvidia-smi invocations setting the spec's
+// limits on every GPU visible to the container. Persistence mode keeps the
+// driver loaded, so the limits still apply once the init container exits.
+func gpuTuningCommand(tuning *kaimeraaiv1.GPUTuningSpec) []string {
+	steps := []string{"nvidia-smi -pm 1"}
+	if tuning.PowerLimitWatts > 0 {
+		steps = append(steps, fmt.Sprintf("nvidia-smi -pl %d", tuning.PowerLimitWatts))
+	}
+	if clocks := tuning.LockedGraphicsClocks; clocks != nil {
+		steps = append(steps, fmt.Sprintf("nvidia-smi -lgc %d,%d", clocks.MinMHz, clocks.MaxMHz))
+	}
+
+	return []string{"/bin/sh", "-c", strings.Join(steps, " && ")}
+}
+
+// generateGPUTuning returns the privileged init container tuning the GPUs
+// of the replica. It requests the runtime's GPU limits, so the device plugin
+// hands it the same GPUs the runtime is then given.
+func generateGPUTuning(md *kaimeraaiv1.ModelDeployment, limits corev1.ResourceList) corev1.Container {
+	tuning := md.Spec.GPUTuning
+
+	image := tuning.Image
+	if image == "" {
+		image = defaultGPUTuningImage
+	}
+
+	gpuLimits := corev1.ResourceList{}
+	if quantity, ok := limits[md.Spec.GPUResource()]; ok {
+		gpuLimits[md.Spec.GPUResource()] = quantity
+	}
+
+	privileged := true
+	return corev1.Container{
+		Name:            gpuTuningName,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         gpuTuningCommand(tuning),
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &privileged,
+		},
+		Resources: corev1.ResourceRequirements{
+			Limits: gpuLimits,
+		},
+	}
+}
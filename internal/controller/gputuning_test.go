@@ -0,0 +1,73 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment GPU tuning", func() {
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tuned",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Runtime:   "gpu",
+				GPUTuning: &kaimeraaiv1.GPUTuningSpec{
+					PowerLimitWatts:      300,
+					LockedGraphicsClocks: &kaimeraaiv1.ClockRange{MinMHz: 1000, MaxMHz: 1400},
+				},
+			},
+		}
+		reconciler = &ModelDeploymentReconciler{Scheme: scheme.Scheme, AllowGPUTuning: true}
+	})
+
+	It("should set the limits in a privileged init container", func() {
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		initContainers := deploy.Spec.Template.Spec.InitContainers
+		Expect(initContainers).To(HaveLen(1))
+		tuning := initContainers[0]
+		Expect(tuning.Name).To(Equal("gpu-tuning"))
+		Expect(tuning.Image).To(Equal(defaultGPUTuningImage))
+		Expect(tuning.Command).To(Equal([]string{
+			"/bin/sh", "-c", "nvidia-smi -pm 1 && nvidia-smi -pl 300 && nvidia-smi -lgc 1000,1400",
+		}))
+		Expect(*tuning.SecurityContext.Privileged).To(BeTrue())
+		Expect(tuning.Resources.Limits).To(HaveLen(1))
+		gpus := tuning.Resources.Limits[md.Spec.GPUResource()]
+		Expect(gpus.Value()).To(BeNumerically("==", 1))
+	})
+
+	It("should only run the steps for the limits set", func() {
+		md.Spec.GPUTuning.LockedGraphicsClocks = nil
+		Expect(gpuTuningCommand(md.Spec.GPUTuning)).To(Equal([]string{
+			"/bin/sh", "-c", "nvidia-smi -pm 1 && nvidia-smi -pl 300",
+		}))
+	})
+
+	It("should refuse to tune unless allowed", func() {
+		reconciler.AllowGPUTuning = false
+		_, err := reconciler.generateDeployment(md)
+		Expect(err).To(MatchError("GPU tuning is not allowed on this cluster"))
+		Expect(err).To(BeAssignableToTypeOf(&degradedError{}))
+		Expect(err.(*degradedError).reason).To(Equal("GPUTuningNotAllowed"))
+	})
+
+	It("should not tune the CPU runtime", func() {
+		md.Spec.Runtime = "cpu"
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Spec.Template.Spec.InitContainers).To(BeEmpty())
+	})
+})
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// highAvailabilityMaxUnavailable is the number of replicas the
+// PodDisruptionBudget of a highly available model lets be evicted at once.
+// A single replica can still be evicted, so it doesn't block node drains.
+const highAvailabilityMaxUnavailable = 1
+
+// reconcilePodDisruptionBudget keeps the PodDisruptionBudget of a highly
+// available model in sync with the spec, and removes it once high
+// availability is turned off
+func (r *ModelDeploymentReconciler) reconcilePodDisruptionBudget(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if !md.Spec.HighAvailability {
+		return r.deleteOwned(ctx, md, &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: md.Namespace, Name: r.resourceName(md.Name)},
+		})
+	}
+
+	pdb, err := r.generatePodDisruptionBudget(md)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(ctx, pdb, &policyv1.PodDisruptionBudget{})
+}
+
+func (r *ModelDeploymentReconciler) generatePodDisruptionBudget(md *kaimeraaiv1.ModelDeployment) (*policyv1.PodDisruptionBudget, error) {
+	maxUnavailable := intstr.FromInt32(highAvailabilityMaxUnavailable)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.resourceName(md.Name),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": md.Name},
+			},
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, pdb, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdb, nil
+}
+
+// generateSpreadAntiAffinity returns the pod anti-affinity spreading highly
+// available replicas over nodes, and zones where it can. It is only
+// preferred, so the replicas still schedule on clusters with fewer nodes.
+func generateSpreadAntiAffinity(md *kaimeraaiv1.ModelDeployment) *corev1.PodAntiAffinity {
+	if !md.Spec.HighAvailability {
+		return nil
+	}
+
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app": md.Name},
+	}
+	return &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+			{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: selector,
+					TopologyKey:   corev1.LabelHostname,
+				},
+			},
+			{
+				Weight: 50,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: selector,
+					TopologyKey:   corev1.LabelTopologyZone,
+				},
+			},
+		},
+	}
+}
+
+// generateHighAvailabilityStrategy surges a new replica before removing an
+// old one, so a rollout never serves with fewer replicas than the spec
+func generateHighAvailabilityStrategy() appsv1.DeploymentStrategy {
+	maxSurge := intstr.FromInt32(1)
+	maxUnavailable := intstr.FromInt32(0)
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
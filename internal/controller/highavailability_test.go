@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment high availability", func() {
+	ctx := context.Background()
+
+	It("should generate the disruption budget, priority, spread and rollout strategy together", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "available",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:        "facebook/opt-125m",
+				Replicas:         3,
+				HighAvailability: true,
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:               scheme.Scheme,
+			DefaultPriorityClass: "model-serving",
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("evicting one replica at a time")
+		pdb := policyv1.PodDisruptionBudget{}
+		Expect(reconciler.Get(ctx, key, &pdb)).To(Succeed())
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(metav1.IsControlledBy(&pdb, md)).To(BeTrue())
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+		Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt32(1)))
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "available"}))
+
+		deploy := appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, &deploy)).To(Succeed())
+		podSpec := deploy.Spec.Template.Spec
+
+		By("giving the pods the default priority class")
+		Expect(podSpec.PriorityClassName).To(Equal("model-serving"))
+
+		By("spreading the replicas over nodes and zones")
+		antiAffinity := podSpec.Affinity.PodAntiAffinity
+		Expect(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(BeEmpty())
+		terms := antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		Expect(terms).To(HaveLen(2))
+		Expect(terms[0].PodAffinityTerm.TopologyKey).To(Equal(corev1.LabelHostname))
+		Expect(terms[1].PodAffinityTerm.TopologyKey).To(Equal(corev1.LabelTopologyZone))
+		Expect(terms[0].PodAffinityTerm.LabelSelector.MatchLabels).To(Equal(map[string]string{"app": "available"}))
+
+		By("surging a new replica before removing an old one")
+		Expect(deploy.Spec.Strategy.Type).To(Equal(appsv1.RollingUpdateDeploymentStrategyType))
+		Expect(*deploy.Spec.Strategy.RollingUpdate.MaxSurge).To(Equal(intstr.FromInt32(1)))
+		Expect(*deploy.Spec.Strategy.RollingUpdate.MaxUnavailable).To(Equal(intstr.FromInt32(0)))
+
+		By("removing the disruption budget once turned off")
+		md.Spec.HighAvailability = false
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		err = reconciler.Get(ctx, key, &policyv1.PodDisruptionBudget{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should keep the priority class of evictionPriority", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "available", Namespace: "default"},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:        "facebook/opt-125m",
+				HighAvailability: true,
+				EvictionPriority: &kaimeraaiv1.EvictionPrioritySpec{PriorityClassName: "model-critical"},
+			},
+		}
+
+		deploy, err := (&ModelDeploymentReconciler{Scheme: scheme.Scheme}).generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Spec.Template.Spec.PriorityClassName).To(Equal("model-critical"))
+	})
+})
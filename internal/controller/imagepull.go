@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	imagePullBackOffReason = "ImagePullBackOff"
+	errImagePullReason     = "ErrImagePull"
+	largeImageReason       = "LargeImage"
+	imageNotAllowedReason  = "ImageNotAllowed"
+)
+
+// largeImages are the repositories of runtime images known to be large
+// enough to outlast image pull timeouts on slow registries, with their
+// approximate size
+var largeImages = map[string]string{
+	"vllm/vllm-openai": "10GB",
+}
+
+// warnLargeImages emits a Warning event for each container of deploy whose
+// image is known to be large, as pulling it can exceed the kubelet's runtime
+// request timeout on slow registries
+func (r *ModelDeploymentReconciler) warnLargeImages(md *kaimeraaiv1.ModelDeployment, deploy *appsv1.Deployment) {
+	for _, container := range deploy.Spec.Template.Spec.Containers {
+		size, ok := largeImages[kaimeraaiv1.ImageRepository(container.Image)]
+		if !ok {
+			continue
+		}
+
+		r.warningEvent(md, largeImageReason, fmt.Sprintf(
+			"image %s of container %q is about %s, pulling it may exceed the kubelet's runtime request timeout on slow registries",
+			container.Image, container.Name, size))
+	}
+}
+
+// checkImagePull fails with an ImagePullBackOff reason when a model pod
+// can't pull one of its images, e.g. because it doesn't exist, the registry
+// refuses the credentials or the pull timed out
+func (r *ModelDeploymentReconciler) checkImagePull(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	pods := corev1.PodList{}
+	err := r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{"app": md.Name})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			waiting := status.State.Waiting
+			if waiting == nil || (waiting.Reason != imagePullBackOffReason && waiting.Reason != errImagePullReason) {
+				continue
+			}
+
+			message := fmt.Sprintf("container %q of pod %q can't pull image %s", status.Name, pod.Name, status.Image)
+			if text := strings.TrimSpace(waiting.Message); text != "" {
+				message += ": " + text
+			}
+			if !degradedWith(md, imagePullBackOffReason, message) {
+				r.warningEvent(md, imagePullBackOffReason, message)
+			}
+			return &degradedError{reason: imagePullBackOffReason, message: message}
+		}
+	}
+
+	return nil
+}
+
+// checkImageRegistries fails with an ImageNotAllowed reason when a container
+// of deploy runs an image from outside the allowed registries. The webhook
+// rejects such images set in the spec; this also covers the defaults of the
+// sidecars and init containers.
+func (r *ModelDeploymentReconciler) checkImageRegistries(deploy *appsv1.Deployment) error {
+	return r.checkPodImageRegistries(&deploy.Spec.Template.Spec)
+}
+
+// checkPodImageRegistries is checkImageRegistries for the pods of any
+// workload, e.g. a Job
+func (r *ModelDeploymentReconciler) checkPodImageRegistries(podSpec *corev1.PodSpec) error {
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		if kaimeraaiv1.ImageRegistryAllowed(container.Image, r.AllowedImageRegistries) {
+			continue
+		}
+
+		return &degradedError{reason: imageNotAllowedReason, message: fmt.Sprintf(
+			"image %s of container %q is not from an allowed registry; allowed registries are %s",
+			container.Image, container.Name, strings.Join(r.AllowedImageRegistries, ", "))}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment image pulls", func() {
+	ctx := context.Background()
+
+	It("should report a pod that can't pull its image in the Degraded condition", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "pull",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Runtime:   "gpu",
+			},
+		}
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pull-7d9f8-abcde",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "pull"},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "model-server",
+						Image: "vllm/vllm-openai:latest",
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{
+								Reason:  "ImagePullBackOff",
+								Message: `Back-off pulling image "vllm/vllm-openai:latest"`,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, pod).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+		}
+
+		By("warning about the size of the gpu runtime image on create")
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning LargeImage image vllm/vllm-openai:latest")))
+
+		By("reporting the failed pull once the deployment exists")
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("ImagePullBackOff"))
+		Expect(cond.Message).To(Equal(`container "model-server" of pod "pull-7d9f8-abcde" can't pull image vllm/vllm-openai:latest: ` +
+			`Back-off pulling image "vllm/vllm-openai:latest"`))
+
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning ImagePullBackOff")))
+	})
+
+	It("should not run images from outside the allowed registries", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "registry",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Runtime:   "gpu",
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:                 scheme.Scheme,
+			AllowedImageRegistries: []string{"nvcr.io"},
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal("ImageNotAllowed"))
+		Expect(cond.Message).To(Equal(`image vllm/vllm-openai:latest of container "model-server" is not from an allowed registry; ` +
+			`allowed registries are nvcr.io`))
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).NotTo(Succeed())
+
+		By("running them once their registry is allowed")
+		reconciler.AllowedImageRegistries = append(reconciler.AllowedImageRegistries, "docker.io/vllm")
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+	})
+
+	It("should not run smoke test images from outside the allowed registries", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default", Generation: 1},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				SmokeTest: true,
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:                 scheme.Scheme,
+			AllowedImageRegistries: []string{"docker.io/vllm"},
+		}
+
+		replicas := int32(1)
+		dp := &appsv1.Deployment{
+			Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+		}
+		err := reconciler.reconcileSmokeTest(ctx, md, dp)
+		Expect(err).To(MatchError(`image curlimages/curl:8.8.0 of container "smoke-test" is not from an allowed registry; ` +
+			`allowed registries are docker.io/vllm`))
+		Expect(reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "registry-smoke-test"}, &batchv1.Job{})).NotTo(Succeed())
+	})
+
+	It("should strip the tag and digest from image references", func() {
+		Expect(kaimeraaiv1.ImageRepository("vllm/vllm-openai:v0.6.0")).To(Equal("vllm/vllm-openai"))
+		Expect(kaimeraaiv1.ImageRepository("registry:5000/vllm@sha256:abc")).To(Equal("registry:5000/vllm"))
+		Expect(kaimeraaiv1.ImageRepository("registry:5000/vllm")).To(Equal("registry:5000/vllm"))
+	})
+})
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	logVolume              = "model-logs"
+	logMountPath           = "/var/log/model"
+	logFifo                = logMountPath + "/output.fifo"
+	logFile                = logMountPath + "/model.log"
+	logShipperName         = "log-shipper"
+	defaultLogShipperImage = "cr.fluentbit.io/fluent/fluent-bit:3.1"
+
+	// logShipperDB is where the log shipper keeps how far it read the log
+	// file, so it resumes there when it restarts
+	logShipperDB = logMountPath + "/log-shipper.db"
+
+	// logMaxFileSize is the size in bytes at which the log file is
+	// truncated, checked every logTruncateInterval seconds
+	logMaxFileSize      = 100 * 1024 * 1024
+	logTruncateInterval = 60
+)
+
+// logVolumeSizeLimit bounds the node's disk the log file may take up
+var logVolumeSizeLimit = resource.MustParse("2Gi")
+
+// teeLogCommand copies the output of command to the given log files, as well
+// as to the container's stdout. The runtime is exec'd writing into fifo, so
+// it keeps receiving signals and its exit code is the container's. The
+// paths are expanded by the shell.
+//
+// truncateFile, if set, is one of the log files that is truncated in place
+// once it reaches logMaxFileSize, so it doesn't fill the log volume. tee
+// appends to it, so it keeps writing at the new end.
+func teeLogCommand(command []string, fifo, truncateFile string, logFiles ...string) []string {
+	var files string
+	for _, logFile := range logFiles {
+		files += fmt.Sprintf(` "%s"`, logFile)
+	}
+	// The fifo is created before tee starts in the background, so the
+	// runtime never opens the path before it is a fifo
+	script := fmt.Sprintf(`rm -f "%[1]s" && mkfifo "%[1]s" && { tee -a%[2]s < "%[1]s" & } && `, fifo, files)
+	if truncateFile != "" {
+		script += fmt.Sprintf(`{ while sleep %[1]d; do [ "$(wc -c < "%[2]s")" -lt %[3]d ] || : > "%[2]s"; done & } && `,
+			logTruncateInterval, truncateFile, logMaxFileSize)
+	}
+	script += fmt.Sprintf(`exec "$@" > "%s" 2>&1`, fifo)
+
+	return append([]string{"/bin/sh", "-c", script, "sh"}, command...)
+}
+
+// generateLogShipper returns the fluent-bit sidecar tailing the log file on
+// the shared volume and posting it to the configured endpoint. It reads the
+// file again from the start once it is truncated.
+func generateLogShipper(md *kaimeraaiv1.ModelDeployment) (corev1.Container, error) {
+	sidecar := md.Spec.Logging.Sidecar
+	endpoint, err := url.Parse(sidecar.Endpoint)
+	if err != nil {
+		return corev1.Container{}, fmt.Errorf("invalid log endpoint: %w", err)
+	}
+
+	tls := "off"
+	port := endpoint.Port()
+	if endpoint.Scheme == "https" {
+		tls = "on"
+		if port == "" {
+			port = "443"
+		}
+	}
+	if port == "" {
+		port = "80"
+	}
+
+	image := sidecar.Image
+	if image == "" {
+		image = defaultLogShipperImage
+	}
+
+	return corev1.Container{
+		Name:  logShipperName,
+		Image: image,
+		Args: []string{
+			"-i", "tail",
+			"-p", "path=" + path.Join(logMountPath, "*.log"),
+			"-p", "read_from_head=true",
+			"-p", "db=" + logShipperDB,
+			"-o", "http",
+			"-p", "match=*",
+			"-p", "host=" + endpoint.Hostname(),
+			"-p", "port=" + port,
+			"-p", "uri=" + endpoint.RequestURI(),
+			"-p", "tls=" + tls,
+			"-p", "format=json",
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      logVolume,
+				MountPath: logMountPath,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// loraMountPath is where the whole model cache claim is mounted read-only
+// for the runtime to load adapters from
+const loraMountPath = "/etc/kaimera/lora"
+
+// loraEnabled reports whether the runtime serves LoRA adapters. They are
+// only loaded from the model cache claim.
+func loraEnabled(md *kaimeraaiv1.ModelDeployment) bool {
+	return md.Spec.ModelCache != nil && (len(md.Spec.LoRAAdapters) > 0 || md.Spec.LoRAHotReload)
+}
+
+// loraAdapterPath returns where the runtime finds the adapter
+func loraAdapterPath(adapter kaimeraaiv1.LoRAAdapter) string {
+	return path.Join(loraMountPath, cleanVolumePath(adapter.Path))
+}
+
+// loraArgs returns the runtime arguments serving the LoRA adapters of the
+// spec, or nil if there are none. With hot reloading the adapters are
+// loaded through the runtime's API instead, so changing them doesn't roll
+// out the pods.
+func loraArgs(md *kaimeraaiv1.ModelDeployment) []string {
+	if !loraEnabled(md) {
+		return nil
+	}
+	if md.Spec.LoRAHotReload {
+		return []string{"--enable-lora"}
+	}
+
+	args := []string{"--enable-lora", "--lora-modules"}
+	for _, adapter := range md.Spec.LoRAAdapters {
+		args = append(args, fmt.Sprintf("%s=%s", adapter.Name, loraAdapterPath(adapter)))
+	}
+
+	return args
+}
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// Endpoints of the runtime's API used to hot reload LoRA adapters
+const (
+	modelsPath      = "/v1/models"
+	loadLoRAPath    = "/v1/load_lora_adapter"
+	unloadLoRAPath  = "/v1/unload_lora_adapter"
+	loraHTTPTimeout = 30 * time.Second
+)
+
+// loraSyncConcurrency bounds the replicas synced at once, and loraSyncTimeout
+// bounds the whole sync so it doesn't hold up the reconcile
+const (
+	loraSyncConcurrency = 8
+	loraSyncTimeout     = time.Minute
+)
+
+var loraClient = &http.Client{Timeout: loraHTTPTimeout}
+
+// reconcileLoRAAdapters loads and unloads adapters on every ready replica
+// until they serve exactly the spec's adapters, and records the adapters
+// loaded everywhere in the status. The replicas are synced concurrently.
+func (r *ModelDeploymentReconciler) reconcileLoRAAdapters(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if !md.Spec.LoRAHotReload || !loraEnabled(md) {
+		return nil
+	}
+
+	apiKey, err := r.apiKey(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	pods := corev1.PodList{}
+	err = r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{"app": md.Name})
+	if err != nil {
+		return err
+	}
+
+	var readyPods []*corev1.Pod
+	for i := range pods.Items {
+		if podReady(&pods.Items[i]) {
+			readyPods = append(readyPods, &pods.Items[i])
+		}
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, loraSyncTimeout)
+	defer cancel()
+	eg, syncCtx := errgroup.WithContext(syncCtx)
+	eg.SetLimit(loraSyncConcurrency)
+	loadedPerPod := make([][]string, len(readyPods))
+	for i, pod := range readyPods {
+		eg.Go(func() error {
+			runtime := runtimeAPI{baseURL: runtimeBaseURL(md, pod), apiKey: string(apiKey)}
+			loaded, err := runtime.syncAdapters(syncCtx, md)
+			if err != nil {
+				return fmt.Errorf("syncing LoRA adapters of pod %s: %w", pod.Name, err)
+			}
+			loadedPerPod[i] = loaded
+			return nil
+		})
+	}
+	err = eg.Wait()
+	if err != nil {
+		return err
+	}
+
+	loadedOn := map[string]int{}
+	for _, loaded := range loadedPerPod {
+		for _, name := range loaded {
+			loadedOn[name]++
+		}
+	}
+
+	var active []string
+	for name, count := range loadedOn {
+		if count == len(readyPods) {
+			active = append(active, name)
+		}
+	}
+	sort.Strings(active)
+
+	if equality.Semantic.DeepEqual(md.Status.LoRAAdapters, active) {
+		return nil
+	}
+	md.Status.LoRAAdapters = active
+	return r.updateStatus(ctx, md)
+}
+
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// runtimeBaseURL returns the URL of the runtime API served by pod
+func runtimeBaseURL(md *kaimeraaiv1.ModelDeployment, pod *corev1.Pod) string {
+	port := int32(servingPort)
+	if md.Spec.Port > 0 {
+		port = md.Spec.Port
+	}
+
+	return "http://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port)))
+}
+
+// runtimeAPI is the API of a single runtime replica
+type runtimeAPI struct {
+	baseURL string
+	apiKey  string
+}
+
+// servedModel is an entry of the runtime's model list. LoRA adapters have
+// the base model as their parent and their path as their root.
+type servedModel struct {
+	ID     string  `json:"id"`
+	Root   string  `json:"root"`
+	Parent *string `json:"parent"`
+}
+
+// syncAdapters unloads the adapters the spec no longer lists, or lists with
+// another path, and loads the missing ones. It returns the adapters loaded
+// once done.
+func (rt runtimeAPI) syncAdapters(ctx context.Context, md *kaimeraaiv1.ModelDeployment) ([]string, error) {
+	models := struct {
+		Data []servedModel `json:"data"`
+	}{}
+	err := rt.do(ctx, http.MethodGet, modelsPath, nil, &models)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := map[string]string{}
+	for _, model := range models.Data {
+		if model.Parent != nil {
+			loaded[model.ID] = model.Root
+		}
+	}
+
+	desired := map[string]string{}
+	for _, adapter := range md.Spec.LoRAAdapters {
+		desired[adapter.Name] = loraAdapterPath(adapter)
+	}
+
+	logger := log.FromContext(ctx).WithValues("runtime", rt.baseURL)
+	for name, path := range loaded {
+		if desired[name] == path {
+			continue
+		}
+
+		logger.Info("unloading LoRA adapter", "adapter", name)
+		err := rt.do(ctx, http.MethodPost, unloadLoRAPath, map[string]string{"lora_name": name}, nil)
+		if err != nil {
+			return nil, err
+		}
+		delete(loaded, name)
+	}
+
+	for _, adapter := range md.Spec.LoRAAdapters {
+		if _, ok := loaded[adapter.Name]; ok {
+			continue
+		}
+
+		logger.Info("loading LoRA adapter", "adapter", adapter.Name)
+		path := desired[adapter.Name]
+		err := rt.do(ctx, http.MethodPost, loadLoRAPath, map[string]string{"lora_name": adapter.Name, "lora_path": path}, nil)
+		if err != nil {
+			return nil, err
+		}
+		loaded[adapter.Name] = path
+	}
+
+	var names []string
+	for name := range loaded {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// do sends a request to the runtime with body encoded as JSON, and decodes
+// the response into out unless it is nil
+func (rt runtimeAPI) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rt.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if rt.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+rt.apiKey)
+	}
+
+	resp, err := loraClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, bytes.TrimSpace(message))
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// stubRuntime serves the parts of the vLLM API used to hot reload LoRA
+// adapters, recording the calls made to it
+type stubRuntime struct {
+	mu            sync.Mutex
+	base          string
+	loaded        map[string]string
+	calls         []string
+	authorization string
+}
+
+func (s *stubRuntime) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	defer GinkgoRecover()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authorization = req.Header.Get("Authorization")
+
+	switch req.URL.Path {
+	case modelsPath:
+		data := []servedModel{{ID: s.base, Root: s.base}}
+		for name, path := range s.loaded {
+			data = append(data, servedModel{ID: name, Root: path, Parent: &s.base})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	case loadLoRAPath, unloadLoRAPath:
+		body := map[string]string{}
+		Expect(json.NewDecoder(req.Body).Decode(&body)).To(Succeed())
+		if req.URL.Path == loadLoRAPath {
+			s.loaded[body["lora_name"]] = body["lora_path"]
+			s.calls = append(s.calls, "load "+body["lora_name"])
+		} else {
+			delete(s.loaded, body["lora_name"])
+			s.calls = append(s.calls, "unload "+body["lora_name"])
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *stubRuntime) takeCalls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := s.calls
+	s.calls = nil
+	return calls
+}
+
+var _ = Describe("ModelDeployment LoRA hot reload", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+	var runtime *stubRuntime
+
+	BeforeEach(func() {
+		runtime = &stubRuntime{base: "facebook/opt-125m", loaded: map[string]string{}}
+		server := httptest.NewServer(runtime)
+		DeferCleanup(server.Close)
+
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		host, portString, err := net.SplitHostPort(serverURL.Host)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(portString)
+		Expect(err).NotTo(HaveOccurred())
+
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "lora",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:     "facebook/opt-125m",
+				Port:          int32(port),
+				ModelCache:    &kaimeraaiv1.ModelCacheSpec{ClaimName: "models"},
+				LoRAHotReload: true,
+				LoRAAdapters: []kaimeraaiv1.LoRAAdapter{
+					{Name: "sql", Path: "adapters/sql"},
+					{Name: "chat", Path: "adapters/chat"},
+				},
+			},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "lora-0",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "lora"},
+			},
+			Status: corev1.PodStatus{
+				PodIP:      host,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, pod).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should not roll out the pods when the adapters change", func() {
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		container := deploy.Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(ContainElement("--enable-lora"))
+		Expect(container.Command).NotTo(ContainElement("--lora-modules"))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "VLLM_ALLOW_RUNTIME_LORA_UPDATING", Value: "True"}))
+
+		md.Spec.LoRAAdapters = nil
+		changed, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed.Spec.Template).To(Equal(deploy.Spec.Template))
+	})
+
+	It("should load and unload adapters as the list changes", func() {
+		Expect(reconciler.reconcileLoRAAdapters(ctx, md)).To(Succeed())
+		Expect(runtime.takeCalls()).To(ConsistOf("load sql", "load chat"))
+		Expect(runtime.loaded).To(HaveKeyWithValue("sql", "/etc/kaimera/lora/adapters/sql"))
+		Expect(md.Status.LoRAAdapters).To(Equal([]string{"chat", "sql"}))
+
+		By("leaving loaded adapters alone")
+		Expect(reconciler.reconcileLoRAAdapters(ctx, md)).To(Succeed())
+		Expect(runtime.takeCalls()).To(BeEmpty())
+
+		By("unloading removed adapters and reloading moved ones")
+		md.Spec.LoRAAdapters = []kaimeraaiv1.LoRAAdapter{{Name: "sql", Path: "adapters/sql-v2"}}
+		Expect(reconciler.reconcileLoRAAdapters(ctx, md)).To(Succeed())
+		Expect(runtime.takeCalls()).To(ConsistOf("unload chat", "unload sql", "load sql"))
+		Expect(runtime.loaded).To(Equal(map[string]string{"sql": "/etc/kaimera/lora/adapters/sql-v2"}))
+		Expect(md.Status.LoRAAdapters).To(Equal([]string{"sql"}))
+	})
+
+	It("should authenticate with the runtime's API key", func() {
+		Expect(reconciler.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-key", Namespace: "default"},
+			Data:       map[string][]byte{"key": []byte("secret")},
+		})).To(Succeed())
+		md.Spec.APIKeySecretRef = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+			Key:                  "key",
+		}
+
+		Expect(reconciler.reconcileLoRAAdapters(ctx, md)).To(Succeed())
+		Expect(runtime.authorization).To(Equal("Bearer secret"))
+	})
+})
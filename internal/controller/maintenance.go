@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// maintenanceWindow is a daily time range in UTC. It wraps around midnight
+// when end is before start.
+type maintenanceWindow struct {
+	start, end time.Duration
+}
+
+// parseMaintenanceWindow parses a window in the form HH:MM-HH:MM
+func parseMaintenanceWindow(value string) (maintenanceWindow, error) {
+	startValue, endValue, ok := strings.Cut(value, "-")
+	if !ok {
+		return maintenanceWindow{}, fmt.Errorf("maintenance window %q is not in the form HH:MM-HH:MM", value)
+	}
+
+	start, err := time.Parse("15:04", strings.TrimSpace(startValue))
+	if err != nil {
+		return maintenanceWindow{}, fmt.Errorf("maintenance window %q: %w", value, err)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(endValue))
+	if err != nil {
+		return maintenanceWindow{}, fmt.Errorf("maintenance window %q: %w", value, err)
+	}
+
+	return maintenanceWindow{
+		start: time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		end:   time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+	}, nil
+}
+
+// untilOpen returns how long it is from now until the window opens, or 0 if
+// it is open
+func (w maintenanceWindow) untilOpen(now time.Time) time.Duration {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	sinceMidnight := now.Sub(midnight)
+
+	open := sinceMidnight >= w.start && sinceMidnight < w.end
+	if w.end <= w.start {
+		open = sinceMidnight >= w.start || sinceMidnight < w.end
+	}
+	if open {
+		return 0
+	}
+
+	if sinceMidnight < w.start {
+		return w.start - sinceMidnight
+	}
+	return 24*time.Hour - sinceMidnight + w.start
+}
+
+// reconcileMaintenanceWindow holds back a pending spec change outside of the
+// maintenance window: the workload isn't updated, while the rest of the
+// children are. It returns how long to wait for the window to open, or 0 if
+// the change can be rolled out now.
+func (r *ModelDeploymentReconciler) reconcileMaintenanceWindow(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (time.Duration, error) {
+	value, ok := md.Annotations[kaimeraaiv1.MaintenanceWindowAnnotation]
+	// The children are behind a change of the spec until it is reconciled
+	pending := md.Status.ObservedGeneration != 0 && md.Status.ObservedGeneration != md.Generation
+	if !ok || !pending {
+		return 0, r.clearRolloutDeferred(ctx, md)
+	}
+
+	window, err := parseMaintenanceWindow(value)
+	if err != nil {
+		// A broken annotation must not block rollouts forever
+		log.FromContext(ctx).Error(err, "ignoring maintenance window")
+		return 0, r.clearRolloutDeferred(ctx, md)
+	}
+
+	wait := window.untilOpen(r.now().Time)
+	if wait == 0 {
+		return 0, r.clearRolloutDeferred(ctx, md)
+	}
+
+	log.FromContext(ctx).Info("deferring rollout until the maintenance window", "window", value, "wait", wait)
+	changed := meta.SetStatusCondition(&md.Status.Conditions, metav1.Condition{
+		Type:               kaimeraaiv1.ConditionRolloutDeferred,
+		Status:             metav1.ConditionTrue,
+		Reason:             "OutsideMaintenanceWindow",
+		Message:            fmt.Sprintf("the change will be rolled out in the maintenance window %s UTC", value),
+		ObservedGeneration: md.Generation,
+	})
+	if changed {
+		err = r.updateStatus(ctx, md)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return wait, nil
+}
+
+// rolloutDeferred returns whether a spec change waits for the maintenance
+// window
+func rolloutDeferred(md *kaimeraaiv1.ModelDeployment) bool {
+	return meta.IsStatusConditionTrue(md.Status.Conditions, kaimeraaiv1.ConditionRolloutDeferred)
+}
+
+func (r *ModelDeploymentReconciler) clearRolloutDeferred(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if !rolloutDeferred(md) {
+		return nil
+	}
+
+	meta.SetStatusCondition(&md.Status.Conditions, metav1.Condition{
+		Type:               kaimeraaiv1.ConditionRolloutDeferred,
+		Status:             metav1.ConditionFalse,
+		Reason:             "RolledOut",
+		Message:            "no change is waiting for the maintenance window",
+		ObservedGeneration: md.Generation,
+	})
+	return r.updateStatus(ctx, md)
+}
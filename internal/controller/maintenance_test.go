@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment maintenance window", func() {
+	ctx := context.Background()
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	It("should report how long until the window opens", func() {
+		window, err := parseMaintenanceWindow("01:00-03:00")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(window.untilOpen(at(0, 30))).To(Equal(30 * time.Minute))
+		Expect(window.untilOpen(at(2, 0))).To(BeZero())
+		Expect(window.untilOpen(at(3, 0))).To(Equal(22 * time.Hour))
+
+		By("wrapping around midnight")
+		window, err = parseMaintenanceWindow("22:00-04:00")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(window.untilOpen(at(23, 0))).To(BeZero())
+		Expect(window.untilOpen(at(1, 0))).To(BeZero())
+		Expect(window.untilOpen(at(12, 0))).To(Equal(10 * time.Hour))
+
+		_, err = parseMaintenanceWindow("nightly")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should defer a rollout until the window opens", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "maintenance",
+				Namespace:   "default",
+				Generation:  1,
+				Annotations: map[string]string{kaimeraaiv1.MaintenanceWindowAnnotation: "01:00-03:00"},
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+			},
+		}
+
+		fakeClock := clocktesting.NewFakePassiveClock(at(12, 0))
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+			Clock:  fakeClock,
+		}
+
+		By("deploying a new model straight away")
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dp := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		Expect(dp.Spec.Template.Spec.Containers[0].Command).To(ContainElement("facebook/opt-125m"))
+
+		By("deferring a spec change outside of the window")
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.ModelName = "facebook/opt-350m"
+		md.Spec.HighAvailability = true
+		md.Generation = 2
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(13 * time.Hour))
+
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		Expect(dp.Spec.Template.Spec.Containers[0].Command).To(ContainElement("facebook/opt-125m"))
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionRolloutDeferred)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(md.Status.ObservedGeneration).To(BeEquivalentTo(1))
+
+		By("still reconciling the rest of the children")
+		Expect(reconciler.Get(ctx, key, &policyv1.PodDisruptionBudget{})).To(Succeed())
+
+		By("rolling out the change once the window opens")
+		fakeClock.SetTime(at(2, 0).Add(24 * time.Hour))
+		result, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		Expect(dp.Spec.Template.Spec.Containers[0].Command).To(ContainElement("facebook/opt-350m"))
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond = meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionRolloutDeferred)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(md.Status.ObservedGeneration).To(BeEquivalentTo(2))
+	})
+})
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// Keys of the model manifest ConfigMap
+const (
+	manifestModelKey    = "model"
+	manifestImageKey    = "image"
+	manifestArgsKey     = "args"
+	manifestEndpointKey = "endpoint"
+)
+
+// reconcileManifest keeps a ConfigMap summarising the resolved deployment in
+// sync, for tooling that needs to know how a model is served without
+// interpreting the ModelDeployment spec itself.
+func (r *ModelDeploymentReconciler) reconcileManifest(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	cm, err := r.generateManifestConfigMap(md)
+	if err != nil {
+		return err
+	}
+
+	return r.applyConfigMap(ctx, cm)
+}
+
+func (r *ModelDeploymentReconciler) manifestConfigMapName(md *kaimeraaiv1.ModelDeployment) string {
+	return r.resourceName(md.Name + "-manifest")
+}
+
+// serviceEndpoint returns the in-cluster URL of the model's API
+func (r *ModelDeploymentReconciler) serviceEndpoint(md *kaimeraaiv1.ModelDeployment) string {
+	return fmt.Sprintf("http://%s.%s:%d", r.resourceName(md.Name), md.Namespace, md.Spec.ServicePort())
+}
+
+func (r *ModelDeploymentReconciler) generateManifestConfigMap(md *kaimeraaiv1.ModelDeployment) (*corev1.ConfigMap, error) {
+	deploy, err := r.generateDeployment(md)
+	if err != nil {
+		return nil, err
+	}
+	container := deploy.Spec.Template.Spec.Containers[0]
+
+	args, err := json.Marshal(container.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.manifestConfigMapName(md),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Data: map[string]string{
+			manifestModelKey:    md.Spec.ModelName,
+			manifestImageKey:    container.Image,
+			manifestArgsKey:     string(args),
+			manifestEndpointKey: r.serviceEndpoint(md),
+		},
+	}
+
+	err = ctrl.SetControllerReference(md, cm, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm, nil
+}
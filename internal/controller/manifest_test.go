@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment manifest", func() {
+	ctx := context.Background()
+
+	It("should reflect the resolved deployment and follow spec changes", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "manifest",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Runtime:   "gpu",
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		Expect(reconciler.reconcileManifest(ctx, md)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.manifestConfigMapName(md)}
+		Expect(reconciler.Get(ctx, key, cm)).To(Succeed())
+		Expect(metav1.IsControlledBy(cm, md)).To(BeTrue())
+		Expect(cm.Data).To(HaveKeyWithValue(manifestModelKey, "facebook/opt-125m"))
+		Expect(cm.Data).To(HaveKeyWithValue(manifestImageKey, "vllm/vllm-openai:latest"))
+		Expect(cm.Data).To(HaveKeyWithValue(manifestEndpointKey, "http://manifest.default:80"))
+
+		var args []string
+		Expect(json.Unmarshal([]byte(cm.Data[manifestArgsKey]), &args)).To(Succeed())
+		Expect(args).To(HaveExactElements("vllm", "serve", "--dtype", "auto", "facebook/opt-125m"))
+
+		By("updating it when the spec changes")
+		md.Spec.Port = 8080
+		md.Spec.LoadFormat = "safetensors"
+		Expect(reconciler.reconcileManifest(ctx, md)).To(Succeed())
+
+		Expect(reconciler.Get(ctx, key, cm)).To(Succeed())
+		Expect(cm.Data).To(HaveKeyWithValue(manifestEndpointKey, "http://manifest.default:8080"))
+		Expect(json.Unmarshal([]byte(cm.Data[manifestArgsKey]), &args)).To(Succeed())
+		Expect(args).To(ContainElements("--load-format", "safetensors", "--port", "8080"))
+	})
+})
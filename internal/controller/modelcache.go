@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// selectedNodeAnnotation is set on a WaitForFirstConsumer claim once a pod
+// using it has been scheduled, from when it gets provisioned
+const selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+// modelCacheBinding returns why the model cache claim is not bound yet, or
+// an empty string once it is bound or a consumer has been scheduled
+func (r *ModelDeploymentReconciler) modelCacheBinding(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (string, error) {
+	if md.Spec.ModelCache == nil {
+		return "", nil
+	}
+
+	pvc := corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: md.Spec.ModelCache.ClaimName}, &pvc)
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("waiting for model cache claim %q to be created", md.Spec.ModelCache.ClaimName), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if pvc.Status.Phase == corev1.ClaimBound || pvc.Annotations[selectedNodeAnnotation] != "" {
+		return "", nil
+	}
+
+	return fmt.Sprintf("waiting for model cache claim %q to be bound", pvc.Name), nil
+}
+
+// modelDeploymentsPerClaim maps a claim to the ModelDeployments caching
+// their model on it
+func (r *ModelDeploymentReconciler) modelDeploymentsPerClaim(ctx context.Context, obj client.Object) []reconcile.Request {
+	mds := kaimeraaiv1.ModelDeploymentList{}
+	err := r.List(ctx, &mds, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to list model deployments for claim event")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, md := range mds.Items {
+		if md.Spec.ModelCache != nil && md.Spec.ModelCache.ClaimName == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&md)})
+		}
+	}
+
+	return requests
+}
+
+// checkModelCacheStorageClass fails with a StorageClassNotFound reason when
+// the model cache claim asks for a StorageClass that doesn't exist, which
+// would otherwise leave the claim, and the pods, pending without a trace.
+func (r *ModelDeploymentReconciler) checkModelCacheStorageClass(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.ModelCache == nil {
+		return nil
+	}
+
+	pvc := corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: md.Spec.ModelCache.ClaimName}, &pvc)
+	if err != nil {
+		// A missing claim may still be created, the pods wait for it
+		return client.IgnoreNotFound(err)
+	}
+
+	className := pvc.Spec.StorageClassName
+	if className == nil || *className == "" {
+		return nil
+	}
+
+	err = r.Get(ctx, client.ObjectKey{Name: *className}, &storagev1.StorageClass{})
+	if errors.IsNotFound(err) {
+		return &degradedError{
+			reason:  "StorageClassNotFound",
+			message: fmt.Sprintf("storage class %q of model cache claim %q does not exist", *className, pvc.Name),
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment model cache", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+	var pvc *corev1.PersistentVolumeClaim
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "cached",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:  "facebook/opt-125m",
+				ModelCache: &kaimeraaiv1.ModelCacheSpec{ClaimName: "models"},
+			},
+		}
+
+		className := "fast-nvme"
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "models",
+				Namespace: "default",
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &className,
+			},
+		}
+	})
+
+	reconcileAndGetDegraded := func(objs ...client.Object) *metav1.Condition {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(append(objs, md)...).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+
+		return meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+	}
+
+	It("should report a missing storage class", func() {
+		cond := reconcileAndGetDegraded(pvc)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("StorageClassNotFound"))
+		Expect(cond.Message).To(ContainSubstring(`"fast-nvme"`))
+	})
+
+	It("should accept an existing storage class", func() {
+		storageClass := &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "fast-nvme"},
+			Provisioner: "example.com/nvme",
+		}
+
+		cond := reconcileAndGetDegraded(pvc, storageClass)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+	})
+})
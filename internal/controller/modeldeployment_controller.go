@@ -3,29 +3,189 @@ package controller
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
 )
 
+const (
+	// topologyPolicyLabel marks nodes whose kubelet topology manager policy
+	// guarantees NUMA aligned device allocation.
+	topologyPolicyLabel   = "kaimera.ai/topology-manager-policy"
+	topologyPolicyAligned = "single-numa-node"
+
+	// modelDeploymentLabel is set on the model pods to the name of their
+	// ModelDeployment, to tell them from other pods with the same app label
+	modelDeploymentLabel = "kaimera.ai/model-deployment"
+
+	// defaultGPUProductLabel is set by NVIDIA GPU feature discovery
+	defaultGPUProductLabel = "nvidia.com/gpu.product"
+
+	defaultDiscoveryTagAnnotation   = "kaimera.ai/discovery-tag"
+	defaultDiscoveryModelAnnotation = "kaimera.ai/served-model-name"
+
+	// arm64TagSuffix marks the arm64 builds of the runtime images
+	arm64TagSuffix = "-arm64"
+
+	kueueQueueNameLabel = "kueue.x-k8s.io/queue-name"
+	kueueAdmissionGate  = "kueue.x-k8s.io/admission"
+
+	// servingPort is the port vLLM serves both its API and /metrics on. The
+	// Service targets it by name so it stays correct if the number changes.
+	servingPort     = 8000
+	servingPortName = "http"
+
+	// defaultContainerName names the model server container. The pods are
+	// selected by the app label, so it can change without a new selector.
+	defaultContainerName = "model-server"
+
+	modelCacheVolume    = "model-cache"
+	modelCacheMountPath = "/root/.cache/huggingface"
+)
+
+// scratchDirs are the paths vLLM needs to write to. They are backed by empty
+// dirs when the root filesystem is read-only.
+var scratchDirs = []struct {
+	volume string
+	path   string
+}{
+	{volume: "tmp", path: "/tmp"},
+	{volume: "cache", path: "/root/.cache"},
+	{volume: "config", path: "/root/.config"},
+	{volume: "triton-cache", path: "/root/.triton"},
+}
+
 // ModelDeploymentReconciler reconciles a ModelDeployment object
 type ModelDeploymentReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Clock stamps times recorded in status. Defaults to the real clock.
+	Clock clock.PassiveClock
+
+	// RateLimiterBaseDelay and RateLimiterMaxDelay bound the exponential
+	// backoff of failed reconciles. The controller-runtime defaults are used
+	// when unset.
+	RateLimiterBaseDelay time.Duration
+	RateLimiterMaxDelay  time.Duration
+
+	// DiscoveryTagAnnotation and DiscoveryModelAnnotation are the Service
+	// annotation keys carrying the discovery tag and served model name of
+	// ModelDeployments that enable discovery. Defaults are used when unset.
+	DiscoveryTagAnnotation   string
+	DiscoveryModelAnnotation string
+
+	// Recorder emits events on ModelDeployments. Events are dropped when
+	// unset.
+	Recorder record.EventRecorder
+
+	// ResourceNamePrefix is prepended to the names of the resources
+	// generated for ModelDeployments, e.g. for policies to target them
+	ResourceNamePrefix string
+
+	// NodePoolLabel is the node label the node autoscaler sets to the name
+	// of the pool a node belongs to, selected by ModelDeployments setting a
+	// node pool. Defaults to karpenter.sh/nodepool.
+	NodePoolLabel string
+
+	// NodePoolTaint is the key of the taint on the nodes of a pool, with the
+	// pool name as value, tolerated by ModelDeployments setting a node pool.
+	// No taint is tolerated when unset.
+	NodePoolTaint string
+
+	// DefaultPriorityClass is the priority class of the pods of
+	// ModelDeployments setting evictionPriority without a class, or high
+	// availability. The pods keep the cluster's default priority when unset.
+	DefaultPriorityClass string
+
+	// AllowNodeRemediation lets ModelDeployments cordon or taint the nodes
+	// of their wedged GPUs. The nodes are only reported when unset.
+	AllowNodeRemediation bool
+
+	// AllowHostPID lets ModelDeployments run in the host PID namespace.
+	// ModelDeployments setting hostPID are degraded when unset.
+	AllowHostPID bool
+
+	// AllowGPUTuning lets ModelDeployments tune their GPUs from a privileged
+	// init container. ModelDeployments setting gpuTuning are degraded when
+	// unset.
+	AllowGPUTuning bool
+
+	// AllowMPS lets ModelDeployments share GPUs through the MPS control
+	// daemons of the nodes. ModelDeployments setting mps are degraded when
+	// unset.
+	AllowMPS bool
+
+	// MPSPipeDirectory is the node directory the MPS control daemons listen
+	// in, mounted into the pods of ModelDeployments setting mps. Defaults to
+	// /tmp/nvidia-mps.
+	MPSPipeDirectory string
+
+	// AllowedImageRegistries are the registries, optionally with a path,
+	// the images of generated pods must be pulled from. Any registry is
+	// allowed when empty.
+	AllowedImageRegistries []string
+
+	// Validator validates the specs ModelDeployments resolve from their
+	// template, as the webhook only sees the spec of a ModelDeployment with
+	// the template it has at the time. They aren't validated when nil.
+	Validator *kaimeraaiv1.ModelDeploymentValidator
+
+	// TracerProvider records a span for each reconcile and the operations
+	// on its children. Defaults to the global provider, which records
+	// nothing unless set.
+	TracerProvider trace.TracerProvider
 }
 
 // +kubebuilder:rbac:groups=kaimera.ai,resources=modeldeployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kaimera.ai,resources=modeldeployments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kaimera.ai,resources=modeldeployments/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kaimera.ai,resources=modeldeploymenttemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=list;create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -37,6 +197,24 @@ type ModelDeploymentReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.18.4/pkg/reconcile
 func (r *ModelDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// The manager cancels ctx on shutdown while it waits for in-flight
+	// reconciles, which still get to finish their writes rather than leave
+	// the children half applied
+	ctx = context.WithoutCancel(ctx)
+	ctx, span := r.tracer().Start(ctx, "Reconcile", trace.WithAttributes(
+		namespaceAttribute.String(req.Namespace),
+		nameAttribute.String(req.Name),
+	))
+
+	result, err := r.reconcile(ctx, req)
+	span.SetAttributes(requeueAfterAttribute.String(result.RequeueAfter.String()))
+	endSpan(span, err)
+	return result, err
+}
+
+// reconcile brings the children of the ModelDeployment named by req in line
+// with its spec
+func (r *ModelDeploymentReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
 	logger.Info("in reconcile")
@@ -47,105 +225,925 @@ func (r *ModelDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	err = r.resolveTemplate(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, r.reconcileDegraded(ctx, &md, err)
+	}
+
 	logger.Info("in reconcile got model deployment with model", "model", md.Spec.ModelName)
 
+	registered, err := r.reconcileRequiredTypes(ctx, &md)
+	if err != nil || !registered {
+		return ctrl.Result{}, err
+	}
+
 	dp := appsv1.Deployment{}
-	err = r.Get(ctx, req.NamespacedName, &dp)
+	err = r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.Name)}, &dp)
 	logger.Info("in reconcile got deployment", "deployment", dp.Name)
-	if err != nil {
-
-		// Create new deployment
-		logger.Info("creating deployment")
-		// Insert func
-		deploy, err := r.generateDeployment(&md)
+	exists := err == nil
+	switch md.Spec.WorkloadType {
+	case kaimeraaiv1.WorkloadTypeRollout:
+		exists, err = r.getRollout(ctx, &md, &dp)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+	case kaimeraaiv1.WorkloadTypeStatefulSet:
+		exists, err = r.getStatefulSet(ctx, &md, &dp)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
-		err = r.Create(ctx, deploy)
+	if md.Spec.ReplicasPerNode > 0 {
+		err = r.reconcileNodeReplicas(ctx, &md)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+	}
 
-		svc, err := r.generateService(&md)
+	if md.Spec.ExclusiveNode {
+		err = r.reconcileNodeGPUs(ctx, &md)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+	}
 
-		err = r.Create(ctx, svc)
+	rightsizingWait, err := r.reconcileRightsizing(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	current := &dp
+	if !exists {
+		current = nil
+	}
+
+	if md.Spec.RampUp {
+		err = r.reconcileRampUp(ctx, &md, current)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-	} else {
-		// Update an existing deployment
-		deploy, err := r.generateDeployment(&md)
+	}
+
+	var maintenanceWait time.Duration
+	if exists {
+		maintenanceWait, err = r.reconcileMaintenanceWindow(ctx, &md)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+	}
+
+	err = r.reconcileChatTemplate(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	fallbackWait, err := r.reconcileCPUFallback(ctx, &md, current)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileChildren(ctx, &md, current)
+	// A version whose pods crash or can't pull their image is reported as
+	// degraded below, so it is rolled back first. Pods on wedged GPUs crash
+	// loop too, so their nodes are remediated first as well.
+	var rollbackWait time.Duration
+	if err == nil {
+		rollbackWait, err = r.reconcileAutoRollback(ctx, &md)
+	}
+	if err == nil {
+		err = r.reconcileWedgedGPUs(ctx, &md)
+	}
+	if err == nil && exists {
+		err = r.checkModelChecksum(ctx, &md)
+	}
+	if err == nil && exists {
+		err = r.checkCrashLoopBackOff(ctx, &md)
+	}
+	if err == nil && exists {
+		err = r.checkImagePull(ctx, &md)
+	}
+	err = r.reconcileDegraded(ctx, &md, err)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
-		err = r.Update(ctx, deploy)
+	err = r.reconcileAutoscaling(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcilePodDisruptionBudget(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileEgressPolicy(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileShadow(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcilePrefixCache(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileConnectionSecret(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileManifest(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileDashboard(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileServiceMonitor(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if md.Spec.StrictZoneBalance {
+		err = r.reconcileZoneReplicas(ctx, &md)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+	}
 
-		svc, err := r.generateService(&md)
+	if md.Spec.PruneReplicaSets && exists {
+		err = r.pruneReplicaSets(ctx, &md, &dp)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+	}
+
+	err = r.reconcileWarmPool(ctx, &md, &dp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileReadyTime(ctx, &md, &dp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
-		err = r.Update(ctx, svc)
+	err = r.reconcileReady(ctx, &md, &dp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileLoRAAdapters(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcileConfigDrift(ctx, &md)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The smoke test runs once per generation, on the pods of that generation
+	if md.Spec.SmokeTest && !rolloutDeferred(&md) {
+		err = r.reconcileSmokeTest(ctx, &md, &dp)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: shortestWait(fallbackWait, rightsizingWait, rollbackWait, maintenanceWait)}, nil
+}
+
+// reconcileChildren creates or updates the Deployment and Service serving the
+// model. current is the existing Deployment, or nil if there is none yet.
+func (r *ModelDeploymentReconciler) reconcileChildren(ctx context.Context, md *kaimeraaiv1.ModelDeployment, current *appsv1.Deployment) error {
+	logger := log.FromContext(ctx)
+
+	err := r.checkModelCacheStorageClass(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	err = r.checkEntrypointConfigMap(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	// Outside of the maintenance window the workload keeps running the
+	// previous spec, and its Services keep matching its pods
+	if current != nil && rolloutDeferred(md) {
+		return nil
+	}
+
+	switch md.Spec.WorkloadType {
+	case kaimeraaiv1.WorkloadTypeRollout:
+		return r.reconcileRollout(ctx, md, current)
+	case kaimeraaiv1.WorkloadTypeStatefulSet:
+		return r.reconcileStatefulSet(ctx, md, current)
+	}
+
+	if current == nil {
+		// Create new deployment
+		logger.Info("creating deployment")
+		// Insert func
+		deploy, err := r.generateDeployment(md)
+		if err != nil {
+			return err
+		}
+		setChangeCause(deploy, current, md)
+		setSpecHash(deploy, current, md)
+
+		err = r.checkImageRegistries(deploy)
+		if err != nil {
+			return err
+		}
+		err = r.checkGPUQuota(ctx, md, deploy)
+		if err != nil {
+			return err
+		}
+		r.warnLargeImages(md, deploy)
+
+		err = r.create(ctx, deploy)
+		if err != nil {
+			return err
+		}
+
+		svc, err := r.generateService(md)
+		if err != nil {
+			return err
+		}
+
+		// The Service is left over when the model moves back from a Rollout
+		err = r.apply(ctx, svc, &corev1.Service{})
+		if err != nil {
+			return err
+		}
+	} else {
+		// Update an existing deployment
+		deploy, err := r.generateDeployment(md)
+		if err != nil {
+			return err
+		}
+		setChangeCause(deploy, current, md)
+		setSpecHash(deploy, current, md)
+		preserveAutoscaledReplicas(deploy, current, md)
+
+		err = r.checkImageRegistries(deploy)
+		if err != nil {
+			return err
+		}
+		err = r.update(ctx, deploy)
+		if err != nil {
+			return err
+		}
+
+		svc, err := r.generateService(md)
+		if err != nil {
+			return err
+		}
+
+		err = r.update(ctx, svc)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The Deployment replaces the Rollout or StatefulSet of the model once
+	// it serves in their place
+	if workloadAvailable(current) {
+		err = r.deleteRollout(ctx, md)
+		if err != nil {
+			return err
+		}
+		err = r.deleteStatefulSet(ctx, md)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// workloadAvailable returns whether all replicas of current, the Deployment
+// or the stand-in of the workload running the model, are available. The
+// workload of another type it replaces is deleted only then, so the model
+// keeps serving while its workload type changes.
+func workloadAvailable(current *appsv1.Deployment) bool {
+	if current == nil {
+		return false
+	}
+
+	replicas := int32(1)
+	if current.Spec.Replicas != nil {
+		replicas = *current.Spec.Replicas
+	}
+	return current.Status.AvailableReplicas >= replicas
+}
+
+// resourceName returns the name of a generated resource from its unprefixed
+// name, e.g. md.Name for the Deployment and Service
+func (r *ModelDeploymentReconciler) resourceName(name string) string {
+	return kaimeraaiv1.PrefixedName(r.ResourceNamePrefix, name)
+}
+
+// apply creates obj, or updates the existing object read into current. An
+// existing object controlled by someone else, e.g. created by hand with the
+// same name, is never taken over.
+func (r *ModelDeploymentReconciler) apply(ctx context.Context, obj, current client.Object) (err error) {
+	ctx, span := r.startObjectSpan(ctx, "apply", obj)
+	defer func() { endSpan(span, err) }()
+
+	err = r.Get(ctx, client.ObjectKeyFromObject(obj), current)
+	if errors.IsNotFound(err) {
+		log.FromContext(ctx).Info("creating object", "name", obj.GetName())
+		span.SetAttributes(operationAttribute.String("create"))
+		return r.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	if owner := metav1.GetControllerOfNoCopy(obj); owner != nil {
+		if controller := metav1.GetControllerOfNoCopy(current); controller == nil || controller.UID != owner.UID {
+			return &degradedError{reason: "ResourceConflict", message: fmt.Sprintf(
+				"%q already exists and is not controlled by %s %q", obj.GetName(), owner.Kind, owner.Name)}
+		}
+	}
+
+	obj.SetResourceVersion(current.GetResourceVersion())
+	span.SetAttributes(operationAttribute.String("update"))
+	return r.Update(ctx, obj)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ModelDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kaimeraaiv1.ModelDeployment{}).
-		Complete(r)
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			RateLimiter: r.rateLimiter(),
+			NewQueue:    r.newQueue,
+		}).
+		For(&kaimeraaiv1.ModelDeployment{}, builder.WithPredicates(modelDeploymentPredicate())).
+		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.Secret{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Watches(&kaimeraaiv1.ModelDeploymentTemplate{},
+			handler.EnqueueRequestsFromMapFunc(r.modelDeploymentsPerTemplate)).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.modelDeploymentsPerSecret)).
+		Watches(&corev1.PersistentVolumeClaim{},
+			handler.EnqueueRequestsFromMapFunc(r.modelDeploymentsPerClaim)).
+		Watches(&corev1.Node{},
+			handler.EnqueueRequestsFromMapFunc(r.modelDeploymentsPerNode),
+			builder.WithPredicates(nodeCountPredicate())).
+		Watches(&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.modelDeploymentPerPod),
+			builder.WithPredicates(podWaitingPredicate()))
+
+	// Rollouts can only be watched on clusters with Argo Rollouts installed
+	_, err := mgr.GetRESTMapper().RESTMapping(rolloutGVK.GroupKind(), rolloutGVK.Version)
+	if err == nil {
+		bldr = bldr.Owns(newRollout())
+	}
+
+	return bldr.Complete(r)
+}
+
+// modelDeploymentPredicate filters ModelDeployment events down to spec and
+// annotation changes, e.g. of the maintenance window, so the controller's
+// own status writes don't trigger another reconcile. Owned objects are not
+// filtered, so child changes still do.
+func modelDeploymentPredicate() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
+// rateLimiter returns the workqueue rate limiter for the configured backoff,
+// or nil to use the controller-runtime default. Like the default, it also
+// applies an overall 10 qps limit.
+func (r *ModelDeploymentReconciler) rateLimiter() workqueue.RateLimiter {
+	if r.RateLimiterBaseDelay == 0 && r.RateLimiterMaxDelay == 0 {
+		return nil
+	}
+
+	baseDelay := r.RateLimiterBaseDelay
+	if baseDelay == 0 {
+		baseDelay = 5 * time.Millisecond
+	}
+	maxDelay := r.RateLimiterMaxDelay
+	if maxDelay == 0 {
+		maxDelay = 1000 * time.Second
+	}
+
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// now returns the current time of the reconciler's clock
+func (r *ModelDeploymentReconciler) now() metav1.Time {
+	if r.Clock == nil {
+		return metav1.Now()
+	}
+
+	return metav1.NewTime(r.Clock.Now())
+}
+
+// shortestWait returns the shortest of the waits that are set, or 0 if none
+// is
+func shortestWait(waits ...time.Duration) time.Duration {
+	var shortest time.Duration
+	for _, wait := range waits {
+		if wait > 0 && (shortest == 0 || wait < shortest) {
+			shortest = wait
+		}
+	}
+
+	return shortest
+}
+
+// warningEvent emits a Warning event on the ModelDeployment
+func (r *ModelDeploymentReconciler) warningEvent(md *kaimeraaiv1.ModelDeployment, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+
+	r.Recorder.Event(md, corev1.EventTypeWarning, reason, message)
+}
+
+// discoveryAnnotations returns the Service annotations external registries
+// discover the model by
+func (r *ModelDeploymentReconciler) discoveryAnnotations(md *kaimeraaiv1.ModelDeployment) map[string]string {
+	tagKey := r.DiscoveryTagAnnotation
+	if tagKey == "" {
+		tagKey = defaultDiscoveryTagAnnotation
+	}
+	modelKey := r.DiscoveryModelAnnotation
+	if modelKey == "" {
+		modelKey = defaultDiscoveryModelAnnotation
+	}
+
+	return map[string]string{
+		tagKey:   md.Spec.Discovery.Tag,
+		modelKey: md.Spec.ModelName,
+	}
+}
+
+// generateNodeSelector returns the node selector of the model pods: the
+// configured labels plus the architecture and node pool, if set
+func (r *ModelDeploymentReconciler) generateNodeSelector(md *kaimeraaiv1.ModelDeployment) map[string]string {
+	if md.Spec.Architecture == "" && md.Spec.NodePool == "" {
+		return md.Spec.NodeSelectorLabels
+	}
+
+	nodeSelector := map[string]string{}
+	for k, v := range md.Spec.NodeSelectorLabels {
+		nodeSelector[k] = v
+	}
+	if md.Spec.Architecture != "" {
+		nodeSelector[corev1.LabelArchStable] = md.Spec.Architecture
+	}
+	if md.Spec.NodePool != "" {
+		nodeSelector[r.nodePoolLabel()] = md.Spec.NodePool
+	}
+
+	return nodeSelector
+}
+
+// gpuProductLabel returns the node label holding the GPU model
+func gpuProductLabel(md *kaimeraaiv1.ModelDeployment) string {
+	if md.Spec.GPUProductLabel != "" {
+		return md.Spec.GPUProductLabel
+	}
+
+	return defaultGPUProductLabel
+}
+
+// deploymentReady reports whether all desired replicas of the deployment are
+// ready to serve traffic.
+func deploymentReady(dp *appsv1.Deployment) bool {
+	if dp.Spec.Replicas == nil {
+		return false
+	}
+
+	return dp.Status.ReadyReplicas > 0 && dp.Status.ReadyReplicas >= *dp.Spec.Replicas
 }
 
 func (r *ModelDeploymentReconciler) generateDeployment(md *kaimeraaiv1.ModelDeployment) (*appsv1.Deployment, error) {
+	// The webhook rejects these too, but it may not be deployed
+	if md.Spec.HostPID && !r.AllowHostPID {
+		return nil, &degradedError{reason: "HostPIDNotAllowed", message: "hostPID is not allowed on this cluster"}
+	}
+	if md.Spec.GPUTuning != nil && md.Spec.Runtime == "gpu" && !r.AllowGPUTuning {
+		return nil, &degradedError{reason: "GPUTuningNotAllowed", message: "GPU tuning is not allowed on this cluster"}
+	}
+	if mpsEnabled(md) && !r.AllowMPS {
+		return nil, &degradedError{reason: "MPSNotAllowed", message: "MPS is not allowed on this cluster"}
+	}
 
 	if md.Spec.Replicas == 0 {
 		md.Spec.Replicas = 1
 	}
-
-	var image string
-	maxModelLength := 512
-	if md.Spec.MaxModelLength > 0 {
-		maxModelLength = int(md.Spec.MaxModelLength)
+	replicas := desiredReplicas(md)
+	if md.Spec.RampUp && md.Status.RampReplicas > 0 {
+		replicas = md.Status.RampReplicas
 	}
+	if md.Spec.Autoscaling != nil {
+		replicas = minReplicas(md)
+	}
+
+	image := md.Spec.RuntimeImage()
 	var tolerations []corev1.Toleration
 	var limits corev1.ResourceList
-	if md.Spec.Runtime == "" || md.Spec.Runtime == "cpu" {
-		image = "patnaikshekhar/vllm-cpu:1"
-	} else if md.Spec.Runtime == "gpu" {
-		image = "vllm/vllm-openai:latest"
+	var nodeRequirements []corev1.NodeSelectorRequirement
+	if md.Spec.Runtime == "gpu" {
 		tolerations = []corev1.Toleration{
 			{
-				Key:      "nvidia.com/gpu",
+				Key:      md.Spec.GPUToleration(),
 				Operator: "Exists",
 				Effect:   "NoSchedule",
 			},
 		}
 
 		limits = corev1.ResourceList{
-			"nvidia.com/gpu": resource.MustParse("1"),
+			md.Spec.GPUResource(): *resource.NewQuantity(int64(requestedGPUs(md)), resource.DecimalSI),
+		}
+
+		if md.Spec.GPUTopologyAware {
+			nodeRequirements = append(nodeRequirements, corev1.NodeSelectorRequirement{
+				Key:      topologyPolicyLabel,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{topologyPolicyAligned},
+			})
+		}
+		if md.Spec.GPUProduct != "" {
+			nodeRequirements = append(nodeRequirements, corev1.NodeSelectorRequirement{
+				Key:      gpuProductLabel(md),
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{md.Spec.GPUProduct},
+			})
 		}
+	}
+	tolerations = append(tolerations, r.generateNodePoolTolerations(md)...)
+	nodeRequirements = append(nodeRequirements, generateKarpenterRequirements(md)...)
+
+	if md.Spec.Architecture == "arm64" {
+		image += arm64TagSuffix
+	}
+
+	if md.Spec.ImageDigest != "" {
+		image = pinImageDigest(image, md.Spec.ImageDigest)
+	}
 
+	command := []string{
+		"vllm",
+		"serve",
+		"--dtype",
+		"auto",
+		md.Spec.ModelName,
+	}
+	if md.Spec.MaxModelLength > 0 {
+		command = append(command, "--max-model-len", fmt.Sprintf("%d", md.Spec.MaxModelLength))
+	}
+	if md.Spec.MaxSeqLenToCapture > 0 {
+		command = append(command, "--max-seq-len-to-capture", fmt.Sprintf("%d", md.Spec.MaxSeqLenToCapture))
+	}
+	if md.Spec.LoadFormat != "" {
+		command = append(command, "--load-format", md.Spec.LoadFormat)
+	}
+	// An explicit size wins over the GPU count, e.g. when a template turns
+	// on autoTensorParallel
+	if md.Spec.TensorParallelSize > 0 {
+		command = append(command, "--tensor-parallel-size", fmt.Sprintf("%d", md.Spec.TensorParallelSize))
+	} else if gpus := requestedGPUs(md); md.Spec.AutoTensorParallel && gpus > 0 {
+		command = append(command, "--tensor-parallel-size", fmt.Sprintf("%d", gpus))
+	}
+	if md.Spec.PipelineParallelSize > 0 {
+		command = append(command, "--pipeline-parallel-size", fmt.Sprintf("%d", md.Spec.PipelineParallelSize))
+	}
+	if md.Spec.GPUMemoryUtilization != "" {
+		command = append(command, "--gpu-memory-utilization", md.Spec.GPUMemoryUtilization)
+	}
+	if md.Spec.MaxConcurrentRequests > 0 {
+		command = append(command, "--max-num-seqs", fmt.Sprintf("%d", md.Spec.MaxConcurrentRequests))
+	}
+	if md.Spec.CPUOffloadGB > 0 {
+		command = append(command, "--cpu-offload-gb", fmt.Sprintf("%d", md.Spec.CPUOffloadGB))
+	}
+	containerPort := int32(servingPort)
+	if md.Spec.Port > 0 {
+		containerPort = md.Spec.Port
+		command = append(command, "--port", fmt.Sprintf("%d", md.Spec.Port))
+	}
+	if md.Spec.DistributedExecutorBackend != "" {
+		command = append(command, "--distributed-executor-backend", md.Spec.DistributedExecutorBackend)
+	}
+	if md.Spec.Tracing != nil {
+		command = append(command, "--otlp-traces-endpoint", md.Spec.Tracing.Endpoint)
+	}
+	if md.Spec.RopeScaling != "" {
+		command = append(command, "--rope-scaling", md.Spec.RopeScaling)
+	}
+	if md.Spec.RopeTheta > 0 {
+		command = append(command, "--rope-theta", fmt.Sprintf("%d", md.Spec.RopeTheta))
+	}
+	if md.Spec.ChatTemplate != "" {
+		command = append(command, "--chat-template", path.Join(chatTemplateMountPath, chatTemplateKey))
+	}
+	if md.Spec.ResponseRole != "" {
+		command = append(command, "--response-role", md.Spec.ResponseRole)
+	}
+	if md.Spec.PrefixCache != nil {
+		command = append(command, "--kv-transfer-config", prefixCacheKVTransfer)
+	}
+	if md.Spec.AuditLogging != nil {
+		command = append(command, "--enable-log-requests", "--enable-log-outputs")
+	}
+	command = append(command, loraArgs(md)...)
+
+	var requests corev1.ResourceList
+	var priorityClassName string
+	if md.Spec.EvictionPriority != nil {
+		priorityClassName = md.Spec.EvictionPriority.PriorityClassName
+		if priorityClassName == "" {
+			priorityClassName = r.DefaultPriorityClass
+		}
+
+		// Requests equal to limits for CPU and memory make the pod
+		// Guaranteed, the last QoS class to be evicted
+		guaranteed := corev1.ResourceList{
+			corev1.ResourceCPU:    md.Spec.EvictionPriority.CPU,
+			corev1.ResourceMemory: md.Spec.EvictionPriority.Memory,
+		}
+		for name, quantity := range limits {
+			guaranteed[name] = quantity
+		}
+		limits = guaranteed
+		requests = guaranteed.DeepCopy()
+	}
+	if md.Spec.CPUOffloadGB > 0 && md.Spec.EvictionPriority == nil {
+		// The offloaded weights live in host memory, which the node must
+		// have free for the pod
+		requests = corev1.ResourceList{corev1.ResourceMemory: md.Spec.CPUOffloadMemory()}
+	}
+	if md.Spec.HighAvailability && priorityClassName == "" {
+		priorityClassName = r.DefaultPriorityClass
+	}
+
+	startupProbe, readinessProbe, livenessProbe := generateProbes(md, containerPort)
+
+	var claims []corev1.ResourceClaim
+	for _, claim := range md.Spec.ResourceClaims {
+		claims = append(claims, corev1.ResourceClaim{Name: claim.Name})
+	}
+
+	var env []corev1.EnvVar
+	if md.Spec.APIKeySecretRef != nil {
+		env = append(env, corev1.EnvVar{
+			Name: "VLLM_API_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: md.Spec.APIKeySecretRef,
+			},
+		})
+	}
+	if md.Spec.Offline {
+		env = append(env,
+			corev1.EnvVar{Name: "HF_HUB_OFFLINE", Value: "1"},
+			corev1.EnvVar{Name: "TRANSFORMERS_OFFLINE", Value: "1"},
+		)
+	}
+	if md.Spec.DownloadConcurrency > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  "HF_XET_NUM_CONCURRENT_RANGE_GETS",
+			Value: fmt.Sprintf("%d", md.Spec.DownloadConcurrency),
+		})
 	}
+	if md.Spec.CUDAVisibleDevices != "" {
+		env = append(env, corev1.EnvVar{Name: "CUDA_VISIBLE_DEVICES", Value: md.Spec.CUDAVisibleDevices})
+	}
+	if md.Spec.PrefixCache != nil {
+		env = append(env, r.prefixCacheEnv(md)...)
+	}
+	if loraEnabled(md) && md.Spec.LoRAHotReload {
+		env = append(env, corev1.EnvVar{Name: "VLLM_ALLOW_RUNTIME_LORA_UPDATING", Value: "True"})
+	}
+	if md.Spec.Tracing != nil {
+		serviceName := md.Spec.Tracing.ServiceName
+		if serviceName == "" {
+			serviceName = md.Name
+		}
+		env = append(env,
+			corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: md.Spec.Tracing.Endpoint},
+			corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: serviceName},
+		)
+	}
+
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	var podSecurityContext *corev1.PodSecurityContext
+	if md.Spec.ModelCache != nil {
+		changePolicy := md.Spec.ModelCache.FSGroupChangePolicy
+		if changePolicy == "" {
+			changePolicy = corev1.FSGroupChangeOnRootMismatch
+		}
+		podSecurityContext = &corev1.PodSecurityContext{
+			FSGroup:             md.Spec.ModelCache.FSGroup,
+			FSGroupChangePolicy: &changePolicy,
+		}
+
+		volumes = append(volumes, corev1.Volume{
+			Name: modelCacheVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: md.Spec.ModelCache.ClaimName,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      modelCacheVolume,
+			MountPath: modelCacheMountPath,
+			SubPath:   cacheSubPath(md),
+		})
+		if loraEnabled(md) {
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      modelCacheVolume,
+				MountPath: loraMountPath,
+				ReadOnly:  true,
+			})
+		}
+	} else if md.Spec.ModelChecksum != "" {
+		// The runtime loads the weights verified by the download init
+		// container from a cache shared with it
+		volumes = append(volumes, corev1.Volume{
+			Name: modelCacheVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      modelCacheVolume,
+			MountPath: modelCacheMountPath,
+		})
+	}
+	var initContainers []corev1.Container
+	if md.Spec.ModelChecksum != "" {
+		initContainers = append(initContainers, generateModelDownload(md, image, env, volumeMounts))
+		// Keep the runtime from fetching newer weights than those verified
+		if !md.Spec.Offline {
+			env = append(env, corev1.EnvVar{Name: "HF_HUB_OFFLINE", Value: "1"})
+		}
+	}
+	if len(md.Spec.Sysctls) > 0 {
+		if podSecurityContext == nil {
+			podSecurityContext = &corev1.PodSecurityContext{}
+		}
+		podSecurityContext.Sysctls = md.Spec.Sysctls
+	}
+	if md.Spec.ChatTemplate != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: chatTemplateVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: r.chatTemplateConfigMapName(md)},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      chatTemplateVolume,
+			MountPath: chatTemplateMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	if md.Spec.Entrypoint != nil {
+		volumes = append(volumes, generateEntrypointVolume(md))
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      entrypointVolume,
+			MountPath: entrypointMountPath,
+			ReadOnly:  true,
+		})
+		command = append([]string{"/bin/sh", path.Join(entrypointMountPath, entrypointKey(md))}, command...)
+	}
+
+	if mpsEnabled(md) {
+		mpsEnv, volume, mount := r.generateMPS(md)
+		env = append(env, mpsEnv...)
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
+	}
+
+	var lifecycle *corev1.Lifecycle
+	var terminationGracePeriod *int64
+	if md.Spec.GracefulDrain != nil {
+		lifecycle, terminationGracePeriod = generateDrain(md, containerPort)
+	}
+
+	var logShipper *corev1.Container
+	if md.Spec.Logging != nil && md.Spec.Logging.Sidecar != nil {
+		sidecar, err := generateLogShipper(md)
+		if err != nil {
+			return nil, err
+		}
+		logShipper = &sidecar
+	}
+	// The volume holds the fifo the runtime's output is teed from, and
+	// the log file the log shipper tails along with its read offset
+	if logShipper != nil || md.Spec.AuditLogging != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: logVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &logVolumeSizeLimit},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      logVolume,
+			MountPath: logMountPath,
+		})
+	}
+
+	var auditLogRotator *corev1.Container
+	if md.Spec.AuditLogging != nil {
+		rotator := generateAuditLogRotator(md)
+		auditLogRotator = &rotator
+
+		volume, mount := generateAuditLogVolume(md)
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
+		env = append(env, podNameEnv)
+	}
+
+	switch {
+	case logShipper != nil && auditLogRotator != nil:
+		command = teeLogCommand(command, logFifo, logFile, logFile, auditLogFile)
+	case logShipper != nil:
+		command = teeLogCommand(command, logFifo, logFile, logFile)
+	case auditLogRotator != nil:
+		command = teeLogCommand(command, logFifo, "", auditLogFile)
+	}
+
+	var securityContext *corev1.SecurityContext
+	if md.Spec.ReadOnlyRootFilesystem {
+		readOnly := true
+		securityContext = &corev1.SecurityContext{
+			ReadOnlyRootFilesystem: &readOnly,
+		}
+
+		for _, dir := range scratchDirs {
+			volumes = append(volumes, corev1.Volume{
+				Name: dir.volume,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      dir.volume,
+				MountPath: dir.path,
+			})
+		}
+	}
+
+	var labels map[string]string
+	podLabels := map[string]string{
+		"app":                md.Name,
+		modelDeploymentLabel: md.Name,
+	}
+	var schedulingGates []corev1.PodSchedulingGate
+	schedulingGates = append(schedulingGates, md.Spec.SchedulingGates...)
+	if md.Spec.Kueue != nil {
+		labels = map[string]string{
+			kueueQueueNameLabel: md.Spec.Kueue.QueueName,
+		}
+		podLabels[kueueQueueNameLabel] = md.Spec.Kueue.QueueName
+
+		if md.Spec.Kueue.Suspend {
+			schedulingGates = append(schedulingGates, corev1.PodSchedulingGate{Name: kueueAdmissionGate})
+		}
+	}
+
 	deploy := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      md.Name,
-			Namespace: md.Namespace,
+			Name:        r.resourceName(md.Name),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, labels),
+			Annotations: childAnnotations(md, nil),
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &md.Spec.Replicas,
+			Replicas:             &replicas,
+			RevisionHistoryLimit: md.Spec.RevisionHistoryLimit,
+			Strategy:             generateStrategy(md),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"app": md.Name,
@@ -153,37 +1151,70 @@ func (r *ModelDeploymentReconciler) generateDeployment(md *kaimeraaiv1.ModelDepl
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": md.Name,
-					},
+					Labels:      podLabels,
+					Annotations: generatePodAnnotations(md),
 				},
 				Spec: corev1.PodSpec{
-					NodeSelector: md.Spec.NodeSelectorLabels,
+					NodeSelector:                  r.generateNodeSelector(md),
+					SchedulerName:                 md.Spec.SchedulerName,
+					Hostname:                      md.Spec.Hostname,
+					Subdomain:                     md.Spec.Subdomain,
+					SecurityContext:               podSecurityContext,
+					PriorityClassName:             priorityClassName,
+					RestartPolicy:                 md.Spec.RestartPolicy,
+					HostPID:                       md.Spec.HostPID,
+					HostIPC:                       mpsEnabled(md),
+					TerminationGracePeriodSeconds: terminationGracePeriod,
 					Containers: []corev1.Container{
 						{
-							Name:            "app",
+							Name:            containerName(md),
 							Image:           image,
 							ImagePullPolicy: "IfNotPresent",
-							Command: []string{
-								"vllm",
-								"serve",
-								"--dtype",
-								"auto",
-								"--max-model-len",
-								fmt.Sprintf("%d", maxModelLength),
-								md.Spec.ModelName,
+							Command:         command,
+							Env:             env,
+							VolumeMounts:    volumeMounts,
+							SecurityContext: securityContext,
+							StartupProbe:    startupProbe,
+							ReadinessProbe:  readinessProbe,
+							LivenessProbe:   livenessProbe,
+							Lifecycle:       lifecycle,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          servingPortName,
+									ContainerPort: containerPort,
+									Protocol:      corev1.ProtocolTCP,
+								},
 							},
 							Resources: corev1.ResourceRequirements{
-								Limits: limits,
+								Requests: requests,
+								Limits:   limits,
+								Claims:   claims,
 							},
 						},
 					},
-					Tolerations: tolerations,
+					Tolerations:               tolerations,
+					Affinity:                  generateAffinity(md, nodeRequirements),
+					TopologySpreadConstraints: generateTopologySpreadConstraints(md),
+					SchedulingGates:           schedulingGates,
+					ResourceClaims:            md.Spec.ResourceClaims,
+					Volumes:                   volumes,
 				},
 			},
 		},
 	}
 
+	if logShipper != nil {
+		deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, *logShipper)
+	}
+	if auditLogRotator != nil {
+		deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, *auditLogRotator)
+	}
+	if md.Spec.GPUTuning != nil && md.Spec.Runtime == "gpu" {
+		// The GPUs are tuned before anything else runs on them
+		initContainers = append([]corev1.Container{generateGPUTuning(md, limits)}, initContainers...)
+	}
+	deploy.Spec.Template.Spec.InitContainers = initContainers
+
 	err := ctrl.SetControllerReference(md, deploy, r.Scheme)
 	if err != nil {
 		return nil, err
@@ -192,24 +1223,130 @@ func (r *ModelDeploymentReconciler) generateDeployment(md *kaimeraaiv1.ModelDepl
 	return deploy, nil
 }
 
+// containerName returns the name of the container running the model server
+func containerName(md *kaimeraaiv1.ModelDeployment) string {
+	if md.Spec.ContainerName != "" {
+		return md.Spec.ContainerName
+	}
+
+	return defaultContainerName
+}
+
+// pinImageDigest replaces the tag or digest of an image reference with the
+// given digest, e.g. vllm/vllm-openai:latest becomes vllm/vllm-openai@sha256:...
+func pinImageDigest(image string, digest string) string {
+	return kaimeraaiv1.ImageRepository(image) + "@" + digest
+}
+
+// cacheSubPath returns the directory of the cache volume used by the model.
+// Configured paths are cleaned so they can't escape the volume; the default
+// is derived from the model name, e.g. meta-llama/Llama-2-7b becomes
+// meta-llama--llama-2-7b.
+func cacheSubPath(md *kaimeraaiv1.ModelDeployment) string {
+	if md.Spec.ModelCache.SubPath != "" {
+		return cleanVolumePath(md.Spec.ModelCache.SubPath)
+	}
+
+	name := strings.ToLower(strings.ReplaceAll(md.Spec.ModelName, "/", "--"))
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// cleanVolumePath returns p relative to the root of a volume, without any
+// segments escaping it
+func cleanVolumePath(p string) string {
+	var segments []string
+	for _, segment := range strings.Split(path.Clean("/"+p), "/") {
+		if segment != "" && segment != ".." {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// generateNodeAffinity requires nodes matching all of the given
+// requirements and, if there are any alternatives, all requirements of one
+// of them. It returns nil if there is nothing to match.
+func generateNodeAffinity(requirements []corev1.NodeSelectorRequirement, alternatives [][]corev1.NodeSelectorRequirement) *corev1.Affinity {
+	if len(requirements) == 0 && len(alternatives) == 0 {
+		return nil
+	}
+
+	terms := []corev1.NodeSelectorTerm{{MatchExpressions: requirements}}
+	if len(alternatives) > 0 {
+		terms = nil
+		for _, alternative := range alternatives {
+			expressions := append(append([]corev1.NodeSelectorRequirement{}, requirements...), alternative...)
+			terms = append(terms, corev1.NodeSelectorTerm{MatchExpressions: expressions})
+		}
+	}
+
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: terms,
+			},
+		},
+	}
+}
+
 func (r *ModelDeploymentReconciler) generateService(md *kaimeraaiv1.ModelDeployment) (*corev1.Service, error) {
+	ports := []corev1.ServicePort{
+		{
+			Name:       "http",
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromString(servingPortName),
+			Port:       md.Spec.ServicePort(),
+		},
+		{
+			// Dedicated port for scrapers. vLLM does not require the
+			// API key on /metrics.
+			Name:       "metrics",
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromString(servingPortName),
+			Port:       servingPort,
+		},
+	}
+	if md.Spec.Port > 0 {
+		// A single port serves both the API and /metrics
+		ports = ports[:1]
+	}
+	if md.Spec.AppProtocol != "" {
+		appProtocol := md.Spec.AppProtocol
+		ports[0].AppProtocol = &appProtocol
+	}
+
+	var annotations map[string]string
+	if md.Spec.Discovery != nil {
+		annotations = r.discoveryAnnotations(md)
+	}
+
+	var internalTrafficPolicy *corev1.ServiceInternalTrafficPolicy
+	if md.Spec.InternalTrafficPolicy != "" {
+		policy := md.Spec.InternalTrafficPolicy
+		internalTrafficPolicy = &policy
+	}
+
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      md.Name,
+			Name:      r.resourceName(md.Name),
 			Namespace: md.Namespace,
+			// Lets the ServiceMonitor select the Service
+			Labels:      childLabels(md, map[string]string{"app": md.Name}),
+			Annotations: childAnnotations(md, annotations),
 		},
 		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceTypeClusterIP,
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: md.Spec.ClusterIP,
 			Selector: map[string]string{
-				"app": md.Name,
-			},
-			Ports: []corev1.ServicePort{
-				{
-					Protocol:   corev1.ProtocolTCP,
-					TargetPort: intstr.FromInt32(8000),
-					Port:       80,
-				},
+				"app": servingApp(md),
 			},
+			Ports:                 ports,
+			InternalTrafficPolicy: internalTrafficPolicy,
 		},
 	}, nil
 }
@@ -2,11 +2,21 @@ package controller
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -65,4 +75,975 @@ var _ = Describe("ModelDeployment Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When setting up the controller", func() {
+		It("should use the default rate limiter unless configured", func() {
+			reconciler := &ModelDeploymentReconciler{}
+			Expect(reconciler.rateLimiter()).To(BeNil())
+		})
+
+		It("should back off failed reconciles with the configured delays", func() {
+			reconciler := &ModelDeploymentReconciler{
+				RateLimiterBaseDelay: time.Second,
+				RateLimiterMaxDelay:  10 * time.Second,
+			}
+			limiter := reconciler.rateLimiter()
+
+			Expect(limiter.When("item")).To(Equal(time.Second))
+			Expect(limiter.When("item")).To(Equal(2 * time.Second))
+			for i := 0; i < 5; i++ {
+				limiter.When("item")
+			}
+			Expect(limiter.When("item")).To(Equal(10 * time.Second))
+
+			limiter.Forget("item")
+			Expect(limiter.When("item")).To(Equal(time.Second))
+		})
+
+		It("should ignore status-only updates to the ModelDeployment", func() {
+			oldMd := &kaimeraaiv1.ModelDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "filtered", Namespace: "default", Generation: 1},
+			}
+			newMd := oldMd.DeepCopy()
+			newMd.Status.ObservedGeneration = 1
+
+			pred := modelDeploymentPredicate()
+			Expect(pred.Update(event.UpdateEvent{ObjectOld: oldMd, ObjectNew: newMd})).To(BeFalse())
+
+			newMd.Generation = 2
+			Expect(pred.Update(event.UpdateEvent{ObjectOld: oldMd, ObjectNew: newMd})).To(BeTrue())
+		})
+
+		It("should reconcile annotation changes to the ModelDeployment", func() {
+			oldMd := &kaimeraaiv1.ModelDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "filtered", Namespace: "default", Generation: 1},
+			}
+			newMd := oldMd.DeepCopy()
+			newMd.Annotations = map[string]string{kaimeraaiv1.MaintenanceWindowAnnotation: "01:00-03:00"}
+
+			pred := modelDeploymentPredicate()
+			Expect(pred.Update(event.UpdateEvent{ObjectOld: oldMd, ObjectNew: newMd})).To(BeTrue())
+			Expect(pred.Update(event.UpdateEvent{ObjectOld: newMd, ObjectNew: oldMd})).To(BeTrue())
+		})
+	})
+
+	Context("When generating a deployment", func() {
+		var reconciler *ModelDeploymentReconciler
+		var md *kaimeraaiv1.ModelDeployment
+
+		BeforeEach(func() {
+			reconciler = &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+			md = &kaimeraaiv1.ModelDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-generate",
+					Namespace: "default",
+				},
+				Spec: kaimeraaiv1.ModelDeploymentSpec{
+					ModelName: "facebook/opt-125m",
+				},
+			}
+		})
+
+		It("should require NUMA aligned nodes when gpu topology awareness is enabled", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUTopologyAware = true
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+
+			affinity := deploy.Spec.Template.Spec.Affinity
+			Expect(affinity).NotTo(BeNil())
+			terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			Expect(terms).To(HaveLen(1))
+			Expect(terms[0].MatchExpressions).To(ConsistOf(corev1.NodeSelectorRequirement{
+				Key:      topologyPolicyLabel,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{topologyPolicyAligned},
+			}))
+		})
+
+		It("should not add scheduling hints for the cpu runtime", func() {
+			md.Spec.GPUTopologyAware = true
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Affinity).To(BeNil())
+		})
+
+		It("should pass max-seq-len-to-capture to vllm when set", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.MaxSeqLenToCapture = 4096
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--max-seq-len-to-capture", "4096"))
+		})
+
+		It("should not pass max-seq-len-to-capture by default", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).NotTo(ContainElement("--max-seq-len-to-capture"))
+		})
+
+		It("should schedule pods with the configured scheduler", func() {
+			md.Spec.SchedulerName = "volcano"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.SchedulerName).To(Equal("volcano"))
+		})
+
+		It("should label and gate pods for kueue admission", func() {
+			md.Spec.Kueue = &kaimeraaiv1.KueueSpec{
+				QueueName: "gpu-queue",
+				Suspend:   true,
+			}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Labels).To(HaveKeyWithValue(kueueQueueNameLabel, "gpu-queue"))
+			Expect(deploy.Spec.Template.Labels).To(HaveKeyWithValue(kueueQueueNameLabel, "gpu-queue"))
+			Expect(deploy.Spec.Template.Spec.SchedulingGates).To(ConsistOf(corev1.PodSchedulingGate{Name: kueueAdmissionGate}))
+			Expect(deploy.Spec.Selector.MatchLabels).NotTo(HaveKey(kueueQueueNameLabel))
+		})
+
+		It("should not gate pods when kueue suspension is disabled", func() {
+			md.Spec.Kueue = &kaimeraaiv1.KueueSpec{QueueName: "gpu-queue"}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Labels).To(HaveKeyWithValue(kueueQueueNameLabel, "gpu-queue"))
+			Expect(deploy.Spec.Template.Spec.SchedulingGates).To(BeEmpty())
+		})
+
+		It("should pin the runtime image to the configured digest", func() {
+			digest := "sha256:" + strings.Repeat("a", 64)
+			md.Spec.Runtime = "gpu"
+			md.Spec.ImageDigest = digest
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal("vllm/vllm-openai@" + digest))
+		})
+
+		It("should keep registry ports when pinning a digest", func() {
+			digest := "sha256:" + strings.Repeat("b", 64)
+			Expect(pinImageDigest("registry.local:5000/vllm:v1", digest)).To(Equal("registry.local:5000/vllm@" + digest))
+			Expect(pinImageDigest("registry.local:5000/vllm", digest)).To(Equal("registry.local:5000/vllm@" + digest))
+			Expect(pinImageDigest("vllm@sha256:"+strings.Repeat("c", 64), digest)).To(Equal("vllm@" + digest))
+		})
+
+		It("should keep metrics scrapeable without the api key", func() {
+			md.Spec.APIKeySecretRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "model-api-key"},
+				Key:                  "key",
+			}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{
+				Name:      "VLLM_API_KEY",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: md.Spec.APIKeySecretRef},
+			}))
+			Expect(container.Command).NotTo(ContainElement("--api-key"))
+
+			svc, err := reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Spec.Ports).To(ContainElement(corev1.ServicePort{
+				Name:       "metrics",
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.FromString(servingPortName),
+				Port:       servingPort,
+			}))
+		})
+
+		It("should pass the load format to vllm", func() {
+			md.Spec.LoadFormat = "safetensors"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--load-format", "safetensors"))
+		})
+
+		It("should support dummy weights for testing", func() {
+			md.Spec.LoadFormat = "dummy"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			command := deploy.Spec.Template.Spec.Containers[0].Command
+			Expect(command).To(ContainElements("--load-format", "dummy"))
+			Expect(command).To(ContainElement(md.Spec.ModelName))
+		})
+
+		It("should apply pod annotations independently of deployment annotations", func() {
+			md.Spec.Annotations = map[string]string{"team": "ml"}
+			md.Spec.PodAnnotations = map[string]string{"sidecar.istio.io/inject": "true"}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Annotations).To(Equal(map[string]string{"team": "ml"}))
+			Expect(deploy.Spec.Template.Annotations).To(Equal(map[string]string{"sidecar.istio.io/inject": "true"}))
+		})
+
+		DescribeTable("should pass the distributed executor backend to vllm",
+			func(backend string) {
+				md.Spec.Runtime = "gpu"
+				md.Spec.DistributedExecutorBackend = backend
+
+				deploy, err := reconciler.generateDeployment(md)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--distributed-executor-backend", backend))
+			},
+			Entry("multiprocessing", "mp"),
+			Entry("ray", "ray"),
+		)
+
+		It("should request the configured number of GPUs", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 4
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			limits := deploy.Spec.Template.Spec.Containers[0].Resources.Limits
+			Expect(limits.Name(kaimeraaiv1.DefaultGPUResourceName, resource.DecimalSI).Value()).To(BeEquivalentTo(4))
+		})
+
+		It("should offload weights to host memory and request it", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 2
+			md.Spec.CPUOffloadGB = 8
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			Expect(container.Command).To(ContainElements("--cpu-offload-gb", "8"))
+			Expect(container.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceMemory, resource.MustParse("16Gi")))
+
+			By("leaving the memory to evictionPriority when it reserves some")
+			md.Spec.EvictionPriority = &kaimeraaiv1.EvictionPrioritySpec{
+				CPU:    resource.MustParse("4"),
+				Memory: resource.MustParse("32Gi"),
+			}
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Resources.Requests).To(
+				HaveKeyWithValue(corev1.ResourceMemory, resource.MustParse("32Gi")))
+		})
+
+		It("should mount the model cache at a subpath derived from the model name", func() {
+			md.Spec.ModelName = "meta-llama/Llama-2-7b@main"
+			md.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{ClaimName: "shared-cache"}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+				Name: modelCacheVolume,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared-cache"},
+				},
+			}))
+			Expect(deploy.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      modelCacheVolume,
+				MountPath: modelCacheMountPath,
+				SubPath:   "meta-llama--llama-2-7b-main",
+			}))
+		})
+
+		It("should sanitize a configured cache subpath", func() {
+			md.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{
+				ClaimName: "shared-cache",
+				SubPath:   "/../models//opt/../opt-125m/",
+			}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].VolumeMounts[0].SubPath).To(Equal("models/opt-125m"))
+		})
+
+		It("should target the named container port from the service", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Ports).To(ConsistOf(corev1.ContainerPort{
+				Name:          servingPortName,
+				ContainerPort: servingPort,
+				Protocol:      corev1.ProtocolTCP,
+			}))
+
+			svc, err := reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			for _, port := range svc.Spec.Ports {
+				Expect(port.TargetPort).To(Equal(intstr.FromString(servingPortName)))
+			}
+		})
+
+		It("should require nodes with the requested gpu product", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUProduct = "NVIDIA-A100-SXM4-80GB"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			terms := deploy.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			Expect(terms).To(HaveLen(1))
+			Expect(terms[0].MatchExpressions).To(ConsistOf(corev1.NodeSelectorRequirement{
+				Key:      defaultGPUProductLabel,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{"NVIDIA-A100-SXM4-80GB"},
+			}))
+		})
+
+		It("should use the configured gpu product label", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUProduct = "Tesla-T4"
+			md.Spec.GPUProductLabel = "cloud.google.com/gke-accelerator"
+			md.Spec.GPUTopologyAware = true
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			terms := deploy.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			Expect(terms).To(HaveLen(1))
+			Expect(terms[0].MatchExpressions).To(ContainElement(corev1.NodeSelectorRequirement{
+				Key:      "cloud.google.com/gke-accelerator",
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{"Tesla-T4"},
+			}))
+			Expect(terms[0].MatchExpressions).To(HaveLen(2))
+		})
+
+		It("should mount writable scratch dirs on a read-only root filesystem", func() {
+			md.Spec.ReadOnlyRootFilesystem = true
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			Expect(*container.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
+
+			volumes := map[string]corev1.Volume{}
+			for _, volume := range deploy.Spec.Template.Spec.Volumes {
+				volumes[volume.Name] = volume
+			}
+			for _, path := range []string{"/tmp", "/root/.cache", "/root/.config", "/root/.triton"} {
+				Expect(container.VolumeMounts).To(ContainElement(HaveField("MountPath", path)))
+			}
+			for _, mount := range container.VolumeMounts {
+				Expect(volumes).To(HaveKey(mount.Name))
+				Expect(volumes[mount.Name].EmptyDir).NotTo(BeNil())
+				Expect(mount.ReadOnly).To(BeFalse())
+			}
+		})
+
+		It("should keep the root filesystem writable by default", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].SecurityContext).To(BeNil())
+			Expect(deploy.Spec.Template.Spec.Volumes).To(BeEmpty())
+		})
+
+		It("should request a custom gpu resource with an independent toleration", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUResourceName = "example.com/accelerator"
+			md.Spec.GPUTolerationKey = "dedicated"
+			md.Spec.GPUCount = 2
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			spec := deploy.Spec.Template.Spec
+			limits := spec.Containers[0].Resources.Limits
+			Expect(limits).To(HaveLen(1))
+			Expect(limits.Name("example.com/accelerator", resource.DecimalSI).Value()).To(BeEquivalentTo(2))
+			Expect(spec.Tolerations).To(ConsistOf(HaveField("Key", "dedicated")))
+		})
+
+		It("should tolerate the gpu resource name by default", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUResourceName = "amd.com/gpu"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Tolerations).To(ConsistOf(HaveField("Key", "amd.com/gpu")))
+		})
+
+		It("should use a single port across container and service", func() {
+			md.Spec.Port = 8080
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			Expect(container.Command).To(ContainElements("--port", "8080"))
+			Expect(container.Ports).To(ConsistOf(HaveField("ContainerPort", BeEquivalentTo(8080))))
+
+			svc, err := reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Spec.Ports).To(ConsistOf(corev1.ServicePort{
+				Name:       "http",
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.FromString(servingPortName),
+				Port:       8080,
+			}))
+		})
+
+		It("should configure OpenTelemetry tracing", func() {
+			md.Spec.Tracing = &kaimeraaiv1.TracingSpec{
+				Endpoint: "http://otel-collector.observability:4317",
+			}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			Expect(container.Command).To(ContainElements("--otlp-traces-endpoint", "http://otel-collector.observability:4317"))
+			Expect(container.Env).To(ContainElements(
+				corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: "http://otel-collector.observability:4317"},
+				corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: md.Name},
+			))
+
+			md.Spec.Tracing.ServiceName = "opt"
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: "opt"},
+			))
+		})
+
+		It("should set the pod hostname and subdomain", func() {
+			md.Spec.Hostname = "opt"
+			md.Spec.Subdomain = "models"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Hostname).To(Equal("opt"))
+			Expect(deploy.Spec.Template.Spec.Subdomain).To(Equal("models"))
+		})
+
+		It("should pass scheduling gates to the pods until they are cleared", func() {
+			md.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: "example.com/cost-approval"}}
+			md.Spec.Kueue = &kaimeraaiv1.KueueSpec{QueueName: "gpu", Suspend: true}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.SchedulingGates).To(ConsistOf(
+				corev1.PodSchedulingGate{Name: "example.com/cost-approval"},
+				corev1.PodSchedulingGate{Name: kueueAdmissionGate},
+			))
+
+			md.Spec.SchedulingGates = nil
+			md.Spec.Kueue = nil
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.SchedulingGates).To(BeEmpty())
+		})
+
+		It("should limit concurrent requests with max-num-seqs", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).NotTo(ContainElement("--max-num-seqs"))
+
+			md.Spec.MaxConcurrentRequests = 16
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--max-num-seqs", "16"))
+		})
+
+		It("should keep the runtime offline", func() {
+			md.Spec.Offline = true
+			md.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{ClaimName: "models"}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "HF_HUB_OFFLINE", Value: "1"},
+				corev1.EnvVar{Name: "TRANSFORMERS_OFFLINE", Value: "1"},
+			))
+		})
+
+		It("should annotate the service for discovery", func() {
+			svc, err := reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Annotations).To(BeEmpty())
+
+			md.Spec.Discovery = &kaimeraaiv1.DiscoverySpec{Tag: "prod"}
+			svc, err = reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Annotations).To(Equal(map[string]string{
+				defaultDiscoveryTagAnnotation:   "prod",
+				defaultDiscoveryModelAnnotation: md.Spec.ModelName,
+			}))
+
+			By("using the configured annotation keys")
+			reconciler.DiscoveryTagAnnotation = "registry.example.com/tag"
+			reconciler.DiscoveryModelAnnotation = "registry.example.com/model"
+			svc, err = reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Annotations).To(Equal(map[string]string{
+				"registry.example.com/tag":   "prod",
+				"registry.example.com/model": md.Spec.ModelName,
+			}))
+		})
+
+		It("should skip recursive chowns of the cache volume", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.SecurityContext).To(BeNil())
+
+			fsGroup := int64(1000)
+			md.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{ClaimName: "models", FSGroup: &fsGroup}
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			securityContext := deploy.Spec.Template.Spec.SecurityContext
+			Expect(securityContext.FSGroup).To(HaveValue(BeEquivalentTo(1000)))
+			Expect(securityContext.FSGroupChangePolicy).To(HaveValue(Equal(corev1.FSGroupChangeOnRootMismatch)))
+
+			md.Spec.ModelCache.FSGroupChangePolicy = corev1.FSGroupChangeAlways
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.SecurityContext.FSGroupChangePolicy).To(HaveValue(Equal(corev1.FSGroupChangeAlways)))
+		})
+
+		It("should allocate devices through resource claims", func() {
+			template := "gpu-claim-template"
+			md.Spec.ResourceClaims = []corev1.PodResourceClaim{
+				{Name: "gpu", Source: corev1.ClaimSource{ResourceClaimTemplateName: &template}},
+			}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			podSpec := deploy.Spec.Template.Spec
+			Expect(podSpec.ResourceClaims).To(Equal(md.Spec.ResourceClaims))
+			Expect(podSpec.Containers[0].Resources.Claims).To(ConsistOf(corev1.ResourceClaim{Name: "gpu"}))
+		})
+
+		It("should run on arm64 nodes with the arm64 image", func() {
+			md.Spec.NodeSelectorLabels = map[string]string{"pool": "graviton"}
+			md.Spec.Architecture = "arm64"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			podSpec := deploy.Spec.Template.Spec
+			Expect(podSpec.NodeSelector).To(Equal(map[string]string{
+				"pool":               "graviton",
+				"kubernetes.io/arch": "arm64",
+			}))
+			Expect(podSpec.Containers[0].Image).To(Equal("patnaikshekhar/vllm-cpu:1-arm64"))
+			Expect(md.Spec.NodeSelectorLabels).NotTo(HaveKey("kubernetes.io/arch"))
+
+			md.Spec.Architecture = "amd64"
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("kubernetes.io/arch", "amd64"))
+			Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal("patnaikshekhar/vllm-cpu:1"))
+		})
+
+		It("should derive the tensor parallel size from the gpu count", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 4
+			md.Spec.AutoTensorParallel = true
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--tensor-parallel-size", "4"))
+
+			md.Spec.Runtime = "cpu"
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).NotTo(ContainElement("--tensor-parallel-size"))
+		})
+
+		It("should set the tensor and pipeline parallel sizes", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 8
+			md.Spec.TensorParallelSize = 4
+			md.Spec.PipelineParallelSize = 2
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			command := deploy.Spec.Template.Spec.Containers[0].Command
+			Expect(command).To(ContainElements("--tensor-parallel-size", "4", "--pipeline-parallel-size", "2"))
+		})
+
+		It("should prefer an explicit tensor parallel size over the gpu count", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUCount = 8
+			md.Spec.AutoTensorParallel = true
+			md.Spec.TensorParallelSize = 4
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			command := deploy.Spec.Template.Spec.Containers[0].Command
+			Expect(command).To(ContainElements("--tensor-parallel-size", "4"))
+			Expect(command).NotTo(ContainElement("8"))
+			count := 0
+			for _, arg := range command {
+				if arg == "--tensor-parallel-size" {
+					count++
+				}
+			}
+			Expect(count).To(Equal(1))
+		})
+
+		It("should make the pods the last to be evicted", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.EvictionPriority = &kaimeraaiv1.EvictionPrioritySpec{
+				CPU:    resource.MustParse("8"),
+				Memory: resource.MustParse("64Gi"),
+			}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			podSpec := deploy.Spec.Template.Spec
+			Expect(podSpec.PriorityClassName).To(BeEmpty())
+
+			resources := podSpec.Containers[0].Resources
+			Expect(resources.Limits).To(HaveLen(3))
+			Expect(resources.Requests).To(HaveLen(3))
+			for name, limit := range resources.Limits {
+				request := resources.Requests[name]
+				Expect(request.Cmp(limit)).To(BeZero(), "request for %s", name)
+			}
+			Expect(resources.Limits.Memory().Value()).To(BeEquivalentTo(64 << 30))
+
+			reconciler.DefaultPriorityClass = "model-serving-default"
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.PriorityClassName).To(Equal("model-serving-default"))
+
+			md.Spec.EvictionPriority.PriorityClassName = "model-serving"
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.PriorityClassName).To(Equal("model-serving"))
+		})
+
+		It("should set the app protocol of the serving port", func() {
+			md.Spec.AppProtocol = "grpc"
+
+			svc, err := reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Spec.Ports[0].Name).To(Equal("http"))
+			Expect(svc.Spec.Ports[0].AppProtocol).To(HaveValue(Equal("grpc")))
+			Expect(svc.Spec.Ports[1].AppProtocol).To(BeNil())
+		})
+
+		It("should set the internal traffic policy of the Service", func() {
+			svc, err := reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Spec.InternalTrafficPolicy).To(BeNil())
+
+			md.Spec.InternalTrafficPolicy = corev1.ServiceInternalTrafficPolicyLocal
+			svc, err = reconciler.generateService(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Spec.InternalTrafficPolicy).To(HaveValue(Equal(corev1.ServiceInternalTrafficPolicyLocal)))
+		})
+
+		It("should name the model server container", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Name).To(Equal("model-server"))
+
+			md.Spec.ContainerName = "vllm"
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Name).To(Equal("vllm"))
+			Expect(deploy.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": md.Name}))
+			Expect(deploy.Spec.Template.Labels).To(HaveKeyWithValue(modelDeploymentLabel, md.Name))
+		})
+
+		It("should enable parallel downloads", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(
+				HaveField("Name", "HF_XET_NUM_CONCURRENT_RANGE_GETS")))
+
+			md.Spec.DownloadConcurrency = 8
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			env := deploy.Spec.Template.Spec.Containers[0].Env
+			Expect(env).To(ContainElement(corev1.EnvVar{Name: "HF_XET_NUM_CONCURRENT_RANGE_GETS", Value: "8"}))
+			Expect(env).NotTo(ContainElement(HaveField("Name", "HF_HUB_ENABLE_HF_TRANSFER")))
+		})
+
+		It("should pin the runtime to the visible devices", func() {
+			md.Spec.CUDAVisibleDevices = "0,2"
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "CUDA_VISIBLE_DEVICES", Value: "0,2"}))
+		})
+
+		It("should propagate the restart policy", func() {
+			md.Spec.RestartPolicy = corev1.RestartPolicyAlways
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyAlways))
+		})
+
+		It("should add a log shipper sidecar sharing the log volume", func() {
+			md.Spec.Logging = &kaimeraaiv1.LoggingSpec{
+				Sidecar: &kaimeraaiv1.LogSidecarSpec{Endpoint: "https://logs.example.com/ingest"},
+			}
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+
+			podSpec := deploy.Spec.Template.Spec
+			Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+				Name: logVolume,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &logVolumeSizeLimit},
+				},
+			}))
+			Expect(podSpec.Containers).To(HaveLen(2))
+
+			app := podSpec.Containers[0]
+			Expect(app.Command[:2]).To(Equal([]string{"/bin/sh", "-c"}))
+			Expect(app.Command[2]).To(Equal(`rm -f "/var/log/model/output.fifo" && mkfifo "/var/log/model/output.fifo" && ` +
+				`{ tee -a "/var/log/model/model.log" < "/var/log/model/output.fifo" & } && ` +
+				`{ while sleep 60; do [ "$(wc -c < "/var/log/model/model.log")" -lt 104857600 ] || : > "/var/log/model/model.log"; done & } && ` +
+				`exec "$@" > "/var/log/model/output.fifo" 2>&1`))
+			Expect(app.Command).To(ContainElement("facebook/opt-125m"))
+			Expect(app.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: logVolume, MountPath: logMountPath}))
+
+			sidecar := podSpec.Containers[1]
+			Expect(sidecar.Name).To(Equal(logShipperName))
+			Expect(sidecar.Image).To(Equal(defaultLogShipperImage))
+			Expect(sidecar.Args).To(ContainElements("host=logs.example.com", "port=443", "uri=/ingest", "tls=on",
+				"db=/var/log/model/log-shipper.db"))
+			Expect(sidecar.VolumeMounts).To(ContainElement(HaveField("Name", logVolume)))
+		})
+
+		It("should log requests and responses to the audit volume", func() {
+			md.Spec.AuditLogging = &kaimeraaiv1.AuditLoggingSpec{
+				ClaimName:     "audit",
+				RetentionDays: 90,
+			}
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+
+			podSpec := deploy.Spec.Template.Spec
+			Expect(podSpec.Volumes).To(ContainElement(HaveField("VolumeSource.PersistentVolumeClaim.ClaimName", "audit")))
+			Expect(podSpec.Containers).To(HaveLen(2))
+
+			app := podSpec.Containers[0]
+			Expect(app.Command[:2]).To(Equal([]string{"/bin/sh", "-c"}))
+			Expect(app.Command[2]).To(ContainSubstring(`tee -a "/var/log/audit/${POD_NAME}.log" < "/var/log/model/output.fifo"`))
+			Expect(app.Command[2]).NotTo(ContainSubstring("while sleep"))
+			Expect(app.Command).To(ContainElements("facebook/opt-125m", "--enable-log-requests", "--enable-log-outputs"))
+			Expect(app.Env).To(ContainElement(podNameEnv))
+			Expect(app.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: auditLogVolume, MountPath: auditLogMountPath}))
+
+			By("keeping the fifo on a volume local to the pod")
+			Expect(podSpec.Volumes).To(ContainElement(HaveField("Name", logVolume)))
+			Expect(podSpec.Volumes).To(ContainElement(HaveField("VolumeSource.EmptyDir.SizeLimit", &logVolumeSizeLimit)))
+			Expect(app.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: logVolume, MountPath: logMountPath}))
+
+			rotator := podSpec.Containers[1]
+			Expect(rotator.Name).To(Equal(auditLogRotatorName))
+			Expect(rotator.Image).To(Equal(defaultAuditLogImage))
+			Expect(rotator.Env).To(ContainElement(podNameEnv))
+			Expect(rotator.Command[2]).To(ContainSubstring("-ge 104857600"))
+			Expect(rotator.Command[2]).To(ContainSubstring("-name '*.log.*' -mtime +90"))
+			Expect(rotator.VolumeMounts).To(ContainElement(HaveField("Name", auditLogVolume)))
+
+			By("teeing to both the audit log and the shipped log")
+			md.Spec.Logging = &kaimeraaiv1.LoggingSpec{
+				Sidecar: &kaimeraaiv1.LogSidecarSpec{Endpoint: "https://logs.example.com/ingest"},
+			}
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers).To(HaveLen(3))
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring(
+				`tee -a "/var/log/model/model.log" "/var/log/audit/${POD_NAME}.log" < "/var/log/model/output.fifo"`))
+		})
+
+		It("should pass the rope scaling to the runtime", func() {
+			md.Spec.RopeScaling = `{"rope_type":"yarn","factor":4.0}`
+			md.Spec.RopeTheta = 1000000
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements(
+				"--rope-scaling", `{"rope_type":"yarn","factor":4.0}`,
+				"--rope-theta", "1000000",
+			))
+		})
+
+		It("should run in the host PID namespace when enabled", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.HostPID).To(BeFalse())
+
+			md.Spec.HostPID = true
+			reconciler.AllowHostPID = true
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.HostPID).To(BeTrue())
+		})
+
+		It("should refuse the host PID namespace unless allowed", func() {
+			md.Spec.HostPID = true
+			_, err := reconciler.generateDeployment(md)
+			Expect(err).To(MatchError("hostPID is not allowed on this cluster"))
+			Expect(err).To(BeAssignableToTypeOf(&degradedError{}))
+			Expect(err.(*degradedError).reason).To(Equal("HostPIDNotAllowed"))
+		})
+
+		It("should cap the GPU memory of each replica", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUMemoryUtilization = "0.4"
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--gpu-memory-utilization", "0.4"))
+			Expect(deploy.Spec.Template.Spec.Affinity).To(BeNil())
+		})
+
+		It("should keep a static cluster IP across updates", func() {
+			ctx := context.Background()
+			md.Spec.ClusterIP = "10.96.100.10"
+			reconciler.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build()
+
+			Expect(reconciler.reconcileChildren(ctx, md, nil)).To(Succeed())
+			svc := &corev1.Service{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), svc)).To(Succeed())
+			Expect(svc.Spec.ClusterIP).To(Equal("10.96.100.10"))
+
+			By("updating the service")
+			md.Spec.AppProtocol = "http2"
+			current := &appsv1.Deployment{}
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), current)).To(Succeed())
+			Expect(reconciler.reconcileChildren(ctx, md, current)).To(Succeed())
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), svc)).To(Succeed())
+			Expect(svc.Spec.ClusterIP).To(Equal("10.96.100.10"))
+			Expect(svc.Spec.Ports[0].AppProtocol).To(HaveValue(Equal("http2")))
+		})
+
+		It("should let vllm detect the context length unless it is set", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).NotTo(ContainElement("--max-model-len"))
+
+			md.Spec.MaxModelLength = 4096
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--max-model-len", "4096"))
+		})
+
+		It("should probe the runtime with the exec command", func() {
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Containers[0].ReadinessProbe).To(BeNil())
+
+			md.Spec.Probes = &kaimeraaiv1.ProbesSpec{ExecCommand: []string{"sh", "-c", "test -S /tmp/model.sock"}}
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			for _, probe := range []*corev1.Probe{container.StartupProbe, container.ReadinessProbe, container.LivenessProbe} {
+				Expect(probe).NotTo(BeNil())
+				Expect(probe.Exec.Command).To(Equal(md.Spec.Probes.ExecCommand))
+				Expect(probe.HTTPGet).To(BeNil())
+			}
+		})
+
+		It("should only make replicas ready once they complete a test generation", func() {
+			md.Spec.Port = 9000
+			md.Spec.Probes = &kaimeraaiv1.ProbesSpec{
+				ExecCommand: []string{"sh", "-c", "test -S /tmp/model.sock"},
+				Generation: &kaimeraaiv1.GenerationProbeSpec{
+					Prompt:       "Say \"ok\"",
+					ExpectedText: "ok",
+				},
+			}
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			Expect(container.StartupProbe.Exec.Command).To(Equal(md.Spec.Probes.ExecCommand))
+			Expect(container.LivenessProbe.Exec.Command).To(Equal(md.Spec.Probes.ExecCommand))
+
+			probe := container.ReadinessProbe
+			Expect(probe.Exec.Command).To(HaveLen(8))
+			Expect(probe.Exec.Command[:3]).To(Equal([]string{"python3", "-c", generationProbeScript}))
+			Expect(probe.Exec.Command[3:]).To(Equal([]string{
+				"http://localhost:9000/v1/completions",
+				`{"max_tokens":1,"model":"facebook/opt-125m","prompt":"Say \"ok\""}`,
+				"200",
+				"ok",
+				"10",
+			}))
+			Expect(probe.TimeoutSeconds).To(Equal(int32(11)))
+
+			By("probing only readiness without an exec command")
+			md.Spec.Probes = &kaimeraaiv1.ProbesSpec{
+				Generation: &kaimeraaiv1.GenerationProbeSpec{MaxTokens: 4, ExpectedStatus: 201, TimeoutSeconds: 30},
+			}
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container = deploy.Spec.Template.Spec.Containers[0]
+			Expect(container.StartupProbe).To(BeNil())
+			Expect(container.LivenessProbe).To(BeNil())
+			Expect(container.ReadinessProbe.Exec.Command[4:]).To(Equal([]string{
+				`{"max_tokens":4,"model":"facebook/opt-125m","prompt":"Hello"}`,
+				"201",
+				"",
+				"30",
+			}))
+		})
+
+		It("should set the sysctls on the pod", func() {
+			md.Spec.Sysctls = []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "4096"}}
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.SecurityContext.Sysctls).To(Equal(md.Spec.Sysctls))
+		})
+
+		It("should serve the LoRA adapters from the model cache claim", func() {
+			md.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{ClaimName: "models"}
+			md.Spec.LoRAAdapters = []kaimeraaiv1.LoRAAdapter{
+				{Name: "sql", Path: "adapters/sql-lora"},
+				{Name: "chat", Path: "../adapters/chat-lora/"},
+			}
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			container := deploy.Spec.Template.Spec.Containers[0]
+			Expect(container.Command).To(ContainElements(
+				"--enable-lora", "--lora-modules",
+				"sql=/etc/kaimera/lora/adapters/sql-lora",
+				"chat=/etc/kaimera/lora/adapters/chat-lora",
+			))
+			Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      modelCacheVolume,
+				MountPath: loraMountPath,
+				ReadOnly:  true,
+			}))
+		})
+
+		It("should require nodes supporting the minimum CUDA version", func() {
+			md.Spec.Runtime = "gpu"
+			md.Spec.GPUProduct = "NVIDIA-H100-80GB-HBM3"
+			md.Spec.MinCUDAVersion = "12.4"
+
+			deploy, err := reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			product := corev1.NodeSelectorRequirement{
+				Key:      "nvidia.com/gpu.product",
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{"NVIDIA-H100-80GB-HBM3"},
+			}
+			Expect(deploy.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{
+					product,
+					{Key: "nvidia.com/cuda.runtime.major", Operator: corev1.NodeSelectorOpGt, Values: []string{"12"}},
+				}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{
+					product,
+					{Key: "nvidia.com/cuda.runtime.major", Operator: corev1.NodeSelectorOpIn, Values: []string{"12"}},
+					{Key: "nvidia.com/cuda.runtime.minor", Operator: corev1.NodeSelectorOpGt, Values: []string{"3"}},
+				}},
+			}))
+
+			md.Spec.MinCUDAVersion = "12.0"
+			deploy, err = reconciler.generateDeployment(md)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploy.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{
+					product,
+					{Key: "nvidia.com/cuda.runtime.major", Operator: corev1.NodeSelectorOpGt, Values: []string{"11"}},
+				}},
+			}))
+		})
+	})
 })
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	mpsVolume               = "nvidia-mps"
+	defaultMPSPipeDirectory = "/tmp/nvidia-mps"
+)
+
+// mpsPipeDirectory returns the node directory the MPS control daemons
+// listen in
+func (r *ModelDeploymentReconciler) mpsPipeDirectory() string {
+	if r.MPSPipeDirectory != "" {
+		return r.MPSPipeDirectory
+	}
+
+	return defaultMPSPipeDirectory
+}
+
+// mpsEnabled reports whether the replicas share their GPUs through MPS
+func mpsEnabled(md *kaimeraaiv1.ModelDeployment) bool {
+	return md.Spec.MPS != nil && md.Spec.Runtime == "gpu"
+}
+
+// generateMPS returns the environment, volume and mount connecting the
+// runtime to the node's MPS control daemon. The pipe directory is mounted
+// read-only at the same path as on the node, where CUDA looks for it;
+// clients only connect to the daemon's sockets.
+func (r *ModelDeploymentReconciler) generateMPS(md *kaimeraaiv1.ModelDeployment) ([]corev1.EnvVar, corev1.Volume, corev1.VolumeMount) {
+	pipeDirectory := r.mpsPipeDirectory()
+
+	env := []corev1.EnvVar{
+		{Name: "CUDA_MPS_PIPE_DIRECTORY", Value: pipeDirectory},
+	}
+	if percentage := md.Spec.MPS.ActiveThreadPercentage; percentage > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  "CUDA_MPS_ACTIVE_THREAD_PERCENTAGE",
+			Value: strconv.Itoa(int(percentage)),
+		})
+	}
+
+	hostPathType := corev1.HostPathDirectory
+	volume := corev1.Volume{
+		Name: mpsVolume,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: pipeDirectory,
+				Type: &hostPathType,
+			},
+		},
+	}
+	mount := corev1.VolumeMount{
+		Name:      mpsVolume,
+		MountPath: pipeDirectory,
+		ReadOnly:  true,
+	}
+
+	return env, volume, mount
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment MPS", func() {
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "shared",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Runtime:   "gpu",
+				MPS:       &kaimeraaiv1.MPSSpec{ActiveThreadPercentage: 50},
+			},
+		}
+		reconciler = &ModelDeploymentReconciler{Scheme: scheme.Scheme, AllowMPS: true}
+	})
+
+	It("should connect the runtime to the node's MPS control daemon", func() {
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		podSpec := deploy.Spec.Template.Spec
+		Expect(podSpec.HostIPC).To(BeTrue())
+
+		container := podSpec.Containers[0]
+		Expect(container.Env).To(ContainElements(
+			corev1.EnvVar{Name: "CUDA_MPS_PIPE_DIRECTORY", Value: "/tmp/nvidia-mps"},
+			corev1.EnvVar{Name: "CUDA_MPS_ACTIVE_THREAD_PERCENTAGE", Value: "50"},
+		))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      "nvidia-mps",
+			MountPath: "/tmp/nvidia-mps",
+			ReadOnly:  true,
+		}))
+
+		hostPathType := corev1.HostPathDirectory
+		Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "nvidia-mps",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/tmp/nvidia-mps", Type: &hostPathType},
+			},
+		}))
+	})
+
+	It("should use the controller's pipe directory", func() {
+		md.Spec.MPS = &kaimeraaiv1.MPSSpec{}
+		reconciler.MPSPipeDirectory = "/run/nvidia/mps"
+
+		env, volume, mount := reconciler.generateMPS(md)
+		Expect(env).To(Equal([]corev1.EnvVar{{Name: "CUDA_MPS_PIPE_DIRECTORY", Value: "/run/nvidia/mps"}}))
+		Expect(volume.HostPath.Path).To(Equal("/run/nvidia/mps"))
+		Expect(mount.MountPath).To(Equal("/run/nvidia/mps"))
+	})
+
+	It("should refuse MPS unless allowed", func() {
+		reconciler.AllowMPS = false
+		_, err := reconciler.generateDeployment(md)
+		Expect(err).To(MatchError("MPS is not allowed on this cluster"))
+		Expect(err).To(BeAssignableToTypeOf(&degradedError{}))
+		Expect(err.(*degradedError).reason).To(Equal("MPSNotAllowed"))
+	})
+
+	It("should not share the node's IPC namespace without MPS", func() {
+		md.Spec.MPS = nil
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(deploy.Spec.Template.Spec.HostIPC).To(BeFalse())
+		for _, volume := range deploy.Spec.Template.Spec.Volumes {
+			Expect(volume.Name).NotTo(Equal("nvidia-mps"))
+		}
+	})
+})
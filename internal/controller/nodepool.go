@@ -0,0 +1,96 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	// defaultNodePoolLabel is set by Karpenter on the nodes it provisions
+	defaultNodePoolLabel = "karpenter.sh/nodepool"
+
+	// Well known labels Karpenter sets on its nodes, and provisions nodes
+	// for when pods require them
+	karpenterCapacityTypeLabel   = "karpenter.sh/capacity-type"
+	karpenterInstanceFamilyLabel = "karpenter.k8s.aws/instance-family"
+
+	// karpenterDoNotDisruptAnnotation keeps Karpenter from voluntarily
+	// disrupting the node of a pod
+	karpenterDoNotDisruptAnnotation = "karpenter.sh/do-not-disrupt"
+)
+
+// nodePoolLabel returns the node label holding the name of a node's pool
+func (r *ModelDeploymentReconciler) nodePoolLabel() string {
+	if r.NodePoolLabel != "" {
+		return r.NodePoolLabel
+	}
+
+	return defaultNodePoolLabel
+}
+
+// generateNodePoolTolerations returns the toleration of the taint on the
+// nodes of the model's pool, if the pools are tainted
+func (r *ModelDeploymentReconciler) generateNodePoolTolerations(md *kaimeraaiv1.ModelDeployment) []corev1.Toleration {
+	if md.Spec.NodePool == "" || r.NodePoolTaint == "" {
+		return nil
+	}
+
+	return []corev1.Toleration{
+		{
+			Key:      r.NodePoolTaint,
+			Operator: corev1.TolerationOpEqual,
+			Value:    md.Spec.NodePool,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+}
+
+// generateKarpenterRequirements returns the node requirements on instance
+// type, family and capacity type of the model's Karpenter options
+func generateKarpenterRequirements(md *kaimeraaiv1.ModelDeployment) []corev1.NodeSelectorRequirement {
+	spec := md.Spec.Karpenter
+	if spec == nil {
+		return nil
+	}
+
+	var capacityTypes []string
+	for _, capacityType := range spec.CapacityTypes {
+		capacityTypes = append(capacityTypes, string(capacityType))
+	}
+
+	var requirements []corev1.NodeSelectorRequirement
+	for _, requirement := range []struct {
+		key    string
+		values []string
+	}{
+		{key: corev1.LabelInstanceTypeStable, values: spec.InstanceTypes},
+		{key: karpenterInstanceFamilyLabel, values: spec.InstanceFamilies},
+		{key: karpenterCapacityTypeLabel, values: capacityTypes},
+	} {
+		if len(requirement.values) == 0 {
+			continue
+		}
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      requirement.key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   requirement.values,
+		})
+	}
+
+	return requirements
+}
+
+// generatePodAnnotations returns the annotations of the pod template: those
+// of the spec, and Karpenter's do-not-disrupt if requested
+func generatePodAnnotations(md *kaimeraaiv1.ModelDeployment) map[string]string {
+	if md.Spec.Karpenter == nil || !md.Spec.Karpenter.DoNotDisrupt {
+		return md.Spec.PodAnnotations
+	}
+
+	annotations := map[string]string{karpenterDoNotDisruptAnnotation: "true"}
+	for key, value := range md.Spec.PodAnnotations {
+		annotations[key] = value
+	}
+	return annotations
+}
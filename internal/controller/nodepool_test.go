@@ -0,0 +1,105 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment node pools", func() {
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pooled",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:          "facebook/opt-125m",
+				Runtime:            "gpu",
+				NodeSelectorLabels: map[string]string{"team": "ml"},
+				NodePool:           "h100",
+			},
+		}
+	})
+
+	It("should select the node pool with the default label", func() {
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		podSpec := deploy.Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(map[string]string{
+			"team":                  "ml",
+			"karpenter.sh/nodepool": "h100",
+		}))
+		Expect(podSpec.Tolerations).To(HaveLen(1))
+	})
+
+	It("should select and tolerate the node pool with the configured keys", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Scheme:        scheme.Scheme,
+			NodePoolLabel: "cloud.google.com/gke-nodepool",
+			NodePoolTaint: "pool.example.com/name",
+		}
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		podSpec := deploy.Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(map[string]string{
+			"team":                          "ml",
+			"cloud.google.com/gke-nodepool": "h100",
+		}))
+		Expect(podSpec.Tolerations).To(ContainElement(corev1.Toleration{
+			Key:      "pool.example.com/name",
+			Operator: corev1.TolerationOpEqual,
+			Value:    "h100",
+			Effect:   corev1.TaintEffectNoSchedule,
+		}))
+
+		By("counting only the nodes of the pool")
+		Expect(reconciler.nodeLabels(md)).To(HaveKeyWithValue("cloud.google.com/gke-nodepool", "h100"))
+		Expect(md.Spec.NodeSelectorLabels).NotTo(HaveKey("cloud.google.com/gke-nodepool"))
+	})
+
+	It("should require the instances Karpenter provisions", func() {
+		md.Spec.GPUProduct = "NVIDIA-H100-80GB-HBM3"
+		md.Spec.PodAnnotations = map[string]string{"sidecar.istio.io/inject": "false"}
+		md.Spec.Karpenter = &kaimeraaiv1.KarpenterSpec{
+			InstanceTypes: []string{"p5.48xlarge"},
+			CapacityTypes: []kaimeraaiv1.KarpenterCapacityType{
+				kaimeraaiv1.KarpenterCapacityTypeOnDemand,
+				kaimeraaiv1.KarpenterCapacityTypeSpot,
+			},
+			DoNotDisrupt: true,
+		}
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		affinity := deploy.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		Expect(affinity.NodeSelectorTerms).To(HaveLen(1))
+		Expect(affinity.NodeSelectorTerms[0].MatchExpressions).To(Equal([]corev1.NodeSelectorRequirement{
+			{Key: "nvidia.com/gpu.product", Operator: corev1.NodeSelectorOpIn, Values: []string{"NVIDIA-H100-80GB-HBM3"}},
+			{Key: "node.kubernetes.io/instance-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"p5.48xlarge"}},
+			{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"on-demand", "spot"}},
+		}))
+		Expect(deploy.Spec.Template.Annotations).To(Equal(map[string]string{
+			"sidecar.istio.io/inject":     "false",
+			"karpenter.sh/do-not-disrupt": "true",
+		}))
+		Expect(md.Spec.PodAnnotations).NotTo(HaveKey("karpenter.sh/do-not-disrupt"))
+
+		By("requiring only the configured instance families")
+		md.Spec.Karpenter = &kaimeraaiv1.KarpenterSpec{InstanceFamilies: []string{"p5", "p4d"}}
+		Expect(generateKarpenterRequirements(md)).To(Equal([]corev1.NodeSelectorRequirement{
+			{Key: "karpenter.k8s.aws/instance-family", Operator: corev1.NodeSelectorOpIn, Values: []string{"p5", "p4d"}},
+		}))
+		Expect(generatePodAnnotations(md)).To(Equal(md.Spec.PodAnnotations))
+	})
+})
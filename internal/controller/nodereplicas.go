@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// desiredReplicas returns the replica count the spec asks for, derived from
+// the matching nodes when replicasPerNode is set or decided by rightsizing,
+// and the warm pool
+func desiredReplicas(md *kaimeraaiv1.ModelDeployment) int32 {
+	if md.Spec.ReplicasPerNode > 0 {
+		return md.Status.NodeReplicas + warmPoolReplicas(md)
+	}
+	if md.Spec.Rightsizing != nil {
+		return rightsizedReplicas(md) + warmPoolReplicas(md)
+	}
+
+	return md.Spec.Replicas + warmPoolReplicas(md)
+}
+
+// reconcileNodeReplicas records the replica count for the schedulable nodes
+// currently matching the ModelDeployment
+func (r *ModelDeploymentReconciler) reconcileNodeReplicas(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	nodes := corev1.NodeList{}
+	err := r.List(ctx, &nodes, client.MatchingLabels(r.nodeLabels(md)))
+	if err != nil {
+		return err
+	}
+
+	var count int32
+	for _, node := range nodes.Items {
+		if !node.Spec.Unschedulable {
+			count++
+		}
+	}
+
+	next := count * md.Spec.ReplicasPerNode
+	if next == md.Status.NodeReplicas {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("matching nodes changed", "nodes", count, "replicas", next)
+	md.Status.NodeReplicas = next
+	return r.updateStatus(ctx, md)
+}
+
+// nodeLabels returns the labels a node needs to run the model
+func (r *ModelDeploymentReconciler) nodeLabels(md *kaimeraaiv1.ModelDeployment) map[string]string {
+	labels := map[string]string{}
+	for k, v := range r.generateNodeSelector(md) {
+		labels[k] = v
+	}
+	if md.Spec.Runtime == "gpu" && md.Spec.GPUProduct != "" {
+		labels[gpuProductLabel(md)] = md.Spec.GPUProduct
+	}
+
+	return labels
+}
+
+// modelDeploymentsPerNode maps a node event to the ModelDeployments sized by
+// their nodes
+func (r *ModelDeploymentReconciler) modelDeploymentsPerNode(ctx context.Context, _ client.Object) []reconcile.Request {
+	mds := kaimeraaiv1.ModelDeploymentList{}
+	err := r.List(ctx, &mds)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to list model deployments for node event")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, md := range mds.Items {
+		if md.Spec.ReplicasPerNode > 0 || md.Spec.ExclusiveNode {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&md)})
+		}
+	}
+
+	return requests
+}
+
+// nodeCountPredicate passes node events that can change how many nodes match
+// a ModelDeployment, or how large they are: nodes coming and going,
+// relabelling, cordoning and changes to allocatable resources.
+func nodeCountPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+
+			return oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+				!equality.Semantic.DeepEqual(oldNode.Labels, newNode.Labels) ||
+				!equality.Semantic.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable)
+		},
+	}
+}
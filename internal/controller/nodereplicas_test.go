@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment replicas per node", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	node := func(name string, labels map[string]string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		}
+	}
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "per-node",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:          "facebook/opt-125m",
+				Runtime:            "gpu",
+				NodeSelectorLabels: map[string]string{"pool": "gpu"},
+				GPUProduct:         "NVIDIA-A100-SXM4-80GB",
+				ReplicasPerNode:    2,
+			},
+		}
+
+		a100 := map[string]string{"pool": "gpu", defaultGPUProductLabel: "NVIDIA-A100-SXM4-80GB"}
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(md,
+				node("a100-1", a100, false),
+				node("a100-2", a100, false),
+				node("a100-cordoned", a100, true),
+				node("t4", map[string]string{"pool": "gpu", defaultGPUProductLabel: "Tesla-T4"}, false),
+				node("cpu", map[string]string{"pool": "cpu"}, false),
+			).
+			WithStatusSubresource(md).
+			Build()
+		reconciler = &ModelDeploymentReconciler{
+			Client: fakeClient,
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should size the deployment by the matching schedulable nodes", func() {
+		Expect(reconciler.reconcileNodeReplicas(ctx, md)).To(Succeed())
+		Expect(md.Status.NodeReplicas).To(BeEquivalentTo(4))
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(4))
+	})
+
+	It("should enqueue per node deployments on node events", func() {
+		fixed := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "fixed", Namespace: "default"},
+		}
+		Expect(reconciler.Create(ctx, fixed)).To(Succeed())
+
+		requests := reconciler.modelDeploymentsPerNode(ctx, node("new", nil, false))
+		Expect(requests).To(ConsistOf(HaveField("NamespacedName", client.ObjectKeyFromObject(md))))
+	})
+
+	It("should only pass node updates that change the matching nodes", func() {
+		oldNode := node("a100-1", map[string]string{"pool": "gpu"}, false)
+		newNode := oldNode.DeepCopy()
+		newNode.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+
+		pred := nodeCountPredicate()
+		Expect(pred.Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: newNode})).To(BeFalse())
+
+		newNode.Spec.Unschedulable = true
+		Expect(pred.Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: newNode})).To(BeTrue())
+	})
+})
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	notificationReady  = "Ready"
+	notificationFailed = "Failed"
+)
+
+var (
+	// notificationAttempts and notificationRetryDelay bound the delivery of
+	// a notification. The delay doubles after each failed attempt.
+	notificationAttempts   = 3
+	notificationRetryDelay = time.Second
+
+	notificationClient = &http.Client{Timeout: 10 * time.Second}
+
+	// notificationSenders bounds the notifications being delivered; further
+	// notifications are dropped until one is delivered or given up on
+	notificationSenders = make(chan struct{}, 32)
+)
+
+// notification is the JSON payload POSTed to the notification webhook
+type notification struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	ModelName string    `json:"modelName"`
+	State     string    `json:"state"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// notify POSTs a notification about the ModelDeployment's new state to its
+// notification webhook in the background. The notification is dropped while
+// too many are in flight, so unreachable webhooks can't pile up goroutines.
+func (r *ModelDeploymentReconciler) notify(ctx context.Context, md *kaimeraaiv1.ModelDeployment, state, reason, message string) {
+	if md.Spec.NotificationWebhook == "" {
+		return
+	}
+
+	body, err := json.Marshal(notification{
+		Name:      md.Name,
+		Namespace: md.Namespace,
+		ModelName: md.Spec.ModelName,
+		State:     state,
+		Reason:    reason,
+		Message:   message,
+		Time:      r.now().UTC(),
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to encode notification")
+		return
+	}
+
+	logger := log.FromContext(ctx).WithValues("url", md.Spec.NotificationWebhook, "state", state)
+	select {
+	case notificationSenders <- struct{}{}:
+	default:
+		logger.Info("dropping notification, too many in flight")
+		return
+	}
+
+	go func() {
+		defer func() { <-notificationSenders }()
+		sendNotification(logger, md.Spec.NotificationWebhook, body)
+	}()
+}
+
+func sendNotification(logger logr.Logger, url string, body []byte) {
+	delay := notificationRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := postNotification(url, body)
+		if err == nil {
+			return
+		}
+		if attempt == notificationAttempts {
+			logger.Error(err, "giving up on notification", "attempts", attempt)
+			return
+		}
+
+		logger.Info("notification failed, retrying", "error", err.Error(), "delay", delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func postNotification(url string, body []byte) error {
+	resp, err := notificationClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment notifications", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+	var server *httptest.Server
+	var received chan notification
+	var failures atomic.Int32
+
+	BeforeEach(func() {
+		received = make(chan notification, 10)
+		failures.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			defer GinkgoRecover()
+			if failures.Add(-1) >= 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			n := notification{}
+			Expect(json.NewDecoder(req.Body).Decode(&n)).To(Succeed())
+			received <- n
+		}))
+		DeferCleanup(server.Close)
+
+		retryDelay := notificationRetryDelay
+		notificationRetryDelay = time.Millisecond
+		DeferCleanup(func() { notificationRetryDelay = retryDelay })
+
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "notify",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:           "facebook/opt-125m",
+				NotificationWebhook: server.URL,
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should notify once when the deployment becomes ready", func() {
+		dp := &appsv1.Deployment{}
+		Expect(reconciler.reconcileReady(ctx, md, dp)).To(Succeed())
+		Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+
+		dp.Status.ReadyReplicas = 1
+		Expect(reconciler.reconcileReady(ctx, md, dp)).To(Succeed())
+
+		var n notification
+		Eventually(received).Should(Receive(&n))
+		Expect(n.Name).To(Equal("notify"))
+		Expect(n.ModelName).To(Equal("facebook/opt-125m"))
+		Expect(n.State).To(Equal("Ready"))
+
+		By("not notifying again while the deployment stays ready")
+		Expect(reconciler.reconcileReady(ctx, md, dp)).To(Succeed())
+		Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should notify when the deployment fails, retrying failed deliveries", func() {
+		failures.Store(2)
+
+		reconcileErr := &degradedError{reason: "QuotaExceeded", message: "not enough GPUs"}
+		Expect(reconciler.reconcileDegraded(ctx, md, reconcileErr)).To(MatchError(reconcileErr))
+
+		var n notification
+		Eventually(received).Should(Receive(&n))
+		Expect(n.State).To(Equal("Failed"))
+		Expect(n.Reason).To(Equal("QuotaExceeded"))
+		Expect(n.Message).To(Equal("not enough GPUs"))
+
+		By("not notifying again for the same failure")
+		Expect(reconciler.reconcileDegraded(ctx, md, fmt.Errorf("still failing"))).NotTo(Succeed())
+		Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should drop notifications while too many are in flight", func() {
+		for range cap(notificationSenders) {
+			notificationSenders <- struct{}{}
+		}
+		DeferCleanup(func() {
+			for range cap(notificationSenders) {
+				<-notificationSenders
+			}
+		})
+
+		dp := &appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: 1}}
+		Expect(reconciler.reconcileReady(ctx, md, dp)).To(Succeed())
+		Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+	})
+})
@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	prefixCachePort          = 6379
+	defaultPrefixCacheImage  = "redis:7.4-alpine"
+	prefixCacheKVTransfer    = `{"kv_connector":"LMCacheConnectorV1","kv_role":"kv_both"}`
+	prefixCacheChunkSize     = "256"
+	prefixCacheContainerName = "cache"
+)
+
+var defaultPrefixCacheMaxMemory = resource.MustParse("1Gi")
+
+// prefixCacheLabels returns the labels of the cache pods. Unlike the names of
+// the cache objects they don't carry the resource name prefix, which would
+// change the selector of existing cache Deployments, but are shortened the
+// same way to fit a label value.
+func prefixCacheLabels(md *kaimeraaiv1.ModelDeployment) map[string]string {
+	return map[string]string{"app": kaimeraaiv1.PrefixedName("", md.PrefixCacheName())}
+}
+
+// prefixCacheEnv configures LMCache in the runtime to share the KV cache
+// through the companion cache instead of keeping it local to the replica
+func (r *ModelDeploymentReconciler) prefixCacheEnv(md *kaimeraaiv1.ModelDeployment) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "LMCACHE_LOCAL_CPU", Value: "False"},
+		{Name: "LMCACHE_CHUNK_SIZE", Value: prefixCacheChunkSize},
+		{Name: "LMCACHE_REMOTE_SERDE", Value: "naive"},
+		{Name: "LMCACHE_REMOTE_URL", Value: fmt.Sprintf("redis://%s.%s.svc:%d", r.resourceName(md.PrefixCacheName()), md.Namespace, prefixCachePort)},
+	}
+}
+
+// reconcilePrefixCache keeps the companion cache Deployment, Service and
+// NetworkPolicy in sync with the spec, and removes them once the cache is no
+// longer wanted
+func (r *ModelDeploymentReconciler) reconcilePrefixCache(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.PrefixCache == nil {
+		return r.deletePrefixCache(ctx, md)
+	}
+
+	deploy, err := r.generatePrefixCacheDeployment(md)
+	if err != nil {
+		return err
+	}
+
+	err = r.checkImageRegistries(deploy)
+	if err != nil {
+		return err
+	}
+	err = r.apply(ctx, deploy, &appsv1.Deployment{})
+	if err != nil {
+		return err
+	}
+
+	svc, err := r.generatePrefixCacheService(md)
+	if err != nil {
+		return err
+	}
+	err = r.apply(ctx, svc, &corev1.Service{})
+	if err != nil {
+		return err
+	}
+
+	policy, err := r.generatePrefixCachePolicy(md)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(ctx, policy, &networkingv1.NetworkPolicy{})
+}
+
+func (r *ModelDeploymentReconciler) deletePrefixCache(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	key := client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.PrefixCacheName())}
+	for _, obj := range []client.Object{&appsv1.Deployment{}, &corev1.Service{}, &networkingv1.NetworkPolicy{}} {
+		err := r.Get(ctx, key, obj)
+		if err != nil || !metav1.IsControlledBy(obj, md) {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+
+		log.FromContext(ctx).Info("deleting prefix cache object", "name", obj.GetName())
+		err = r.Delete(ctx, obj)
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ModelDeploymentReconciler) generatePrefixCacheDeployment(md *kaimeraaiv1.ModelDeployment) (*appsv1.Deployment, error) {
+	image := md.Spec.PrefixCache.Image
+	if image == "" {
+		image = defaultPrefixCacheImage
+	}
+	maxMemory := defaultPrefixCacheMaxMemory
+	if md.Spec.PrefixCache.MaxMemory != nil {
+		maxMemory = *md.Spec.PrefixCache.MaxMemory
+	}
+
+	podLabels := prefixCacheLabels(md)
+	replicas := int32(1)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.resourceName(md.PrefixCacheName()),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, podLabels),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  prefixCacheContainerName,
+							Image: image,
+							// The cache only holds recomputable KV blocks, so
+							// it is never persisted and evicts when full
+							Args: []string{
+								"--save", "",
+								"--appendonly", "no",
+								"--maxmemory", fmt.Sprintf("%d", maxMemory.Value()),
+								"--maxmemory-policy", "allkeys-lru",
+							},
+							Ports: []corev1.ContainerPort{
+								{Name: "redis", ContainerPort: prefixCachePort, Protocol: corev1.ProtocolTCP},
+							},
+							Resources: corev1.ResourceRequirements{
+								// No limit, so the server's own overhead
+								// above maxmemory does not get it OOM killed
+								Requests: corev1.ResourceList{corev1.ResourceMemory: maxMemory},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, deploy, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return deploy, nil
+}
+
+func (r *ModelDeploymentReconciler) generatePrefixCacheService(md *kaimeraaiv1.ModelDeployment) (*corev1.Service, error) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.resourceName(md.PrefixCacheName()),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: prefixCacheLabels(md),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "redis",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       prefixCachePort,
+					TargetPort: intstr.FromString("redis"),
+				},
+			},
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, svc, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// generatePrefixCachePolicy returns the NetworkPolicy admitting only the model
+// pods to the cache, as it serves without authentication
+func (r *ModelDeploymentReconciler) generatePrefixCachePolicy(md *kaimeraaiv1.ModelDeployment) (*networkingv1.NetworkPolicy, error) {
+	tcp := corev1.ProtocolTCP
+	cachePort := intstr.FromInt32(prefixCachePort)
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.resourceName(md.PrefixCacheName()),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: prefixCacheLabels(md)},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							PodSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{modelDeploymentLabel: md.Name},
+							},
+						},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &cachePort}},
+				},
+			},
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, policy, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
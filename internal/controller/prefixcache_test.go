@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment prefix cache", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		maxMemory := resource.MustParse("2Gi")
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "opt",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Replicas:  3,
+				PrefixCache: &kaimeraaiv1.PrefixCacheSpec{
+					MaxMemory: &maxMemory,
+				},
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should generate a single cache instance behind a service", func() {
+		deploy, err := reconciler.generatePrefixCacheDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Name).To(Equal("opt-prefix-cache"))
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(1))
+		container := deploy.Spec.Template.Spec.Containers[0]
+		Expect(container.Image).To(Equal(defaultPrefixCacheImage))
+		Expect(container.Args).To(ContainElements("--maxmemory", "2147483648", "--maxmemory-policy", "allkeys-lru"))
+		Expect(metav1.IsControlledBy(deploy, md)).To(BeTrue())
+
+		svc, err := reconciler.generatePrefixCacheService(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Name).To(Equal("opt-prefix-cache"))
+		Expect(labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(deploy.Spec.Template.Labels))).To(BeTrue())
+
+		By("keeping the cache out of the model service")
+		modelSvc, err := reconciler.generateService(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels.SelectorFromSet(modelSvc.Spec.Selector).Matches(labels.Set(deploy.Spec.Template.Labels))).To(BeFalse())
+	})
+
+	It("should point the runtime at the cache", func() {
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		container := deploy.Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(ContainElements("--kv-transfer-config", prefixCacheKVTransfer))
+		Expect(container.Env).To(ContainElements(
+			corev1.EnvVar{Name: "LMCACHE_REMOTE_URL", Value: "redis://opt-prefix-cache.default.svc:6379"},
+			corev1.EnvVar{Name: "LMCACHE_LOCAL_CPU", Value: "False"},
+		))
+
+		By("keeping the shadow's cache local")
+		md.Spec.Shadow = &kaimeraaiv1.ShadowSpec{ModelName: "facebook/opt-350m"}
+		shadow, err := reconciler.generateShadowDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shadow.Spec.Template.Spec.Containers[0].Command).NotTo(ContainElement("--kv-transfer-config"))
+	})
+
+	It("should remove the cache once it is no longer wanted", func() {
+		Expect(reconciler.reconcilePrefixCache(ctx, md)).To(Succeed())
+
+		key := client.ObjectKey{Namespace: md.Namespace, Name: md.PrefixCacheName()}
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &corev1.Service{})).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &networkingv1.NetworkPolicy{})).To(Succeed())
+
+		md.Spec.PrefixCache = nil
+		Expect(reconciler.reconcilePrefixCache(ctx, md)).To(Succeed())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &networkingv1.NetworkPolicy{}))).To(BeTrue())
+	})
+
+	It("should only admit the model pods to the cache", func() {
+		policy, err := reconciler.generatePrefixCachePolicy(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Name).To(Equal("opt-prefix-cache"))
+		Expect(policy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+
+		deploy, err := reconciler.generatePrefixCacheDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(deploy.Spec.Template.Labels))
+
+		model, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Spec.Ingress).To(HaveLen(1))
+		from := policy.Spec.Ingress[0].From
+		Expect(from).To(HaveLen(1))
+		Expect(labels.SelectorFromSet(from[0].PodSelector.MatchLabels).Matches(labels.Set(model.Spec.Template.Labels))).To(BeTrue())
+		Expect(policy.Spec.Ingress[0].Ports).To(ConsistOf(HaveField("Port.IntVal", int32(prefixCachePort))))
+	})
+
+	It("should shorten the cache label of long names", func() {
+		md.Name = "a-model-deployment-name-that-is-just-below-the-limit-for-names"
+		deploy, err := reconciler.generatePrefixCacheDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Spec.Template.Labels["app"]).To(HaveLen(63))
+		Expect(validation.IsValidLabelValue(deploy.Spec.Template.Labels["app"])).To(BeEmpty())
+	})
+})
@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// priorityQueue is a work queue handing out the item of highest priority
+// first, and items of equal priority in the order they were added. Like the
+// client-go queue, an item is queued at most once and is not handed out
+// again while it is being processed. Delayed and rate limited adds are left
+// to the client-go queues wrapping it.
+type priorityQueue struct {
+	cond     *sync.Cond
+	priority func(item interface{}) int
+	metrics  priorityQueueMetrics
+
+	queue        priorityHeap
+	seq          uint64
+	dirty        map[interface{}]int
+	processing   map[interface{}]time.Time
+	shuttingDown bool
+}
+
+var _ workqueue.Interface = &priorityQueue{}
+
+func newPriorityQueue(priority func(item interface{}) int, metrics priorityQueueMetrics) *priorityQueue {
+	return &priorityQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		priority:   priority,
+		metrics:    metrics,
+		dirty:      map[interface{}]int{},
+		processing: map[interface{}]time.Time{},
+	}
+}
+
+// Add queues the item unless it is already waiting. An item being processed
+// is queued again once it is done.
+func (q *priorityQueue) Add(item interface{}) {
+	// The priority may be read from the cache, so it is looked up unlocked
+	priority := q.priority(item)
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if _, ok := q.dirty[item]; ok {
+		return
+	}
+
+	q.metrics.adds.Inc()
+	q.dirty[item] = priority
+	if _, ok := q.processing[item]; ok {
+		return
+	}
+	q.push(item, priority)
+}
+
+func (q *priorityQueue) push(item interface{}, priority int) {
+	q.seq++
+	heap.Push(&q.queue, queuedItem{item: item, priority: priority, seq: q.seq, added: time.Now()})
+	q.metrics.depth.Inc()
+	q.cond.Signal()
+}
+
+func (q *priorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.queue.Len()
+}
+
+// Get blocks until an item is queued, and returns the one of highest
+// priority
+func (q *priorityQueue) Get() (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for q.queue.Len() == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if q.queue.Len() == 0 {
+		return nil, true
+	}
+
+	queued := heap.Pop(&q.queue).(queuedItem)
+	now := time.Now()
+	q.metrics.depth.Dec()
+	q.metrics.latency.Observe(now.Sub(queued.added).Seconds())
+	q.processing[queued.item] = now
+	delete(q.dirty, queued.item)
+	return queued.item, false
+}
+
+func (q *priorityQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	if started, ok := q.processing[item]; ok {
+		q.metrics.workDuration.Observe(time.Since(started).Seconds())
+		delete(q.processing, item)
+	}
+	if priority, ok := q.dirty[item]; ok {
+		q.push(item, priority)
+	} else if len(q.processing) == 0 {
+		q.cond.Broadcast()
+	}
+}
+
+func (q *priorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShutDownWithDrain shuts the queue down and waits for the items being
+// processed to be done
+func (q *priorityQueue) ShutDownWithDrain() {
+	q.ShutDown()
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for len(q.processing) > 0 {
+		q.cond.Wait()
+	}
+}
+
+func (q *priorityQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+// priorityQueueMetrics are the workqueue metrics the priority queue records.
+// The retries are recorded by the client-go queue wrapping it.
+type priorityQueueMetrics struct {
+	depth        workqueue.GaugeMetric
+	adds         workqueue.CounterMetric
+	latency      workqueue.HistogramMetric
+	workDuration workqueue.HistogramMetric
+}
+
+// noopQueueMetrics records nothing
+var noopQueueMetrics = priorityQueueMetrics{
+	depth:        noopMetric{},
+	adds:         noopMetric{},
+	latency:      noopMetric{},
+	workDuration: noopMetric{},
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Dec()            {}
+func (noopMetric) Observe(float64) {}
+
+// workqueueMetrics returns the metrics of the named queue, recorded to the
+// workqueue collectors controller-runtime registers for the default queues.
+// Registering a collector identical to a registered one hands back the
+// registered one. Should they ever differ, the queue goes unreported rather
+// than failing the controller.
+func workqueueMetrics(name string) priorityQueueMetrics {
+	depth := registeredCollector(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.DepthKey,
+		Help:      "Current depth of workqueue",
+	}, []string{"name"}))
+	adds := registeredCollector(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.AddsKey,
+		Help:      "Total number of adds handled by workqueue",
+	}, []string{"name"}))
+	latency := registeredCollector(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.QueueLatencyKey,
+		Help:      "How long in seconds an item stays in workqueue before being requested",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}, []string{"name"}))
+	workDuration := registeredCollector(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.WorkDurationKey,
+		Help:      "How long in seconds processing an item from workqueue takes.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}, []string{"name"}))
+
+	return priorityQueueMetrics{
+		depth:        depth.WithLabelValues(name),
+		adds:         adds.WithLabelValues(name),
+		latency:      latency.WithLabelValues(name),
+		workDuration: workDuration.WithLabelValues(name),
+	}
+}
+
+// registeredCollector registers c, returning the collector registered
+// in its place if there is one
+func registeredCollector[T prometheus.Collector](c T) T {
+	err := metrics.Registry.Register(c)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	return c
+}
+
+type queuedItem struct {
+	item     interface{}
+	priority int
+	seq      uint64
+	added    time.Time
+}
+
+// priorityHeap implements heap.Interface with the highest priority, then
+// the earliest added, item first
+type priorityHeap []queuedItem
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priorityHeap) Push(x interface{}) { *h = append(*h, x.(queuedItem)) }
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// newQueue returns the controller's queue, ordering ModelDeployments by their
+// reconcile priority annotation. The client-go queues wrapping the priority
+// queue hold back delayed and rate limited adds, keeping the earliest of an
+// item, and record the retries.
+func (r *ModelDeploymentReconciler) newQueue(name string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+	return workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{
+		DelayingQueue: workqueue.NewDelayingQueueWithConfig(workqueue.DelayingQueueConfig{
+			Name:  name,
+			Queue: newPriorityQueue(r.reconcilePriority, workqueueMetrics(name)),
+		}),
+	})
+}
+
+// reconcilePriority returns the priority of a queued request. ModelDeployments
+// that are gone or carry an invalid priority get the default of 0.
+func (r *ModelDeploymentReconciler) reconcilePriority(item interface{}) int {
+	req, ok := item.(reconcile.Request)
+	if !ok {
+		return 0
+	}
+
+	md := kaimeraaiv1.ModelDeployment{}
+	err := r.Get(context.Background(), req.NamespacedName, &md)
+	if err != nil {
+		return 0
+	}
+
+	value, ok := md.Annotations[kaimeraaiv1.ReconcilePriorityAnnotation]
+	if !ok {
+		return 0
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		ctrl.Log.WithName("priorityqueue").Error(err, "ignoring reconcile priority",
+			"modeldeployment", req.NamespacedName, "value", value)
+		return 0
+	}
+
+	return priority
+}
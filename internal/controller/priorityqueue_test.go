@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment priority queue", func() {
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+	}
+
+	prioritized := func(name, priority string) *kaimeraaiv1.ModelDeployment {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       kaimeraaiv1.ModelDeploymentSpec{ModelName: "facebook/opt-125m"},
+		}
+		if priority != "" {
+			md.Annotations = map[string]string{kaimeraaiv1.ReconcilePriorityAnnotation: priority}
+		}
+		return md
+	}
+
+	It("should hand out higher priority ModelDeployments first on a cold start", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				prioritized("batch", ""),
+				prioritized("chat", "10"),
+				prioritized("search", "5"),
+				prioritized("broken", "high"),
+				prioritized("archive", "-1"),
+			).Build(),
+			Scheme: scheme.Scheme,
+		}
+		queue := reconciler.newQueue("modeldeployment", workqueue.DefaultControllerRateLimiter())
+		defer queue.ShutDown()
+
+		for _, name := range []string{"batch", "archive", "chat", "broken", "search", "deleted"} {
+			queue.Add(request(name))
+		}
+
+		var order []string
+		for queue.Len() > 0 {
+			item, shutdown := queue.Get()
+			Expect(shutdown).To(BeFalse())
+			order = append(order, item.(reconcile.Request).Name)
+			queue.Done(item)
+		}
+		Expect(order).To(Equal([]string{"chat", "search", "batch", "broken", "deleted", "archive"}))
+	})
+
+	It("should not hand out an item again while it is processed", func() {
+		queue := newPriorityQueue(func(interface{}) int { return 0 }, noopQueueMetrics)
+		defer queue.ShutDown()
+
+		queue.Add("a")
+		queue.Add("a")
+		Expect(queue.Len()).To(Equal(1))
+
+		item, _ := queue.Get()
+		queue.Add("a")
+		Expect(queue.Len()).To(Equal(0))
+
+		queue.Done(item)
+		Expect(queue.Len()).To(Equal(1))
+	})
+
+	It("should stop handing out items once shut down", func() {
+		queue := newPriorityQueue(func(interface{}) int { return 0 }, noopQueueMetrics)
+		queue.ShutDown()
+
+		queue.Add("a")
+		item, shutdown := queue.Get()
+		Expect(item).To(BeNil())
+		Expect(shutdown).To(BeTrue())
+	})
+
+	It("should keep the earliest of the delayed adds of an item", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+			Scheme: scheme.Scheme,
+		}
+		queue := reconciler.newQueue("delayed", workqueue.DefaultControllerRateLimiter())
+		defer queue.ShutDown()
+
+		queue.AddAfter(request("chat"), time.Hour)
+		queue.AddAfter(request("chat"), 10*time.Millisecond)
+		queue.AddAfter(request("chat"), time.Hour)
+		Eventually(queue.Len).Should(Equal(1))
+
+		item, _ := queue.Get()
+		queue.Done(item)
+		Consistently(queue.Len, 100*time.Millisecond).Should(BeZero())
+	})
+
+	It("should record the workqueue metrics", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+			Scheme: scheme.Scheme,
+		}
+		queue := reconciler.newQueue("measured", workqueue.DefaultControllerRateLimiter())
+		defer queue.ShutDown()
+
+		queue.Add(request("chat"))
+		queue.Add(request("search"))
+		queue.AddRateLimited(request("chat"))
+
+		families, err := metrics.Registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		values := map[string]float64{}
+		for _, family := range families {
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "name" && label.GetValue() == "measured" {
+						values[family.GetName()] = metric.GetGauge().GetValue() + metric.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+		Expect(values).To(HaveKeyWithValue("workqueue_depth", 2.0))
+		Expect(values).To(HaveKeyWithValue("workqueue_adds_total", 2.0))
+		Expect(values).To(HaveKeyWithValue("workqueue_retries_total", 1.0))
+	})
+})
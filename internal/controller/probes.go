@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	probePeriodSeconds = 10
+	// Allow up to 30 minutes for the model to download and load before the
+	// liveness probe takes over
+	startupProbeFailureThreshold = 180
+
+	defaultGenerationPrompt         = "Hello"
+	defaultGenerationMaxTokens      = 1
+	defaultGenerationExpectedStatus = 200
+	defaultGenerationTimeoutSeconds = 10
+)
+
+// generationProbeScript POSTs the completion request given as second
+// argument to the runtime's completions endpoint at the first, and fails
+// unless it answers with the status given as third argument within the
+// timeout given as fifth, generating the text given as fourth, if any
+const generationProbeScript = `import json, os, sys, urllib.error, urllib.request
+url, body, status, text, timeout = sys.argv[1], sys.argv[2], int(sys.argv[3]), sys.argv[4], float(sys.argv[5])
+headers = {"Content-Type": "application/json"}
+if os.environ.get("VLLM_API_KEY"):
+    headers["Authorization"] = "Bearer " + os.environ["VLLM_API_KEY"]
+try:
+    response = urllib.request.urlopen(urllib.request.Request(url, data=body.encode(), headers=headers), timeout=timeout)
+    code, content = response.status, response.read().decode()
+except urllib.error.HTTPError as e:
+    code, content = e.code, e.read().decode()
+if code != status:
+    sys.exit("generation returned status %d, expected %d" % (code, status))
+if text and not any(text in choice.get("text", "") for choice in json.loads(content).get("choices", [])):
+    sys.exit("generation does not contain %r" % text)
+`
+
+// generateProbes returns the startup, readiness and liveness probes of the
+// runtime container listening on port, or nils when the spec configures none
+func generateProbes(md *kaimeraaiv1.ModelDeployment, port int32) (startup, readiness, liveness *corev1.Probe) {
+	if md.Spec.Probes == nil {
+		return nil, nil, nil
+	}
+
+	if len(md.Spec.Probes.ExecCommand) > 0 {
+		handler := corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: md.Spec.Probes.ExecCommand},
+		}
+		startup = &corev1.Probe{
+			ProbeHandler:     handler,
+			PeriodSeconds:    probePeriodSeconds,
+			FailureThreshold: startupProbeFailureThreshold,
+		}
+		readiness = &corev1.Probe{
+			ProbeHandler:  handler,
+			PeriodSeconds: probePeriodSeconds,
+		}
+		liveness = &corev1.Probe{
+			ProbeHandler:  handler,
+			PeriodSeconds: probePeriodSeconds,
+		}
+	}
+	if md.Spec.Probes.Generation != nil {
+		readiness = generateGenerationProbe(md, port)
+	}
+
+	return startup, readiness, liveness
+}
+
+// generateGenerationProbe returns the readiness probe passing once the
+// runtime listening on port completes the spec's test generation
+func generateGenerationProbe(md *kaimeraaiv1.ModelDeployment, port int32) *corev1.Probe {
+	generation := md.Spec.Probes.Generation
+
+	prompt := generation.Prompt
+	if prompt == "" {
+		prompt = defaultGenerationPrompt
+	}
+	maxTokens := generation.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultGenerationMaxTokens
+	}
+	status := generation.ExpectedStatus
+	if status == 0 {
+		status = defaultGenerationExpectedStatus
+	}
+	timeout := generation.TimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultGenerationTimeoutSeconds
+	}
+
+	// Marshalling a map of strings and numbers can't fail
+	body, _ := json.Marshal(map[string]interface{}{
+		"model":      md.Spec.ModelName,
+		"prompt":     prompt,
+		"max_tokens": maxTokens,
+	})
+
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{
+					"python3", "-c", generationProbeScript,
+					fmt.Sprintf("http://localhost:%d/v1/completions", port),
+					string(body),
+					strconv.Itoa(int(status)),
+					generation.ExpectedText,
+					strconv.Itoa(int(timeout)),
+				},
+			},
+		},
+		PeriodSeconds: probePeriodSeconds,
+		// The script gives up on the generation first, so its failure is
+		// reported rather than the probe's timeout
+		TimeoutSeconds: timeout + 1,
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// checkGPUQuota fails with a QuotaExceeded reason when a ResourceQuota of the
+// namespace doesn't leave enough GPUs for the replicas of a new deployment.
+// Extended resources such as GPUs are only limited through their requests.
+// Quotas whose scopes don't match the pods of the deployment are skipped.
+func (r *ModelDeploymentReconciler) checkGPUQuota(ctx context.Context, md *kaimeraaiv1.ModelDeployment, deploy *appsv1.Deployment) error {
+	gpus := requestedGPUs(md)
+	if !md.Spec.CheckGPUQuota || gpus == 0 {
+		return nil
+	}
+
+	quotas := corev1.ResourceQuotaList{}
+	err := r.List(ctx, &quotas, client.InNamespace(md.Namespace))
+	if err != nil {
+		return err
+	}
+
+	key := corev1.ResourceName("requests." + string(md.Spec.GPUResource()))
+	needed := resource.NewQuantity(int64(gpus)*int64(*deploy.Spec.Replicas), resource.DecimalSI)
+	for _, quota := range quotas.Items {
+		hard, ok := quota.Status.Hard[key]
+		if !ok || !quotaMatchesPod(&quota, &deploy.Spec.Template.Spec) {
+			continue
+		}
+
+		available := hard.DeepCopy()
+		if used, ok := quota.Status.Used[key]; ok {
+			available.Sub(used)
+		}
+		if available.Cmp(*needed) < 0 {
+			return &degradedError{
+				reason: "QuotaExceeded",
+				message: fmt.Sprintf("resource quota %q has %s of %s available, %s needed",
+					quota.Name, available.String(), key, needed.String()),
+			}
+		}
+	}
+
+	return nil
+}
+
+// quotaMatchesPod reports whether a ResourceQuota counts pods with the given
+// spec, which takes all of its scopes and scope selector expressions to
+// match
+func quotaMatchesPod(quota *corev1.ResourceQuota, pod *corev1.PodSpec) bool {
+	var requirements []corev1.ScopedResourceSelectorRequirement
+	for _, scope := range quota.Spec.Scopes {
+		requirements = append(requirements, corev1.ScopedResourceSelectorRequirement{
+			ScopeName: scope,
+			Operator:  corev1.ScopeSelectorOpExists,
+		})
+	}
+	if quota.Spec.ScopeSelector != nil {
+		requirements = append(requirements, quota.Spec.ScopeSelector.MatchExpressions...)
+	}
+
+	for _, requirement := range requirements {
+		if !scopeMatchesPod(requirement, pod) {
+			return false
+		}
+	}
+	return true
+}
+
+func scopeMatchesPod(requirement corev1.ScopedResourceSelectorRequirement, pod *corev1.PodSpec) bool {
+	switch requirement.ScopeName {
+	case corev1.ResourceQuotaScopeTerminating:
+		return pod.ActiveDeadlineSeconds != nil
+	case corev1.ResourceQuotaScopeNotTerminating:
+		return pod.ActiveDeadlineSeconds == nil
+	case corev1.ResourceQuotaScopeBestEffort:
+		return podBestEffort(pod)
+	case corev1.ResourceQuotaScopeNotBestEffort:
+		return !podBestEffort(pod)
+	case corev1.ResourceQuotaScopeCrossNamespacePodAffinity:
+		// The generated pods have no affinity terms across namespaces
+		return false
+	case corev1.ResourceQuotaScopePriorityClass:
+		switch requirement.Operator {
+		case corev1.ScopeSelectorOpIn:
+			return slices.Contains(requirement.Values, pod.PriorityClassName)
+		case corev1.ScopeSelectorOpNotIn:
+			return !slices.Contains(requirement.Values, pod.PriorityClassName)
+		case corev1.ScopeSelectorOpExists:
+			return pod.PriorityClassName != ""
+		case corev1.ScopeSelectorOpDoesNotExist:
+			return pod.PriorityClassName == ""
+		}
+	}
+
+	// Count the pods against quotas with scopes this version doesn't know
+	return true
+}
+
+// podBestEffort reports whether no container of the pod requests or limits
+// any resource
+func podBestEffort(pod *corev1.PodSpec) bool {
+	for _, container := range slices.Concat(pod.InitContainers, pod.Containers) {
+		if len(container.Resources.Requests) > 0 || len(container.Resources.Limits) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment GPU quota", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+	var quota *corev1.ResourceQuota
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "quota",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:     "facebook/opt-125m",
+				Runtime:       "gpu",
+				GPUCount:      2,
+				Replicas:      2,
+				CheckGPUQuota: true,
+			},
+		}
+
+		quota = &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "gpus",
+				Namespace: "default",
+			},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("8")},
+				Used: corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("6")},
+			},
+		}
+	})
+
+	newReconciler := func() *ModelDeploymentReconciler {
+		return &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, quota).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+	}
+
+	It("should not create the deployment when the quota is exceeded", func() {
+		reconciler := newReconciler()
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("QuotaExceeded"))
+		Expect(cond.Message).To(ContainSubstring(`"gpus"`))
+
+		err = reconciler.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should create the deployment when the quota leaves enough GPUs", func() {
+		quota.Status.Used = corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("4")}
+		reconciler := newReconciler()
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+	})
+
+	It("should ignore the quota unless asked to check it", func() {
+		md.Spec.CheckGPUQuota = false
+		reconciler := newReconciler()
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.checkGPUQuota(ctx, md, deploy)).To(Succeed())
+	})
+
+	It("should only count quotas whose scopes match the pods", func() {
+		quota.Spec.ScopeSelector = &corev1.ScopeSelector{
+			MatchExpressions: []corev1.ScopedResourceSelectorRequirement{{
+				ScopeName: corev1.ResourceQuotaScopePriorityClass,
+				Operator:  corev1.ScopeSelectorOpIn,
+				Values:    []string{"batch"},
+			}},
+		}
+		reconciler := newReconciler()
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.checkGPUQuota(ctx, md, deploy)).To(Succeed())
+
+		By("counting the quota of the pods' priority class")
+		deploy.Spec.Template.Spec.PriorityClassName = "batch"
+		Expect(reconciler.checkGPUQuota(ctx, md, deploy)).To(MatchError(ContainSubstring(`"gpus"`)))
+
+		By("skipping quotas of pods without requests")
+		quota.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}
+		Expect(quotaMatchesPod(quota, &deploy.Spec.Template.Spec)).To(BeFalse())
+		quota.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotBestEffort, corev1.ResourceQuotaScopeNotTerminating}
+		Expect(quotaMatchesPod(quota, &deploy.Spec.Template.Spec)).To(BeTrue())
+	})
+})
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// reconcileRampUp advances the ramp replica count recorded in status
+func (r *ModelDeploymentReconciler) reconcileRampUp(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) error {
+	next := nextRampReplicas(md, dp)
+	if next == md.Status.RampReplicas {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("ramping replicas", "from", md.Status.RampReplicas, "to", next, "target", desiredReplicas(md))
+	md.Status.RampReplicas = next
+	return r.updateStatus(ctx, md)
+}
+
+// nextRampReplicas returns the replica count to roll out next. A new
+// deployment starts with a single replica and gains one more each time all
+// of its current replicas are ready, until it reaches the desired count.
+// Scaling down is applied immediately.
+func nextRampReplicas(md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) int32 {
+	desired := desiredReplicas(md)
+	if desired == 0 {
+		desired = 1
+	}
+
+	if dp == nil || dp.Spec.Replicas == nil {
+		return 1
+	}
+
+	current := *dp.Spec.Replicas
+	if current >= desired {
+		return desired
+	}
+	if deploymentReady(dp) {
+		return current + 1
+	}
+
+	return current
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment replica ramp up", func() {
+	deployment := func(replicas int32, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+
+	It("should add one replica each time the current replicas are ready", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				Replicas: 3,
+				RampUp:   true,
+			},
+		}
+
+		By("starting with a single replica")
+		Expect(nextRampReplicas(md, nil)).To(BeEquivalentTo(1))
+
+		By("waiting while replicas are not ready")
+		Expect(nextRampReplicas(md, deployment(1, 0))).To(BeEquivalentTo(1))
+
+		By("stepping up as replicas become ready")
+		Expect(nextRampReplicas(md, deployment(1, 1))).To(BeEquivalentTo(2))
+		Expect(nextRampReplicas(md, deployment(2, 1))).To(BeEquivalentTo(2))
+		Expect(nextRampReplicas(md, deployment(2, 2))).To(BeEquivalentTo(3))
+
+		By("stopping at the desired replicas")
+		Expect(nextRampReplicas(md, deployment(3, 3))).To(BeEquivalentTo(3))
+	})
+
+	It("should scale down immediately", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				Replicas: 2,
+				RampUp:   true,
+			},
+		}
+
+		Expect(nextRampReplicas(md, deployment(4, 1))).To(BeEquivalentTo(2))
+	})
+
+	It("should roll out the ramped replica count", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Replicas:  3,
+				RampUp:    true,
+			},
+			Status: kaimeraaiv1.ModelDeploymentStatus{
+				RampReplicas: 2,
+			},
+		}
+		md.Name = "ramp"
+		md.Namespace = "default"
+
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(2))
+	})
+})
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// minReadyReplicas returns how many replicas must be ready for the
+// ModelDeployment to be Ready
+func minReadyReplicas(md *kaimeraaiv1.ModelDeployment) int32 {
+	if md.Spec.MinReadyReplicas > 0 {
+		return md.Spec.MinReadyReplicas
+	}
+
+	return 1
+}
+
+// reconcileReady sets the Ready condition and the phase from the ready
+// replicas of the deployment. While the model cache claim waits to be bound
+// the pods cannot start, so that is reported instead of missing replicas.
+func (r *ModelDeploymentReconciler) reconcileReady(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) error {
+	minReady := minReadyReplicas(md)
+	cond := metav1.Condition{
+		Type:               kaimeraaiv1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "MinimumReplicasReady",
+		Message:            fmt.Sprintf("%d of the minimum %d replicas are ready", dp.Status.ReadyReplicas, minReady),
+		ObservedGeneration: md.Generation,
+	}
+	phase := kaimeraaiv1.PhaseReady
+	if dp.Status.ReadyReplicas < minReady {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "MinimumReplicasUnavailable"
+		phase = kaimeraaiv1.PhaseProgressing
+
+		binding, err := r.modelCacheBinding(ctx, md)
+		if err != nil {
+			return err
+		}
+		if binding != "" {
+			cond.Reason = string(kaimeraaiv1.PhaseBinding)
+			cond.Message = binding
+			phase = kaimeraaiv1.PhaseBinding
+		}
+	}
+
+	becameReady := cond.Status == metav1.ConditionTrue &&
+		!meta.IsStatusConditionTrue(md.Status.Conditions, kaimeraaiv1.ConditionReady)
+	changed := meta.SetStatusCondition(&md.Status.Conditions, cond)
+	if md.Status.Phase != phase {
+		md.Status.Phase = phase
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	err := r.updateStatus(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	if becameReady {
+		r.notify(ctx, md, notificationReady, cond.Reason, cond.Message)
+	}
+	return nil
+}
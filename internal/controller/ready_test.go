@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment Ready condition", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "ready",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				Replicas: 3,
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	readyStatus := func(ready int32) metav1.ConditionStatus {
+		dp := &appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: ready}}
+		Expect(reconciler.reconcileReady(ctx, md, dp)).To(Succeed())
+		return meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionReady).Status
+	}
+
+	It("should be ready with a single ready replica by default", func() {
+		Expect(readyStatus(0)).To(Equal(metav1.ConditionFalse))
+		Expect(readyStatus(1)).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should wait for the minimum ready replicas", func() {
+		md.Spec.MinReadyReplicas = 2
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		Expect(readyStatus(1)).To(Equal(metav1.ConditionFalse))
+		Expect(readyStatus(2)).To(Equal(metav1.ConditionTrue))
+
+		By("turning unready when replicas drop below the minimum")
+		Expect(readyStatus(1)).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("should report the binding phase until the model cache claim is bound", func() {
+		md.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{ClaimName: "models"}
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+
+		observe := func(ready int32) (kaimeraaiv1.ModelDeploymentPhase, string) {
+			readyStatus(ready)
+			return md.Status.Phase, meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionReady).Reason
+		}
+
+		phase, reason := observe(0)
+		Expect(phase).To(Equal(kaimeraaiv1.PhaseBinding))
+		Expect(reason).To(Equal("Binding"))
+
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		}
+		Expect(reconciler.Create(ctx, pvc)).To(Succeed())
+		phase, reason = observe(0)
+		Expect(phase).To(Equal(kaimeraaiv1.PhaseBinding))
+		Expect(reason).To(Equal("Binding"))
+
+		By("moving on once a consumer is scheduled")
+		pvc.Annotations = map[string]string{selectedNodeAnnotation: "node-a"}
+		Expect(reconciler.Update(ctx, pvc)).To(Succeed())
+		phase, reason = observe(0)
+		Expect(phase).To(Equal(kaimeraaiv1.PhaseProgressing))
+		Expect(reason).To(Equal("MinimumReplicasUnavailable"))
+
+		phase, reason = observe(1)
+		Expect(phase).To(Equal(kaimeraaiv1.PhaseReady))
+		Expect(reason).To(Equal("MinimumReplicasReady"))
+	})
+})
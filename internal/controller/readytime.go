@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// reconcileReadyTime records when a rollout started and how long it took to
+// become ready. A new generation of the spec starts a new rollout and resets
+// the recorded times.
+func (r *ModelDeploymentReconciler) reconcileReadyTime(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) error {
+	if md.Status.RolloutGeneration != md.Generation || md.Status.DeploymentStartTime == nil {
+		start := r.now()
+		md.Status.RolloutGeneration = md.Generation
+		md.Status.DeploymentStartTime = &start
+		md.Status.ReadyTime = nil
+		md.Status.TimeToReady = nil
+		return r.updateStatus(ctx, md)
+	}
+
+	if md.Status.ReadyTime != nil || !rolloutComplete(dp) {
+		return nil
+	}
+
+	ready := r.now()
+	md.Status.ReadyTime = &ready
+	md.Status.TimeToReady = &metav1.Duration{Duration: ready.Sub(md.Status.DeploymentStartTime.Time)}
+	return r.updateStatus(ctx, md)
+}
+
+// rolloutComplete reports whether the deployment has rolled out its latest
+// template and all replicas are ready.
+func rolloutComplete(dp *appsv1.Deployment) bool {
+	if dp.Status.ObservedGeneration < dp.Generation || dp.Spec.Replicas == nil {
+		return false
+	}
+
+	return dp.Status.UpdatedReplicas >= *dp.Spec.Replicas && deploymentReady(dp)
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment time to ready", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var fakeClock *clocktesting.FakePassiveClock
+	var md *kaimeraaiv1.ModelDeployment
+	var dp *appsv1.Deployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "ready",
+				Namespace:  "default",
+				Generation: 1,
+			},
+		}
+
+		replicas := int32(2)
+		dp = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+
+		fakeClock = clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+			Clock:  fakeClock,
+		}
+	})
+
+	It("should compute the time to ready once the rollout completes", func() {
+		By("recording the start of the rollout")
+		Expect(reconciler.reconcileReadyTime(ctx, md, dp)).To(Succeed())
+		Expect(md.Status.DeploymentStartTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(md.Status.ReadyTime).To(BeNil())
+
+		By("waiting while replicas are not ready")
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+		dp.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 2, ReadyReplicas: 1}
+		Expect(reconciler.reconcileReadyTime(ctx, md, dp)).To(Succeed())
+		Expect(md.Status.ReadyTime).To(BeNil())
+
+		By("recording the ready time")
+		fakeClock.SetTime(fakeClock.Now().Add(4 * time.Minute))
+		dp.Status.ReadyReplicas = 2
+		Expect(reconciler.reconcileReadyTime(ctx, md, dp)).To(Succeed())
+		Expect(md.Status.ReadyTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(md.Status.TimeToReady.Duration).To(Equal(5 * time.Minute))
+	})
+
+	It("should reset the times on a new rollout", func() {
+		dp.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 2, ReadyReplicas: 2}
+		Expect(reconciler.reconcileReadyTime(ctx, md, dp)).To(Succeed())
+		Expect(reconciler.reconcileReadyTime(ctx, md, dp)).To(Succeed())
+		Expect(md.Status.TimeToReady).NotTo(BeNil())
+
+		fakeClock.SetTime(fakeClock.Now().Add(time.Hour))
+		md.Generation = 2
+		Expect(reconciler.reconcileReadyTime(ctx, md, dp)).To(Succeed())
+		Expect(md.Status.DeploymentStartTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(md.Status.RolloutGeneration).To(BeEquivalentTo(2))
+		Expect(md.Status.ReadyTime).To(BeNil())
+		Expect(md.Status.TimeToReady).To(BeNil())
+		Expect(md.Status.ObservedGeneration).To(BeZero())
+	})
+})
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	// revisionAnnotation is set on ReplicaSets by the Deployment controller
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+
+	// defaultRevisionHistoryLimit matches the Deployment default
+	defaultRevisionHistoryLimit = 10
+)
+
+// pruneReplicaSets deletes the oldest scaled down ReplicaSets of the
+// Deployment until no more than revisionHistoryLimit are left
+func (r *ModelDeploymentReconciler) pruneReplicaSets(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) error {
+	limit := int32(defaultRevisionHistoryLimit)
+	if md.Spec.RevisionHistoryLimit != nil {
+		limit = *md.Spec.RevisionHistoryLimit
+	}
+
+	replicaSets := appsv1.ReplicaSetList{}
+	err := r.List(ctx, &replicaSets, client.InNamespace(dp.Namespace), client.MatchingLabels(dp.Spec.Selector.MatchLabels))
+	if err != nil {
+		return err
+	}
+
+	var old []appsv1.ReplicaSet
+	for _, rs := range replicaSets.Items {
+		if !metav1.IsControlledBy(&rs, dp) || rs.DeletionTimestamp != nil {
+			continue
+		}
+		if rs.Spec.Replicas != nil && *rs.Spec.Replicas > 0 || rs.Status.Replicas > 0 {
+			continue
+		}
+		old = append(old, rs)
+	}
+	if len(old) <= int(limit) {
+		return nil
+	}
+
+	sort.Slice(old, func(i, j int) bool {
+		return replicaSetRevision(&old[i]) < replicaSetRevision(&old[j])
+	})
+	for i := range old[:len(old)-int(limit)] {
+		log.FromContext(ctx).Info("deleting stale replicaset", "replicaset", old[i].Name)
+		err := r.Delete(ctx, &old[i])
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func replicaSetRevision(rs *appsv1.ReplicaSet) int64 {
+	revision, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return revision
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment ReplicaSet pruning", func() {
+	ctx := context.Background()
+
+	It("should delete scaled down ReplicaSets beyond the history limit", func() {
+		limit := int32(1)
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "pruned", Namespace: "default"},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:            "facebook/opt-125m",
+				RevisionHistoryLimit: &limit,
+				PruneReplicaSets:     true,
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+		dp, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dp.Spec.RevisionHistoryLimit).To(Equal(&limit))
+		dp.UID = types.UID("deployment-uid")
+
+		replicaSet := func(revision int, replicas int32) *appsv1.ReplicaSet {
+			controller := true
+			return &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        fmt.Sprintf("pruned-%d", revision),
+					Namespace:   "default",
+					Labels:      dp.Spec.Selector.MatchLabels,
+					Annotations: map[string]string{revisionAnnotation: fmt.Sprintf("%d", revision)},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       dp.Name,
+						UID:        dp.UID,
+						Controller: &controller,
+					}},
+				},
+				Spec: appsv1.ReplicaSetSpec{Replicas: &replicas},
+			}
+		}
+		reconciler.Client = fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(replicaSet(1, 0), replicaSet(2, 0), replicaSet(3, 0), replicaSet(4, 1)).
+			Build()
+
+		Expect(reconciler.pruneReplicaSets(ctx, md, dp)).To(Succeed())
+
+		replicaSets := &appsv1.ReplicaSetList{}
+		Expect(reconciler.List(ctx, replicaSets, client.InNamespace("default"))).To(Succeed())
+		Expect(replicaSets.Items).To(ConsistOf(
+			HaveField("Name", "pruned-3"),
+			HaveField("Name", "pruned-4"),
+		))
+	})
+})
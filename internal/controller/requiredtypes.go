@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// requiredType is a kind the controller manages on behalf of a feature
+type requiredType struct {
+	feature string
+	gvk     schema.GroupVersionKind
+
+	// unstructured kinds are not in the scheme, so they only need to be
+	// served by the API server, e.g. through a third party CRD
+	unstructured bool
+}
+
+// requiredTypes lists the kinds needed to reconcile the features requested
+// by the spec
+func requiredTypes(md *kaimeraaiv1.ModelDeployment) []requiredType {
+	types := []requiredType{
+		{feature: "deployment", gvk: appsv1.SchemeGroupVersion.WithKind("Deployment")},
+		{feature: "service", gvk: corev1.SchemeGroupVersion.WithKind("Service")},
+		{feature: "manifest", gvk: corev1.SchemeGroupVersion.WithKind("ConfigMap")},
+		{feature: "connection", gvk: corev1.SchemeGroupVersion.WithKind("Secret")},
+	}
+	if md.Spec.Autoscaling != nil {
+		types = append(types, requiredType{feature: "autoscaling", gvk: autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler")})
+	}
+	if md.Spec.WorkloadType == kaimeraaiv1.WorkloadTypeRollout {
+		types = append(types, requiredType{feature: "rollout", gvk: rolloutGVK, unstructured: true})
+	}
+	if md.Spec.WorkloadType == kaimeraaiv1.WorkloadTypeStatefulSet {
+		types = append(types, requiredType{feature: "statefulSet", gvk: appsv1.SchemeGroupVersion.WithKind("StatefulSet")})
+	}
+	if md.Spec.SmokeTest {
+		types = append(types, requiredType{feature: "smokeTest", gvk: batchv1.SchemeGroupVersion.WithKind("Job")})
+	}
+
+	return types
+}
+
+// reconcileRequiredTypes checks that the scheme knows every kind the spec
+// needs, or that the API server serves it for unstructured kinds, and records
+// the result in the TypesRegistered condition. It reports false when a kind
+// is missing, in which case nothing else should be reconciled as it would
+// only fail further down.
+func (r *ModelDeploymentReconciler) reconcileRequiredTypes(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (bool, error) {
+	var missing []string
+	for _, t := range requiredTypes(md) {
+		if !t.unstructured {
+			if !r.Scheme.Recognizes(t.gvk) {
+				missing = append(missing, fmt.Sprintf("%s requires %s, which is not registered", t.feature, t.gvk))
+			}
+			continue
+		}
+
+		_, err := r.RESTMapper().RESTMapping(t.gvk.GroupKind(), t.gvk.Version)
+		if meta.IsNoMatchError(err) {
+			missing = append(missing, fmt.Sprintf("%s requires %s, which is not installed", t.feature, t.gvk))
+		} else if err != nil {
+			return false, err
+		}
+	}
+
+	cond := metav1.Condition{
+		Type:               kaimeraaiv1.ConditionTypesRegistered,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Registered",
+		Message:            "all required types are registered",
+		ObservedGeneration: md.Generation,
+	}
+	if len(missing) > 0 {
+		log.FromContext(ctx).Info("required types are not registered", "missing", missing)
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "MissingType"
+		cond.Message = strings.Join(missing, "; ")
+	}
+
+	if meta.SetStatusCondition(&md.Status.Conditions, cond) {
+		err := r.updateStatus(ctx, md)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return len(missing) == 0, nil
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment required types", func() {
+	ctx := context.Background()
+
+	It("should report a feature whose type is not registered", func() {
+		// batch/v1 is left out so the smoke test Job can't be managed
+		partialScheme := runtime.NewScheme()
+		Expect(kaimeraaiv1.AddToScheme(partialScheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(partialScheme)).To(Succeed())
+		Expect(corev1.AddToScheme(partialScheme)).To(Succeed())
+
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "unregistered",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				SmokeTest: true,
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(partialScheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: partialScheme,
+		}
+
+		key := types.NamespacedName{Namespace: md.Namespace, Name: md.Name}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionTypesRegistered)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("MissingType"))
+		Expect(cond.Message).To(Equal("smokeTest requires batch/v1, Kind=Job, which is not registered"))
+
+		dp := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, dp)).NotTo(Succeed())
+	})
+})
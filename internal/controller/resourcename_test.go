@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment resource name prefix", func() {
+	ctx := context.Background()
+
+	It("should prefix the names of the generated resources", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "named",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Autoscaling: &kaimeraaiv1.AutoscalingSpec{
+					MaxReplicas: 3,
+				},
+			},
+		}
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:             scheme.Scheme,
+			ResourceNamePrefix: "ml-",
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		prefixed := client.ObjectKey{Namespace: md.Namespace, Name: "ml-named"}
+		deploy := appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, prefixed, &deploy)).To(Succeed())
+		Expect(deploy.Spec.Template.Labels).To(HaveKeyWithValue("app", "named"))
+		Expect(reconciler.Get(ctx, prefixed, &corev1.Service{})).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: "ml-named-connection"}, &corev1.Secret{})).To(Succeed())
+
+		hpa, err := reconciler.generateHPA(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hpa.Name).To(Equal("ml-named"))
+		Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal("ml-named"))
+
+		Expect(reconciler.serviceEndpoint(md)).To(Equal("http://ml-named.default:80"))
+
+		By("updating the prefixed Deployment on the next reconcile")
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		err = reconciler.Get(ctx, key, &appsv1.Deployment{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should keep long names valid and unique", func() {
+		long := strings.Repeat("a", 60)
+		first := kaimeraaiv1.PrefixedName("ml-", long+"-shadow")
+		second := kaimeraaiv1.PrefixedName("ml-", long+"-cpu-fallback")
+
+		for _, name := range []string{first, second} {
+			Expect(len(name)).To(BeNumerically("<=", 63))
+			Expect(validation.IsDNS1035Label(name)).To(BeEmpty())
+			Expect(name).To(HavePrefix("ml-aaa"))
+		}
+		Expect(first).NotTo(Equal(second))
+
+		Expect(kaimeraaiv1.PrefixedName("ml-", "named")).To(Equal("ml-named"))
+		Expect(kaimeraaiv1.PrefixedName("", "named")).To(Equal("named"))
+	})
+})
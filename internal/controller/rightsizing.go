@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	defaultRightsizingInterval = 5 * time.Minute
+
+	// rightsizingQueryTimeout bounds the queries to Prometheus, which run
+	// within the reconcile
+	rightsizingQueryTimeout = 30 * time.Second
+)
+
+// Queries of the request rate and 95th percentile latency of the model, from
+// the metrics vLLM exports, formatted with the selector of the model's
+// Service and the window
+const (
+	requestRateQuery = `sum(rate(vllm:request_success_total{%s}[%s]))`
+	latencyQuery     = `histogram_quantile(0.95, sum by (le) (rate(vllm:e2e_request_latency_seconds_bucket{%s}[%s])))`
+)
+
+// rightsizingInterval returns the time between two rightsizing decisions
+func rightsizingInterval(md *kaimeraaiv1.ModelDeployment) time.Duration {
+	if md.Spec.Rightsizing.Interval != nil {
+		return md.Spec.Rightsizing.Interval.Duration
+	}
+
+	return defaultRightsizingInterval
+}
+
+// rightsizingMinReplicas returns the lower bound of the rightsized replica
+// count
+func rightsizingMinReplicas(md *kaimeraaiv1.ModelDeployment) int32 {
+	if md.Spec.Rightsizing.MinReplicas != nil {
+		return *md.Spec.Rightsizing.MinReplicas
+	}
+
+	return 1
+}
+
+// rightsizedReplicas returns the replica count last decided on, or the
+// lower bound until the first decision
+func rightsizedReplicas(md *kaimeraaiv1.ModelDeployment) int32 {
+	if md.Status.Rightsizing != nil {
+		return md.Status.Rightsizing.Replicas
+	}
+
+	return rightsizingMinReplicas(md)
+}
+
+// reconcileRightsizing decides on the replica count once per interval from
+// the request rate and latency of the model, and records the decision in
+// the status. It returns when to decide again. Failing to query Prometheus
+// keeps the current replica count, so an outage of the monitoring doesn't
+// hold back the rest of the reconcile.
+func (r *ModelDeploymentReconciler) reconcileRightsizing(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (time.Duration, error) {
+	if md.Spec.Rightsizing == nil {
+		if md.Status.Rightsizing == nil && md.Status.RightsizingQueryTime == nil {
+			return 0, nil
+		}
+		md.Status.Rightsizing = nil
+		md.Status.RightsizingQueryTime = nil
+		return 0, r.updateStatus(ctx, md)
+	}
+
+	interval := rightsizingInterval(md)
+	now := r.now()
+	if last := md.Status.RightsizingQueryTime; last != nil {
+		if wait := last.Add(interval).Sub(now.Time); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	md.Status.RightsizingQueryTime = &now
+	rate, latency, err := r.queryRequestMetrics(ctx, md, now.Time, interval)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to query the request metrics for rightsizing")
+		r.warningEvent(md, "RightsizingFailed", fmt.Sprintf("querying %s: %v", md.Spec.Rightsizing.PrometheusURL, err))
+		return interval, r.updateStatus(ctx, md)
+	}
+
+	current := rightsizedReplicas(md)
+	replicas, reason := rightsize(md.Spec.Rightsizing, current, rate, latency)
+	if replicas != current {
+		log.FromContext(ctx).Info("rightsizing replicas", "from", current, "to", replicas, "reason", reason)
+	}
+
+	status := &kaimeraaiv1.RightsizingStatus{
+		Replicas:     replicas,
+		RequestRate:  *resource.NewMilliQuantity(int64(math.Round(rate*1000)), resource.DecimalSI),
+		Reason:       reason,
+		DecisionTime: now,
+	}
+	if latency != nil {
+		status.Latency = &metav1.Duration{Duration: *latency}
+	}
+	md.Status.Rightsizing = status
+	return interval, r.updateStatus(ctx, md)
+}
+
+// rightsize returns the replica count for the request rate and latency, if
+// any request completed, and what it was decided on. Replicas are sized for
+// the target request rate, and one is added while the latency exceeds the
+// maximum. The count only drops by one replica per decision, so a lull
+// doesn't take away the capacity at once.
+func rightsize(spec *kaimeraaiv1.RightsizingSpec, current int32, rate float64, latency *time.Duration) (int32, string) {
+	target := spec.TargetRequestRate.AsApproximateFloat64()
+	replicas := int32(math.Ceil(rate / target))
+	reason := "RequestRate"
+
+	if latency != nil && spec.MaxLatency != nil && *latency > spec.MaxLatency.Duration && replicas <= current {
+		replicas = current + 1
+		reason = "Latency"
+	}
+	if replicas < current-1 {
+		replicas = current - 1
+	}
+
+	minReplicas := int32(1)
+	if spec.MinReplicas != nil {
+		minReplicas = *spec.MinReplicas
+	}
+	if replicas < minReplicas {
+		return minReplicas, "MinReplicas"
+	}
+	if replicas > spec.MaxReplicas {
+		return spec.MaxReplicas, "MaxReplicas"
+	}
+
+	return replicas, reason
+}
+
+// queryRequestMetrics returns the request rate of the model over the
+// window, and the latency if any request completed, giving up after the
+// query timeout
+func (r *ModelDeploymentReconciler) queryRequestMetrics(ctx context.Context, md *kaimeraaiv1.ModelDeployment, now time.Time, window time.Duration) (float64, *time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, rightsizingQueryTimeout)
+	defer cancel()
+
+	client, err := promapi.NewClient(promapi.Config{Address: md.Spec.Rightsizing.PrometheusURL})
+	if err != nil {
+		return 0, nil, err
+	}
+	api := promv1.NewAPI(client)
+
+	selector := fmt.Sprintf(`namespace=%q,service=%q`, md.Namespace, r.resourceName(md.Name))
+	rangeSelector := model.Duration(window).String()
+
+	rate, ok, err := queryScalar(ctx, api, fmt.Sprintf(requestRateQuery, selector, rangeSelector), now)
+	if err != nil || !ok {
+		// No samples means no requests
+		return 0, nil, err
+	}
+
+	seconds, ok, err := queryScalar(ctx, api, fmt.Sprintf(latencyQuery, selector, rangeSelector), now)
+	if err != nil || !ok {
+		return rate, nil, err
+	}
+	latency := time.Duration(seconds * float64(time.Second))
+	return rate, &latency, nil
+}
+
+// queryScalar returns the value of the single sample a query evaluates to,
+// and false if it has none or the value isn't a number, e.g. a quantile of
+// no observations
+func queryScalar(ctx context.Context, api promv1.API, query string, now time.Time) (float64, bool, error) {
+	result, _, err := api.Query(ctx, query, now)
+	if err != nil {
+		return 0, false, err
+	}
+
+	vector, ok := result.(model.Vector)
+	if !ok || len(vector) == 0 {
+		return 0, false, nil
+	}
+
+	value := float64(vector[0].Value)
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false, nil
+	}
+	return value, true, nil
+}
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// stubPrometheus answers instant queries of the request rate and latency
+// with the given values, or no samples when empty
+type stubPrometheus struct {
+	rate    string
+	latency string
+	queries []string
+}
+
+func (p *stubPrometheus) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/api/v1/query" {
+		http.NotFound(w, req)
+		return
+	}
+	query := req.FormValue("query")
+	p.queries = append(p.queries, query)
+
+	value := p.rate
+	if strings.HasPrefix(query, "histogram_quantile") {
+		value = p.latency
+	}
+	result := "[]"
+	if value != "" {
+		result = fmt.Sprintf(`[{"metric":{},"value":[1704067200,%q]}]`, value)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":%s}}`, result)
+}
+
+var _ = Describe("ModelDeployment rightsizing", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var fakeClock *clocktesting.FakePassiveClock
+	var md *kaimeraaiv1.ModelDeployment
+	var prometheus *stubPrometheus
+
+	BeforeEach(func() {
+		prometheus = &stubPrometheus{}
+		server := httptest.NewServer(prometheus)
+		DeferCleanup(server.Close)
+
+		maxLatency := metav1.Duration{Duration: 10 * time.Second}
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rightsized",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Rightsizing: &kaimeraaiv1.RightsizingSpec{
+					PrometheusURL:     server.URL,
+					MaxReplicas:       6,
+					TargetRequestRate: resource.MustParse("2"),
+					MaxLatency:        &maxLatency,
+				},
+			},
+		}
+
+		fakeClock = clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+			Clock:  fakeClock,
+		}
+	})
+
+	It("should size the replicas for the request rate once per interval", func() {
+		Expect(desiredReplicas(md)).To(Equal(int32(1)))
+
+		prometheus.rate = "7.5"
+		prometheus.latency = "4.2"
+		wait, err := reconciler.reconcileRightsizing(ctx, md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(5 * time.Minute))
+		Expect(prometheus.queries).To(ConsistOf(
+			`sum(rate(vllm:request_success_total{namespace="default",service="rightsized"}[5m]))`,
+			`histogram_quantile(0.95, sum by (le) (rate(vllm:e2e_request_latency_seconds_bucket{namespace="default",service="rightsized"}[5m])))`,
+		))
+
+		status := md.Status.Rightsizing
+		Expect(status).NotTo(BeNil())
+		Expect(status.Replicas).To(Equal(int32(4)))
+		Expect(status.Reason).To(Equal("RequestRate"))
+		Expect(status.RequestRate.String()).To(Equal("7500m"))
+		Expect(status.Latency).To(HaveValue(Equal(metav1.Duration{Duration: 4200 * time.Millisecond})))
+		Expect(status.DecisionTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(desiredReplicas(md)).To(Equal(int32(4)))
+
+		By("leaving the cpu fallback at its own replica count")
+		md.Spec.CPUFallback = &kaimeraaiv1.CPUFallbackSpec{}
+		Expect(desiredReplicas(cpuFallbackModelDeployment(md))).To(Equal(int32(1)))
+		md.Spec.CPUFallback = nil
+
+		By("waiting for the next interval")
+		fakeClock.SetTime(fakeClock.Now().Add(2 * time.Minute))
+		prometheus.rate = ""
+		wait, err = reconciler.reconcileRightsizing(ctx, md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(3 * time.Minute))
+		Expect(md.Status.Rightsizing.Replicas).To(Equal(int32(4)))
+
+		By("dropping one replica at a time without requests")
+		fakeClock.SetTime(fakeClock.Now().Add(3 * time.Minute))
+		_, err = reconciler.reconcileRightsizing(ctx, md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(md.Status.Rightsizing.Replicas).To(Equal(int32(3)))
+		Expect(md.Status.Rightsizing.RequestRate.IsZero()).To(BeTrue())
+		Expect(md.Status.Rightsizing.Latency).To(BeNil())
+
+		By("clearing the decision once turned off")
+		md.Spec.Rightsizing = nil
+		_, err = reconciler.reconcileRightsizing(ctx, md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(md.Status.Rightsizing).To(BeNil())
+		Expect(md.Status.RightsizingQueryTime).To(BeNil())
+	})
+
+	It("should keep the replicas when Prometheus can't be queried", func() {
+		recorder := record.NewFakeRecorder(10)
+		reconciler.Recorder = recorder
+		md.Spec.Rightsizing.PrometheusURL += "/unavailable"
+
+		wait, err := reconciler.reconcileRightsizing(ctx, md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(5 * time.Minute))
+		Expect(md.Status.Rightsizing).To(BeNil())
+		Expect(md.Status.RightsizingQueryTime).NotTo(BeNil())
+		Expect(md.Status.RightsizingQueryTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning RightsizingFailed")))
+
+		By("waiting for the next interval to query again")
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+		wait, err = reconciler.reconcileRightsizing(ctx, md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(4 * time.Minute))
+		Expect(recorder.Events).To(BeEmpty())
+
+		fakeClock.SetTime(fakeClock.Now().Add(4 * time.Minute))
+		_, err = reconciler.reconcileRightsizing(ctx, md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning RightsizingFailed")))
+	})
+
+	It("should decide on the replicas within the bounds", func() {
+		spec := md.Spec.Rightsizing
+		seconds := func(s int) *time.Duration {
+			latency := time.Duration(s) * time.Second
+			return &latency
+		}
+
+		for _, c := range []struct {
+			current  int32
+			rate     float64
+			latency  *time.Duration
+			replicas int32
+			reason   string
+		}{
+			{current: 2, rate: 4, latency: seconds(1), replicas: 2, reason: "RequestRate"},
+			{current: 2, rate: 4.1, latency: seconds(1), replicas: 3, reason: "RequestRate"},
+			{current: 2, rate: 4, latency: seconds(12), replicas: 3, reason: "Latency"},
+			{current: 2, rate: 9, latency: seconds(12), replicas: 5, reason: "RequestRate"},
+			{current: 4, rate: 1, latency: nil, replicas: 3, reason: "RequestRate"},
+			{current: 1, rate: 0, latency: nil, replicas: 1, reason: "MinReplicas"},
+			{current: 6, rate: 2, latency: seconds(12), replicas: 6, reason: "MaxReplicas"},
+			{current: 3, rate: 40, latency: nil, replicas: 6, reason: "MaxReplicas"},
+		} {
+			replicas, reason := rightsize(spec, c.current, c.rate, c.latency)
+			Expect(replicas).To(Equal(c.replicas), "current %d, rate %v", c.current, c.rate)
+			Expect(reason).To(Equal(c.reason), "current %d, rate %v", c.current, c.rate)
+		}
+	})
+})
@@ -0,0 +1,334 @@
+package controller
+
+import (
+	"context"
+	"maps"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// The Argo Rollouts types are not a dependency of the controller, so
+// Rollouts are handled as unstructured objects
+var rolloutGVK = schema.GroupVersionKind{
+	Group:   "argoproj.io",
+	Version: "v1alpha1",
+	Kind:    "Rollout",
+}
+
+// rolloutPodTemplateHashKey is the selector Argo Rollouts adds to the
+// Services of a blue-green rollout to route each to one version
+const rolloutPodTemplateHashKey = "rollouts-pod-template-hash"
+
+var defaultCanaryWeights = []int32{25, 50, 75}
+
+func newRollout() *unstructured.Unstructured {
+	rollout := &unstructured.Unstructured{}
+	rollout.SetGroupVersionKind(rolloutGVK)
+	return rollout
+}
+
+// getRollout reads the Rollout running the model replicas into dp, a
+// Deployment stand-in carrying its annotations, replicas, pod template and
+// status, so the rest of the reconcile reads it like a Deployment. It
+// reports whether the Rollout exists.
+func (r *ModelDeploymentReconciler) getRollout(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) (bool, error) {
+	rollout := newRollout()
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.Name)}, rollout)
+	if errors.IsNotFound(err) {
+		*dp = appsv1.Deployment{}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var spec struct {
+		Replicas *int32                 `json:"replicas"`
+		Template corev1.PodTemplateSpec `json:"template"`
+	}
+	var status appsv1.DeploymentStatus
+	for field, out := range map[string]interface{}{"spec": &spec, "status": &status} {
+		value, _, err := unstructured.NestedMap(rollout.Object, field)
+		if err != nil {
+			return false, err
+		}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(value, out)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	*dp = appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        rollout.GetName(),
+			Namespace:   rollout.GetNamespace(),
+			Annotations: rollout.GetAnnotations(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: spec.Replicas,
+			Template: spec.Template,
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          status.Replicas,
+			UpdatedReplicas:   status.UpdatedReplicas,
+			ReadyReplicas:     status.ReadyReplicas,
+			AvailableReplicas: status.AvailableReplicas,
+		},
+	}
+	return true, nil
+}
+
+// reconcileRollout creates or updates the Rollout and Services serving the
+// model, in place of a Deployment. current is the Deployment stand-in of the
+// existing Rollout, or nil if there is none yet.
+func (r *ModelDeploymentReconciler) reconcileRollout(ctx context.Context, md *kaimeraaiv1.ModelDeployment, current *appsv1.Deployment) error {
+	deploy, err := r.generateDeployment(md)
+	if err != nil {
+		return err
+	}
+	setChangeCause(deploy, current, md)
+	err = r.checkImageRegistries(deploy)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		err = r.checkGPUQuota(ctx, md, deploy)
+		if err != nil {
+			return err
+		}
+		r.warnLargeImages(md, deploy)
+	}
+	preserveAutoscaledReplicas(deploy, current, md)
+
+	rollout, err := r.generateRollout(md, deploy)
+	if err != nil {
+		return err
+	}
+
+	err = r.applyRollout(ctx, rollout)
+	if err != nil {
+		return err
+	}
+
+	// The Rollout replaces the Deployment or StatefulSet of the model once
+	// it serves in their place
+	if workloadAvailable(current) {
+		replaced := appsv1.Deployment{}
+		err = r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.Name)}, &replaced)
+		if err == nil && metav1.IsControlledBy(&replaced, md) {
+			log.FromContext(ctx).Info("deleting deployment replaced by the rollout")
+			err = r.Delete(ctx, &replaced)
+		}
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		err = r.deleteStatefulSet(ctx, md)
+		if err != nil {
+			return err
+		}
+	}
+
+	svc, err := r.generateService(md)
+	if err != nil {
+		return err
+	}
+	preview, err := r.generateRolloutPreviewService(md, svc)
+	if err != nil {
+		return err
+	}
+	err = r.applyRolloutService(ctx, svc)
+	if err != nil {
+		return err
+	}
+
+	if rolloutStrategy(md) == kaimeraaiv1.RolloutStrategyBlueGreen {
+		return r.applyRolloutService(ctx, preview)
+	}
+	return r.deleteOwned(ctx, md, preview)
+}
+
+// deleteRollout removes the Rollout and preview Service once the model runs
+// in a Deployment again
+func (r *ModelDeploymentReconciler) deleteRollout(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	rollout := newRollout()
+	rollout.SetNamespace(md.Namespace)
+	rollout.SetName(r.resourceName(md.Name))
+	err := r.deleteOwned(ctx, md, rollout)
+	// Nothing to delete on clusters without Argo Rollouts
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	return r.deleteOwned(ctx, md, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: md.Namespace, Name: r.resourceName(md.RolloutPreviewName())},
+	})
+}
+
+// deleteOwned deletes obj, looked up by its key, if md controls it
+func (r *ModelDeploymentReconciler) deleteOwned(ctx context.Context, md *kaimeraaiv1.ModelDeployment, obj client.Object) (err error) {
+	ctx, span := r.startObjectSpan(ctx, "deleteOwned", obj)
+	defer func() { endSpan(span, err) }()
+
+	err = r.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if err != nil || !metav1.IsControlledBy(obj, md) {
+		return client.IgnoreNotFound(err)
+	}
+
+	log.FromContext(ctx).Info("deleting object", "name", obj.GetName())
+	span.SetAttributes(operationAttribute.String("delete"))
+	return client.IgnoreNotFound(r.Delete(ctx, obj))
+}
+
+// applyRollout creates or updates the Rollout, keeping the annotations and
+// the pause Argo Rollouts and its kubectl plugin set on it
+func (r *ModelDeploymentReconciler) applyRollout(ctx context.Context, rollout *unstructured.Unstructured) error {
+	current := newRollout()
+	err := r.Get(ctx, client.ObjectKeyFromObject(rollout), current)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if len(current.GetAnnotations()) > 0 {
+		annotations := current.GetAnnotations()
+		maps.Copy(annotations, rollout.GetAnnotations())
+		rollout.SetAnnotations(annotations)
+	}
+	if paused, ok, _ := unstructured.NestedBool(current.Object, "spec", "paused"); ok {
+		err = unstructured.SetNestedField(rollout.Object, paused, "spec", "paused")
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.apply(ctx, rollout, newRollout())
+}
+
+// applyRolloutService creates or updates a Service of the Rollout, keeping
+// the version Argo Rollouts routes it to
+func (r *ModelDeploymentReconciler) applyRolloutService(ctx context.Context, svc *corev1.Service) error {
+	current := corev1.Service{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(svc), &current)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if hash, ok := current.Spec.Selector[rolloutPodTemplateHashKey]; ok {
+		svc.Spec.Selector[rolloutPodTemplateHashKey] = hash
+	}
+
+	return r.apply(ctx, svc, &corev1.Service{})
+}
+
+func rolloutStrategy(md *kaimeraaiv1.ModelDeployment) kaimeraaiv1.RolloutStrategy {
+	if md.Spec.Rollout == nil || md.Spec.Rollout.Strategy == "" {
+		return kaimeraaiv1.RolloutStrategyCanary
+	}
+
+	return md.Spec.Rollout.Strategy
+}
+
+// generateRollout returns the Rollout running the replicas and pod template
+// of deploy with the spec's strategy
+func (r *ModelDeploymentReconciler) generateRollout(md *kaimeraaiv1.ModelDeployment, deploy *appsv1.Deployment) (*unstructured.Unstructured, error) {
+	selector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deploy.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deploy.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := map[string]interface{}{
+		"replicas": int64(*deploy.Spec.Replicas),
+		"selector": selector,
+		"template": template,
+		"strategy": r.generateRolloutStrategy(md),
+	}
+	if deploy.Spec.RevisionHistoryLimit != nil {
+		spec["revisionHistoryLimit"] = int64(*deploy.Spec.RevisionHistoryLimit)
+	}
+
+	rollout := newRollout()
+	rollout.SetName(r.resourceName(md.Name))
+	rollout.SetNamespace(md.Namespace)
+	rollout.SetLabels(deploy.Labels)
+	rollout.SetAnnotations(deploy.Annotations)
+	rollout.Object["spec"] = spec
+
+	err = ctrl.SetControllerReference(md, rollout, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
+
+// generateRolloutStrategy returns the strategy of the Rollout: canary steps
+// pausing at each weight, or a blue-green switch between the Service and
+// the preview Service
+func (r *ModelDeploymentReconciler) generateRolloutStrategy(md *kaimeraaiv1.ModelDeployment) map[string]interface{} {
+	spec := md.Spec.Rollout
+	if spec == nil {
+		spec = &kaimeraaiv1.RolloutSpec{}
+	}
+
+	if rolloutStrategy(md) == kaimeraaiv1.RolloutStrategyBlueGreen {
+		return map[string]interface{}{
+			"blueGreen": map[string]interface{}{
+				"activeService":        r.resourceName(md.Name),
+				"previewService":       r.resourceName(md.RolloutPreviewName()),
+				"autoPromotionEnabled": spec.AutoPromote,
+			},
+		}
+	}
+
+	weights := spec.CanaryWeights
+	if len(weights) == 0 {
+		weights = defaultCanaryWeights
+	}
+	var steps []interface{}
+	for _, weight := range weights {
+		pause := map[string]interface{}{}
+		if spec.PauseDuration != nil {
+			pause["duration"] = spec.PauseDuration.Duration.String()
+		}
+		steps = append(steps,
+			map[string]interface{}{"setWeight": int64(weight)},
+			map[string]interface{}{"pause": pause},
+		)
+	}
+
+	return map[string]interface{}{
+		"canary": map[string]interface{}{
+			"steps": steps,
+		},
+	}
+}
+
+// generateRolloutPreviewService returns the Service of the new version during
+// a blue-green rollout, a copy of the model's Service left out of discovery
+func (r *ModelDeploymentReconciler) generateRolloutPreviewService(md *kaimeraaiv1.ModelDeployment, svc *corev1.Service) (*corev1.Service, error) {
+	preview := svc.DeepCopy()
+	preview.Name = r.resourceName(md.RolloutPreviewName())
+	preview.Labels = childLabels(md, map[string]string{"app": preview.Name})
+	preview.Annotations = childAnnotations(md, nil)
+	preview.Spec.ClusterIP = ""
+
+	err := ctrl.SetControllerReference(md, preview, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
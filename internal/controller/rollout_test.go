@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment Rollout", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+	var key types.NamespacedName
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "rolled",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:    "facebook/opt-125m",
+				Replicas:     2,
+				WorkloadType: kaimeraaiv1.WorkloadTypeRollout,
+			},
+		}
+		key = types.NamespacedName{Namespace: md.Namespace, Name: md.Name}
+	})
+
+	// newReconciler returns a reconciler on a cluster where Argo Rollouts is
+	// installed, or not
+	newReconciler := func(installed bool, objs ...client.Object) *ModelDeploymentReconciler {
+		builder := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(append(objs, md)...).
+			WithStatusSubresource(md)
+		if installed {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			for gvk := range scheme.Scheme.AllKnownTypes() {
+				mapper.Add(gvk, meta.RESTScopeNamespace)
+			}
+			mapper.Add(rolloutGVK, meta.RESTScopeNamespace)
+			builder = builder.WithRESTMapper(mapper)
+		}
+
+		return &ModelDeploymentReconciler{
+			Client: builder.Build(),
+			Scheme: scheme.Scheme,
+		}
+	}
+
+	It("should step through the canary weights", func() {
+		pause := metav1.Duration{Duration: 5 * time.Minute}
+		md.Spec.Rollout = &kaimeraaiv1.RolloutSpec{
+			CanaryWeights: []int32{10, 50},
+			PauseDuration: &pause,
+		}
+		reconciler := newReconciler(false)
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		rollout, err := reconciler.generateRollout(md, deploy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rollout.GetAPIVersion()).To(Equal("argoproj.io/v1alpha1"))
+		Expect(rollout.GetKind()).To(Equal("Rollout"))
+		Expect(metav1.IsControlledBy(rollout, md)).To(BeTrue())
+
+		replicas, _, err := unstructured.NestedInt64(rollout.Object, "spec", "replicas")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(Equal(int64(2)))
+
+		containers, _, err := unstructured.NestedSlice(rollout.Object, "spec", "template", "spec", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(containers).To(HaveLen(len(deploy.Spec.Template.Spec.Containers)))
+		Expect(containers[0]).To(HaveKeyWithValue("image", deploy.Spec.Template.Spec.Containers[0].Image))
+
+		steps, _, err := unstructured.NestedSlice(rollout.Object, "spec", "strategy", "canary", "steps")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(Equal([]interface{}{
+			map[string]interface{}{"setWeight": int64(10)},
+			map[string]interface{}{"pause": map[string]interface{}{"duration": "5m0s"}},
+			map[string]interface{}{"setWeight": int64(50)},
+			map[string]interface{}{"pause": map[string]interface{}{"duration": "5m0s"}},
+		}))
+	})
+
+	It("should pause indefinitely at the default weights", func() {
+		strategy := newReconciler(false).generateRolloutStrategy(md)
+
+		steps, _, err := unstructured.NestedSlice(strategy, "canary", "steps")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(steps).To(HaveLen(6))
+		Expect(steps[4]).To(Equal(map[string]interface{}{"setWeight": int64(75)}))
+		Expect(steps[5]).To(Equal(map[string]interface{}{"pause": map[string]interface{}{}}))
+	})
+
+	It("should switch between the Service and a preview Service when blue-green", func() {
+		md.Spec.Rollout = &kaimeraaiv1.RolloutSpec{
+			Strategy:    kaimeraaiv1.RolloutStrategyBlueGreen,
+			AutoPromote: true,
+		}
+
+		reconciler := newReconciler(true)
+		Expect(reconciler.generateRolloutStrategy(md)).To(Equal(map[string]interface{}{
+			"blueGreen": map[string]interface{}{
+				"activeService":        "rolled",
+				"previewService":       "rolled-preview",
+				"autoPromotionEnabled": true,
+			},
+		}))
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		preview := corev1.Service{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: md.Namespace, Name: "rolled-preview"}, &preview)).To(Succeed())
+		Expect(metav1.IsControlledBy(&preview, md)).To(BeTrue())
+		Expect(preview.Spec.Selector).To(HaveKeyWithValue("app", "rolled"))
+	})
+
+	It("should keep the version Argo Rollouts routes the Service to", func() {
+		reconciler := newReconciler(true)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		svc := corev1.Service{}
+		Expect(reconciler.Get(ctx, key, &svc)).To(Succeed())
+		svc.Spec.Selector[rolloutPodTemplateHashKey] = "5d8f9c"
+		Expect(reconciler.Update(ctx, &svc)).To(Succeed())
+
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, &svc)).To(Succeed())
+		Expect(svc.Spec.Selector).To(HaveKeyWithValue(rolloutPodTemplateHashKey, "5d8f9c"))
+	})
+
+	It("should keep the annotations and pause set on the Rollout", func() {
+		reconciler := newReconciler(true)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		rollout := newRollout()
+		Expect(reconciler.Get(ctx, key, rollout)).To(Succeed())
+		annotations := rollout.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations["rollout.argoproj.io/revision"] = "2"
+		rollout.SetAnnotations(annotations)
+		Expect(unstructured.SetNestedField(rollout.Object, true, "spec", "paused")).To(Succeed())
+		Expect(reconciler.Update(ctx, rollout)).To(Succeed())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.ModelName = "facebook/opt-350m"
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, rollout)).To(Succeed())
+		Expect(rollout.GetAnnotations()).To(HaveKeyWithValue("rollout.argoproj.io/revision", "2"))
+		paused, _, err := unstructured.NestedBool(rollout.Object, "spec", "paused")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paused).To(BeTrue())
+		template, _, err := unstructured.NestedMap(rollout.Object, "spec", "template")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(template).To(HaveKeyWithValue("spec", HaveKeyWithValue("containers",
+			ContainElement(HaveKeyWithValue("command", ContainElement("facebook/opt-350m"))))))
+	})
+
+	It("should replace the Deployment with a Rollout", func() {
+		reconciler := newReconciler(true)
+		md.Spec.WorkloadType = ""
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.WorkloadType = kaimeraaiv1.WorkloadTypeRollout
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("keeping the Deployment until the Rollout is available")
+		rollout := newRollout()
+		Expect(reconciler.Get(ctx, key, rollout)).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+
+		Expect(unstructured.SetNestedField(rollout.Object, int64(1), "status", "availableReplicas")).To(Succeed())
+		Expect(reconciler.Update(ctx, rollout)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+
+		By("deleting it once the Rollout is")
+		Expect(reconciler.Get(ctx, key, rollout)).To(Succeed())
+		Expect(unstructured.SetNestedField(rollout.Object, int64(2), "status", "availableReplicas")).To(Succeed())
+		Expect(reconciler.Update(ctx, rollout)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = reconciler.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(reconciler.Get(ctx, key, newRollout())).To(Succeed())
+	})
+
+	It("should not reconcile on clusters without Argo Rollouts", func() {
+		reconciler := newReconciler(false)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionTypesRegistered)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Message).To(Equal("rollout requires argoproj.io/v1alpha1, Kind=Rollout, which is not installed"))
+
+		err = reconciler.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
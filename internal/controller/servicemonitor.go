@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// The Prometheus Operator types are not a dependency of the controller, so
+// ServiceMonitors are handled as unstructured objects
+var serviceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+// reconcileServiceMonitor keeps the ServiceMonitor in sync with the spec,
+// and removes it once it is no longer wanted
+func (r *ModelDeploymentReconciler) reconcileServiceMonitor(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.Metrics == nil || md.Spec.Metrics.ServiceMonitor == nil {
+		sm := newServiceMonitor()
+		err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.Name)}, sm)
+		// Nothing to delete on clusters without the Prometheus Operator
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		if err != nil || !metav1.IsControlledBy(sm, md) {
+			return client.IgnoreNotFound(err)
+		}
+
+		log.FromContext(ctx).Info("deleting service monitor")
+		return client.IgnoreNotFound(r.Delete(ctx, sm))
+	}
+
+	sm, err := r.generateServiceMonitor(md)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(ctx, sm, newServiceMonitor())
+}
+
+func newServiceMonitor() *unstructured.Unstructured {
+	sm := &unstructured.Unstructured{}
+	sm.SetGroupVersionKind(serviceMonitorGVK)
+	return sm
+}
+
+// generateServiceMonitor returns a ServiceMonitor scraping the metrics port
+// of the model's Service, or its only port when the runtime listens on a
+// custom one
+func (r *ModelDeploymentReconciler) generateServiceMonitor(md *kaimeraaiv1.ModelDeployment) (*unstructured.Unstructured, error) {
+	spec := md.Spec.Metrics.ServiceMonitor
+
+	port := "metrics"
+	if md.Spec.Port > 0 {
+		port = "http"
+	}
+	endpoint := map[string]interface{}{
+		"port": port,
+		"path": "/metrics",
+	}
+	if spec.Interval != "" {
+		endpoint["interval"] = string(spec.Interval)
+	}
+	if spec.ScrapeTimeout != "" {
+		endpoint["scrapeTimeout"] = string(spec.ScrapeTimeout)
+	}
+	if len(spec.MetricRelabelings) > 0 {
+		relabelings, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&struct {
+			Relabelings []kaimeraaiv1.RelabelConfig `json:"relabelings"`
+		}{spec.MetricRelabelings})
+		if err != nil {
+			return nil, err
+		}
+		endpoint["metricRelabelings"] = relabelings["relabelings"]
+	}
+
+	sm := newServiceMonitor()
+	sm.SetName(r.resourceName(md.Name))
+	sm.SetNamespace(md.Namespace)
+	sm.SetLabels(childLabels(md, nil))
+	sm.SetAnnotations(childAnnotations(md, nil))
+	sm.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": md.Name},
+		},
+		"endpoints": []interface{}{endpoint},
+	}
+
+	err := ctrl.SetControllerReference(md, sm, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return sm, nil
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment ServiceMonitor", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		replacement := "$1"
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "monitored",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Metrics: &kaimeraaiv1.MetricsSpec{
+					ServiceMonitor: &kaimeraaiv1.ServiceMonitorSpec{
+						Interval:      "1m",
+						ScrapeTimeout: "20s",
+						MetricRelabelings: []kaimeraaiv1.RelabelConfig{
+							{SourceLabels: []string{"__name__"}, Regex: "vllm:.*_bucket", Action: "drop"},
+							{SourceLabels: []string{"model_name"}, TargetLabel: "model", Replacement: &replacement},
+						},
+					},
+				},
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should scrape with the configured interval and relabelings", func() {
+		sm, err := reconciler.generateServiceMonitor(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sm.GetKind()).To(Equal("ServiceMonitor"))
+		Expect(metav1.IsControlledBy(sm, md)).To(BeTrue())
+
+		selector, _, err := unstructured.NestedStringMap(sm.Object, "spec", "selector", "matchLabels")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selector).To(Equal(map[string]string{"app": "monitored"}))
+
+		svc, err := reconciler.generateService(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Labels).To(HaveKeyWithValue("app", "monitored"))
+
+		endpoints, _, err := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endpoints).To(HaveLen(1))
+		Expect(endpoints[0]).To(Equal(map[string]interface{}{
+			"port":          "metrics",
+			"path":          "/metrics",
+			"interval":      "1m",
+			"scrapeTimeout": "20s",
+			"metricRelabelings": []interface{}{
+				map[string]interface{}{
+					"sourceLabels": []interface{}{"__name__"},
+					"regex":        "vllm:.*_bucket",
+					"action":       "drop",
+				},
+				map[string]interface{}{
+					"sourceLabels": []interface{}{"model_name"},
+					"targetLabel":  "model",
+					"replacement":  "$1",
+				},
+			},
+		}))
+	})
+
+	It("should scrape the runtime's only port when it is customized", func() {
+		md.Spec.Port = 9000
+		sm, err := reconciler.generateServiceMonitor(md)
+		Expect(err).NotTo(HaveOccurred())
+
+		endpoints, _, err := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endpoints[0]).To(HaveKeyWithValue("port", "http"))
+	})
+
+	It("should not fail on clusters without the Prometheus Operator", func() {
+		md.Spec.Metrics = nil
+		Expect(reconciler.reconcileServiceMonitor(ctx, md)).To(Succeed())
+	})
+})
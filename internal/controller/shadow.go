@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// reconcileShadow keeps the shadow Deployment and Service in sync with the
+// spec, and removes them once the shadow is no longer wanted. The shadow pods
+// carry their own app label, so the primary Service never routes to them.
+func (r *ModelDeploymentReconciler) reconcileShadow(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.Shadow == nil {
+		return r.deleteShadow(ctx, md)
+	}
+
+	deploy, err := r.generateShadowDeployment(md)
+	if err != nil {
+		return err
+	}
+	err = r.checkImageRegistries(deploy)
+	if err != nil {
+		return err
+	}
+
+	err = r.apply(ctx, deploy, &appsv1.Deployment{})
+	if err != nil {
+		return err
+	}
+
+	svc, err := r.generateShadowService(md)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(ctx, svc, &corev1.Service{})
+}
+
+func (r *ModelDeploymentReconciler) deleteShadow(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	key := client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.ShadowName())}
+	for _, obj := range []client.Object{&appsv1.Deployment{}, &corev1.Service{}} {
+		err := r.Get(ctx, key, obj)
+		if err != nil || !metav1.IsControlledBy(obj, md) {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+
+		log.FromContext(ctx).Info("deleting shadow object", "name", obj.GetName())
+		err = r.Delete(ctx, obj)
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shadowModelDeployment returns the ModelDeployment the shadow is generated
+// from: the primary with the shadow's overrides applied, running a single
+// replica.
+func shadowModelDeployment(md *kaimeraaiv1.ModelDeployment) *kaimeraaiv1.ModelDeployment {
+	shadow := md.DeepCopy()
+	shadow.Name = md.ShadowName()
+	shadow.Spec.ModelName = md.Spec.Shadow.ModelName
+	if md.Spec.Shadow.ImageDigest != "" {
+		shadow.Spec.ImageDigest = md.Spec.Shadow.ImageDigest
+	}
+	shadow.Spec.Replicas = 1
+	shadow.Spec.ReplicasPerNode = 0
+	shadow.Spec.RampUp = false
+	shadow.Spec.Autoscaling = nil
+	shadow.Spec.Hostname = ""
+	shadow.Spec.ClusterIP = ""
+	// The shadow serves a different model, so it keeps its KV cache local
+	shadow.Spec.PrefixCache = nil
+	// Registries should only discover the primary
+	shadow.Spec.Discovery = nil
+	shadow.Spec.CPUFallback = nil
+	// The checksum pins the primary's model
+	shadow.Spec.ModelChecksum = ""
+
+	return shadow
+}
+
+func (r *ModelDeploymentReconciler) generateShadowDeployment(md *kaimeraaiv1.ModelDeployment) (*appsv1.Deployment, error) {
+	// Mirrored requests name the primary's model, so the shadow answers to it
+	return r.generateAliasDeployment(md, shadowModelDeployment(md))
+}
+
+// generateAliasDeployment returns the Deployment of alias, a ModelDeployment
+// derived from md, serving its model under md's model name and owned by md
+func (r *ModelDeploymentReconciler) generateAliasDeployment(md, alias *kaimeraaiv1.ModelDeployment) (*appsv1.Deployment, error) {
+	deploy, err := r.generateDeployment(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	container := &deploy.Spec.Template.Spec.Containers[0]
+	container.Command = append(container.Command, "--served-model-name", md.Spec.ModelName)
+
+	// The chat template ConfigMap is only created for the primary
+	for _, volume := range deploy.Spec.Template.Spec.Volumes {
+		if volume.Name == chatTemplateVolume {
+			volume.ConfigMap.Name = r.chatTemplateConfigMapName(md)
+		}
+	}
+
+	deploy.OwnerReferences = nil
+	err = ctrl.SetControllerReference(md, deploy, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return deploy, nil
+}
+
+func (r *ModelDeploymentReconciler) generateShadowService(md *kaimeraaiv1.ModelDeployment) (*corev1.Service, error) {
+	svc, err := r.generateService(shadowModelDeployment(md))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctrl.SetControllerReference(md, svc, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
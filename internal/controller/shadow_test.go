@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment shadow", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "opt",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Replicas:  3,
+				Shadow: &kaimeraaiv1.ShadowSpec{
+					ModelName: "facebook/opt-350m",
+				},
+			},
+		}
+
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(md).Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should generate a single replica deployment of the shadow model", func() {
+		deploy, err := reconciler.generateShadowDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Name).To(Equal("opt-shadow"))
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(1))
+		Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElement("facebook/opt-350m"))
+		Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--served-model-name", "facebook/opt-125m"))
+		Expect(metav1.IsControlledBy(deploy, md)).To(BeTrue())
+	})
+
+	It("should keep the shadow pods out of the primary service", func() {
+		deploy, err := reconciler.generateShadowDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		shadowPods := labels.Set(deploy.Spec.Template.Labels)
+
+		svc, err := reconciler.generateService(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels.SelectorFromSet(svc.Spec.Selector).Matches(shadowPods)).To(BeFalse())
+
+		shadowSvc, err := reconciler.generateShadowService(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shadowSvc.Name).To(Equal("opt-shadow"))
+		Expect(labels.SelectorFromSet(shadowSvc.Spec.Selector).Matches(shadowPods)).To(BeTrue())
+	})
+
+	It("should remove the shadow once it is no longer wanted", func() {
+		Expect(reconciler.reconcileShadow(ctx, md)).To(Succeed())
+
+		key := client.ObjectKey{Namespace: md.Namespace, Name: md.ShadowName()}
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &corev1.Service{})).To(Succeed())
+
+		By("updating it in place")
+		md.Spec.Shadow.ModelName = "facebook/opt-1.3b"
+		Expect(reconciler.reconcileShadow(ctx, md)).To(Succeed())
+		deploy := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, deploy)).To(Succeed())
+		Expect(deploy.Spec.Template.Spec.Containers[0].Command).To(ContainElement("facebook/opt-1.3b"))
+
+		md.Spec.Shadow = nil
+		Expect(reconciler.reconcileShadow(ctx, md)).To(Succeed())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+	})
+})
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("Manager graceful shutdown", func() {
+	It("should let in-flight reconciles finish their writes before stopping", func() {
+		// The manager never talks to the API server: nothing is watched
+		// and the reconcile is triggered through a channel
+		timeout := 10 * time.Second
+		mgr, err := ctrl.NewManager(&rest.Config{Host: "http://127.0.0.1:1"}, ctrl.Options{
+			Scheme:                  scheme.Scheme,
+			Metrics:                 metricsserver.Options{BindAddress: "0"},
+			HealthProbeBindAddress:  "0",
+			GracefulShutdownTimeout: &timeout,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "in-flight", Namespace: "default", Generation: 1},
+			Spec:       kaimeraaiv1.ModelDeploymentSpec{ModelName: "facebook/opt-125m"},
+		}
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var once sync.Once
+		reconciler := &ModelDeploymentReconciler{
+			// The reconcile blocks on reading the ModelDeployment, and the
+			// writes fail on a cancelled context like those of a real client
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						if _, ok := obj.(*kaimeraaiv1.ModelDeployment); ok {
+							once.Do(func() {
+								close(started)
+								<-release
+							})
+						}
+						return c.Get(ctx, key, obj, opts...)
+					},
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if ctx.Err() != nil {
+							return ctx.Err()
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+				}).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		events := make(chan event.GenericEvent, 1)
+		err = ctrl.NewControllerManagedBy(mgr).
+			Named("shutdown").
+			WatchesRawSource(source.Channel(events, &handler.EnqueueRequestForObject{})).
+			Complete(reconciler)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error)
+		go func() {
+			stopped <- mgr.Start(ctx)
+		}()
+
+		events <- event.GenericEvent{Object: md}
+		Eventually(started).Should(BeClosed())
+
+		By("stopping the manager while the reconcile is in flight")
+		cancel()
+		Consistently(stopped, 200*time.Millisecond).ShouldNot(Receive())
+
+		By("stopping once the reconcile finished")
+		close(release)
+		Eventually(stopped, 5*time.Second).Should(Receive(BeNil()))
+		Expect(reconciler.Get(context.Background(), client.ObjectKeyFromObject(md), &appsv1.Deployment{})).To(Succeed())
+	})
+})
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const smokeTestImage = "curlimages/curl:8.8.0"
+
+// reconcileSmokeTest runs a one-shot Job against the model service once the
+// deployment is ready and records the outcome in the SmokeTest condition. The
+// Job is deleted once its result has been recorded. Each generation of the
+// ModelDeployment is only tested once.
+func (r *ModelDeploymentReconciler) reconcileSmokeTest(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) error {
+	logger := log.FromContext(ctx)
+
+	cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionSmokeTest)
+	if cond != nil && cond.ObservedGeneration == md.Generation && cond.Status != metav1.ConditionUnknown {
+		return nil
+	}
+
+	if !deploymentReady(dp) {
+		return nil
+	}
+
+	job := batchv1.Job{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.smokeTestJobName(md)}, &job)
+	if errors.IsNotFound(err) {
+		logger.Info("creating smoke test job")
+		newJob, err := r.generateSmokeTestJob(md)
+		if err != nil {
+			return err
+		}
+		err = r.checkPodImageRegistries(&newJob.Spec.Template.Spec)
+		if err != nil {
+			return err
+		}
+
+		err = r.Create(ctx, newJob)
+		if err != nil {
+			return err
+		}
+
+		meta.SetStatusCondition(&md.Status.Conditions, metav1.Condition{
+			Type:               kaimeraaiv1.ConditionSmokeTest,
+			Status:             metav1.ConditionUnknown,
+			Reason:             "Running",
+			Message:            "smoke test job is running",
+			ObservedGeneration: md.Generation,
+		})
+		return r.updateStatus(ctx, md)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Failed pods are retried up to the backoff limit, so only the Job's
+	// own conditions tell whether it finished
+	var status metav1.ConditionStatus
+	var reason, message string
+	switch {
+	case jobConditionTrue(&job, batchv1.JobComplete):
+		status, reason, message = metav1.ConditionTrue, "Passed", "smoke test completion succeeded"
+	case jobConditionTrue(&job, batchv1.JobFailed):
+		status, reason, message = metav1.ConditionFalse, "Failed", "smoke test completion failed"
+	default:
+		return nil
+	}
+
+	logger.Info("smoke test finished", "result", reason)
+	meta.SetStatusCondition(&md.Status.Conditions, metav1.Condition{
+		Type:               kaimeraaiv1.ConditionSmokeTest,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: md.Generation,
+	})
+	err = r.updateStatus(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	return client.IgnoreNotFound(r.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
+
+func jobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == conditionType && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ModelDeploymentReconciler) smokeTestJobName(md *kaimeraaiv1.ModelDeployment) string {
+	return r.resourceName(md.Name + "-smoke-test")
+}
+
+func (r *ModelDeploymentReconciler) generateSmokeTestJob(md *kaimeraaiv1.ModelDeployment) (*batchv1.Job, error) {
+	var backoffLimit int32 = 2
+	body := fmt.Sprintf(`{"model": %q, "prompt": "Hello", "max_tokens": 5}`, md.Spec.ModelName)
+	maxTime := "60"
+	if md.Spec.RequestTimeoutSeconds > 0 {
+		maxTime = fmt.Sprintf("%d", md.Spec.RequestTimeoutSeconds)
+	}
+
+	command := []string{
+		"curl",
+		"--silent",
+		"--fail",
+		"--show-error",
+		"--max-time",
+		maxTime,
+		"-H",
+		"Content-Type: application/json",
+	}
+	var env []corev1.EnvVar
+	if md.Spec.APIKeySecretRef != nil {
+		// The kubelet expands $(VLLM_API_KEY) in the command
+		env = append(env, corev1.EnvVar{
+			Name: "VLLM_API_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: md.Spec.APIKeySecretRef,
+			},
+		})
+		command = append(command, "-H", "Authorization: Bearer $(VLLM_API_KEY)")
+	}
+	command = append(command, "-d", body, r.serviceEndpoint(md)+"/v1/completions")
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.smokeTestJobName(md),
+			Namespace:   md.Namespace,
+			Labels:      childLabels(md, nil),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "smoke-test",
+							Image:   smokeTestImage,
+							Command: command,
+							Env:     env,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, job, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
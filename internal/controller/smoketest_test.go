@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment smoke test", func() {
+	ctx := context.Background()
+
+	var reconciler *ModelDeploymentReconciler
+	var md *kaimeraaiv1.ModelDeployment
+	var dp *appsv1.Deployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "smoke",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				SmokeTest: true,
+			},
+		}
+
+		replicas := int32(1)
+		dp = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      md.Name,
+				Namespace: md.Namespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(md).
+			WithStatusSubresource(md).
+			Build()
+		reconciler = &ModelDeploymentReconciler{
+			Client: fakeClient,
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	It("should wait for the deployment to become ready", func() {
+		Expect(reconciler.reconcileSmokeTest(ctx, md, dp)).To(Succeed())
+
+		job := &batchv1.Job{}
+		err := reconciler.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: reconciler.smokeTestJobName(md)}, job)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should create a job and record a passing result", func() {
+		dp.Status.ReadyReplicas = 1
+
+		By("creating the job once the deployment is ready")
+		Expect(reconciler.reconcileSmokeTest(ctx, md, dp)).To(Succeed())
+
+		job := &batchv1.Job{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.smokeTestJobName(md)}
+		Expect(reconciler.Get(ctx, key, job)).To(Succeed())
+		Expect(job.Spec.Template.Spec.Containers[0].Command).To(ContainElement("http://smoke.default:80/v1/completions"))
+		Expect(metav1.IsControlledBy(job, md)).To(BeTrue())
+
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionSmokeTest)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionUnknown))
+
+		By("recording the result once the job succeeds")
+		job.Status.Succeeded = 1
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		Expect(reconciler.Status().Update(ctx, job)).To(Succeed())
+		Expect(reconciler.reconcileSmokeTest(ctx, md, dp)).To(Succeed())
+
+		cond = meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionSmokeTest)
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("Passed"))
+
+		err := reconciler.Get(ctx, key, job)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should record a failing result", func() {
+		dp.Status.ReadyReplicas = 1
+		Expect(reconciler.reconcileSmokeTest(ctx, md, dp)).To(Succeed())
+
+		job := &batchv1.Job{}
+		key := client.ObjectKey{Namespace: md.Namespace, Name: reconciler.smokeTestJobName(md)}
+		Expect(reconciler.Get(ctx, key, job)).To(Succeed())
+
+		By("waiting while failed pods are retried")
+		job.Status.Failed = 1
+		Expect(reconciler.Status().Update(ctx, job)).To(Succeed())
+		Expect(reconciler.reconcileSmokeTest(ctx, md, dp)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionSmokeTest)
+		Expect(cond.Status).To(Equal(metav1.ConditionUnknown))
+
+		By("recording the result once the job fails")
+		job.Status.Failed = 3
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+		Expect(reconciler.Status().Update(ctx, job)).To(Succeed())
+		Expect(reconciler.reconcileSmokeTest(ctx, md, dp)).To(Succeed())
+
+		cond = meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionSmokeTest)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal("Failed"))
+	})
+
+	It("should give up on the request after the request timeout", func() {
+		job, err := reconciler.generateSmokeTestJob(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--max-time", "60"))
+
+		md.Spec.RequestTimeoutSeconds = 600
+		job, err = reconciler.generateSmokeTestJob(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--max-time", "600"))
+	})
+
+	It("should authenticate with the model's API key", func() {
+		job, err := reconciler.generateSmokeTestJob(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+
+		md.Spec.APIKeySecretRef = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+			Key:                  "key",
+		}
+		job, err = reconciler.generateSmokeTestJob(md)
+		Expect(err).NotTo(HaveOccurred())
+		container := job.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(ConsistOf(corev1.EnvVar{
+			Name:      "VLLM_API_KEY",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: md.Spec.APIKeySecretRef},
+		}))
+		Expect(container.Command).To(ContainElements("-H", "Authorization: Bearer $(VLLM_API_KEY)"))
+		Expect(container.Command[len(container.Command)-1]).To(Equal("http://smoke.default:80/v1/completions"))
+	})
+})
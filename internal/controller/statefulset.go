@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// replicaServiceLabel marks the per replica Services with the name of their
+// ModelDeployment, so those of removed replicas can be found
+const replicaServiceLabel = "kaimera.ai/replica-of"
+
+// getStatefulSet reads the StatefulSet running the model replicas into dp, a
+// Deployment stand-in carrying its annotations, replicas, pod template and
+// status, so the rest of the reconcile reads it like a Deployment. It
+// reports whether the StatefulSet exists.
+func (r *ModelDeploymentReconciler) getStatefulSet(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) (bool, error) {
+	sts := appsv1.StatefulSet{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: md.Namespace, Name: r.resourceName(md.Name)}, &sts)
+	if errors.IsNotFound(err) {
+		*dp = appsv1.Deployment{}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	*dp = appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        sts.Name,
+			Namespace:   sts.Namespace,
+			Annotations: sts.Annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: sts.Spec.Replicas,
+			Template: sts.Spec.Template,
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          sts.Status.Replicas,
+			UpdatedReplicas:   sts.Status.UpdatedReplicas,
+			ReadyReplicas:     sts.Status.ReadyReplicas,
+			AvailableReplicas: sts.Status.AvailableReplicas,
+		},
+	}
+	return true, nil
+}
+
+// reconcileStatefulSet creates or updates the StatefulSet and Services
+// serving the model, in place of a Deployment. current is the Deployment
+// stand-in of the existing StatefulSet, or nil if there is none yet.
+func (r *ModelDeploymentReconciler) reconcileStatefulSet(ctx context.Context, md *kaimeraaiv1.ModelDeployment, current *appsv1.Deployment) error {
+	deploy, err := r.generateDeployment(md)
+	if err != nil {
+		return err
+	}
+	setChangeCause(deploy, current, md)
+	err = r.checkImageRegistries(deploy)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		err = r.checkGPUQuota(ctx, md, deploy)
+		if err != nil {
+			return err
+		}
+		r.warnLargeImages(md, deploy)
+	}
+	preserveAutoscaledReplicas(deploy, current, md)
+
+	sts, err := r.generateStatefulSet(md, deploy)
+	if err != nil {
+		return err
+	}
+
+	err = r.apply(ctx, sts, &appsv1.StatefulSet{})
+	if err != nil {
+		return err
+	}
+
+	// The StatefulSet replaces the Deployment or Rollout of the model once
+	// it serves in their place
+	if workloadAvailable(current) {
+		err = r.deleteOwned(ctx, md, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: md.Namespace, Name: r.resourceName(md.Name)},
+		})
+		if err != nil {
+			return err
+		}
+		err = r.deleteRollout(ctx, md)
+		if err != nil {
+			return err
+		}
+	}
+
+	svc, err := r.generateService(md)
+	if err != nil {
+		return err
+	}
+	err = r.apply(ctx, svc, &corev1.Service{})
+	if err != nil {
+		return err
+	}
+
+	var replicas int32
+	if md.Spec.ReplicaServices {
+		replicas = *sts.Spec.Replicas
+	}
+	return r.reconcileReplicaServices(ctx, md, svc.Spec.Ports, replicas)
+}
+
+// deleteStatefulSet removes the StatefulSet and per replica Services once the
+// model runs in another workload
+func (r *ModelDeploymentReconciler) deleteStatefulSet(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	err := r.deleteOwned(ctx, md, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: md.Namespace, Name: r.resourceName(md.Name)},
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.reconcileReplicaServices(ctx, md, nil, 0)
+}
+
+// generateStatefulSet returns the StatefulSet running the replicas and pod
+// template of deploy. Its pods start and stop in parallel, as replicas don't
+// depend on each other.
+func (r *ModelDeploymentReconciler) generateStatefulSet(md *kaimeraaiv1.ModelDeployment, deploy *appsv1.Deployment) (*appsv1.StatefulSet, error) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        deploy.Name,
+			Namespace:   deploy.Namespace,
+			Labels:      deploy.Labels,
+			Annotations: deploy.Annotations,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             deploy.Spec.Replicas,
+			Selector:             deploy.Spec.Selector,
+			Template:             deploy.Spec.Template,
+			ServiceName:          r.resourceName(md.Name),
+			PodManagementPolicy:  appsv1.ParallelPodManagement,
+			RevisionHistoryLimit: deploy.Spec.RevisionHistoryLimit,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, sts, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return sts, nil
+}
+
+// reconcileReplicaServices creates or updates a Service on the given ports
+// for each of the first replicas of the StatefulSet, and deletes the
+// Services of the other replicas
+func (r *ModelDeploymentReconciler) reconcileReplicaServices(ctx context.Context, md *kaimeraaiv1.ModelDeployment, ports []corev1.ServicePort, replicas int32) error {
+	wanted := map[string]bool{}
+	for ordinal := int32(0); ordinal < replicas; ordinal++ {
+		replicaSvc, err := r.generateReplicaService(md, ports, ordinal)
+		if err != nil {
+			return err
+		}
+		err = r.apply(ctx, replicaSvc, &corev1.Service{})
+		if err != nil {
+			return err
+		}
+		wanted[replicaSvc.Name] = true
+	}
+
+	services := corev1.ServiceList{}
+	err := r.List(ctx, &services, client.InNamespace(md.Namespace), client.MatchingLabels{replicaServiceLabel: md.Name})
+	if err != nil {
+		return err
+	}
+	for i := range services.Items {
+		replicaSvc := &services.Items[i]
+		if wanted[replicaSvc.Name] || !metav1.IsControlledBy(replicaSvc, md) {
+			continue
+		}
+
+		log.FromContext(ctx).Info("deleting replica service", "name", replicaSvc.Name)
+		err = client.IgnoreNotFound(r.Delete(ctx, replicaSvc))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateReplicaService returns the Service routing to the replica with the
+// given ordinal only, on the ports of the model's Service and left out of
+// discovery
+func (r *ModelDeploymentReconciler) generateReplicaService(md *kaimeraaiv1.ModelDeployment, ports []corev1.ServicePort, ordinal int32) (*corev1.Service, error) {
+	name := r.resourceName(md.ReplicaServiceName(ordinal))
+	replicaSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: md.Namespace,
+			Labels: childLabels(md, map[string]string{
+				"app":               name,
+				replicaServiceLabel: md.Name,
+			}),
+			Annotations: childAnnotations(md, nil),
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				"app": md.Name,
+				// StatefulSet pods are named after it and their ordinal
+				appsv1.StatefulSetPodNameLabel: fmt.Sprintf("%s-%d", r.resourceName(md.Name), ordinal),
+			},
+			Ports: ports,
+		},
+	}
+
+	err := ctrl.SetControllerReference(md, replicaSvc, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return replicaSvc, nil
+}
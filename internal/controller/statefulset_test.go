@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment StatefulSet", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+	var key types.NamespacedName
+	var reconciler *ModelDeploymentReconciler
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "stateful",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:       "facebook/opt-125m",
+				Replicas:        2,
+				WorkloadType:    kaimeraaiv1.WorkloadTypeStatefulSet,
+				ReplicaServices: true,
+			},
+		}
+		key = client.ObjectKeyFromObject(md)
+		reconciler = &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+	})
+
+	// reconcileReplicas sets the replica count of the spec and reconciles
+	reconcileReplicas := func(replicas int32) {
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.Replicas = replicas
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	// replicaServices returns the names of the per replica Services
+	replicaServices := func() []string {
+		services := corev1.ServiceList{}
+		Expect(reconciler.List(ctx, &services, client.MatchingLabels{replicaServiceLabel: "stateful"})).To(Succeed())
+		var names []string
+		for _, svc := range services.Items {
+			names = append(names, svc.Name)
+		}
+		return names
+	}
+
+	It("should run the replicas in a StatefulSet with a Service each", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		sts := appsv1.StatefulSet{}
+		Expect(reconciler.Get(ctx, key, &sts)).To(Succeed())
+		Expect(*sts.Spec.Replicas).To(BeEquivalentTo(2))
+		Expect(sts.Spec.ServiceName).To(Equal("stateful"))
+		Expect(sts.Spec.PodManagementPolicy).To(Equal(appsv1.ParallelPodManagement))
+		err = reconciler.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		Expect(replicaServices()).To(ConsistOf("stateful-replica-0", "stateful-replica-1"))
+		svc := corev1.Service{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: "stateful-replica-1"}, &svc)).To(Succeed())
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(metav1.IsControlledBy(&svc, md)).To(BeTrue())
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{
+			"app":                                "stateful",
+			"statefulset.kubernetes.io/pod-name": "stateful-1",
+		}))
+		Expect(svc.Labels).To(HaveKeyWithValue("app", "stateful-replica-1"))
+		Expect(svc.Spec.Ports).To(HaveLen(2))
+	})
+
+	It("should follow the replicas as they scale", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("adding a Service for a new replica")
+		reconcileReplicas(3)
+		Expect(replicaServices()).To(ConsistOf("stateful-replica-0", "stateful-replica-1", "stateful-replica-2"))
+
+		By("deleting the Services of removed replicas")
+		reconcileReplicas(1)
+		Expect(replicaServices()).To(ConsistOf("stateful-replica-0"))
+		Expect(reconciler.Get(ctx, key, &corev1.Service{})).To(Succeed())
+
+		By("deleting all of them once turned off")
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.ReplicaServices = false
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicaServices()).To(BeEmpty())
+	})
+
+	It("should not take over a Service it doesn't control", func() {
+		existing := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "stateful-replica-1", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "other"},
+				Ports:    []corev1.ServicePort{{Port: 80}},
+			},
+		}
+		Expect(reconciler.Create(ctx, existing)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal("ResourceConflict"))
+		Expect(cond.Message).To(Equal(`"stateful-replica-1" already exists and is not controlled by ModelDeployment "stateful"`))
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(existing), existing)).To(Succeed())
+		Expect(existing.Spec.Selector).To(Equal(map[string]string{"app": "other"}))
+	})
+
+	It("should replace the StatefulSet with a Deployment", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.WorkloadType = kaimeraaiv1.WorkloadTypeDeployment
+		md.Spec.ReplicaServices = false
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("keeping the StatefulSet until the Deployment is available")
+		dp := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, dp)).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &appsv1.StatefulSet{})).To(Succeed())
+		Expect(replicaServices()).To(HaveLen(2))
+
+		By("deleting it once the Deployment is")
+		dp.Status.AvailableReplicas = 2
+		Expect(reconciler.Status().Update(ctx, dp)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = reconciler.Get(ctx, key, &appsv1.StatefulSet{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(replicaServices()).To(BeEmpty())
+	})
+
+	It("should replace the Deployment with a StatefulSet once it is available", func() {
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.WorkloadType = kaimeraaiv1.WorkloadTypeDeployment
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		md.Spec.WorkloadType = kaimeraaiv1.WorkloadTypeStatefulSet
+		Expect(reconciler.Update(ctx, md)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		sts := &appsv1.StatefulSet{}
+		Expect(reconciler.Get(ctx, key, sts)).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+
+		sts.Status.AvailableReplicas = 2
+		Expect(reconciler.Status().Update(ctx, sts)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = reconciler.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// resolveTemplate merges the spec of md over that of its template, so the
+// rest of the reconcile reads the fields md inherits like its own. The
+// merged spec is only held in memory, see updateStatus; the stored spec is
+// left as written. It is validated like the webhook validates specs, which
+// only sees the spec as written.
+func (r *ModelDeploymentReconciler) resolveTemplate(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	if md.Spec.TemplateName == "" {
+		return nil
+	}
+
+	template := kaimeraaiv1.ModelDeploymentTemplate{}
+	err := r.Get(ctx, client.ObjectKey{Name: md.Spec.TemplateName}, &template)
+	if errors.IsNotFound(err) {
+		return &degradedError{
+			reason:  "TemplateNotFound",
+			message: fmt.Sprintf("ModelDeploymentTemplate %q not found", md.Spec.TemplateName),
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	spec, err := kaimeraaiv1.MergeTemplateSpec(&template.Spec, &md.Spec)
+	if err != nil {
+		return fmt.Errorf("merging ModelDeploymentTemplate %q: %w", template.Name, err)
+	}
+
+	if r.Validator != nil {
+		merged := md.DeepCopy()
+		merged.Spec = *spec
+		err = r.Validator.Validate(ctx, merged)
+		if errors.IsInvalid(err) {
+			return &degradedError{
+				reason:  "InvalidTemplateSpec",
+				message: fmt.Sprintf("spec merged with ModelDeploymentTemplate %q is invalid: %v", template.Name, err),
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	md.Spec = *spec
+	return nil
+}
+
+// modelDeploymentsPerTemplate maps a template to the ModelDeployments
+// inheriting from it
+func (r *ModelDeploymentReconciler) modelDeploymentsPerTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	mds := kaimeraaiv1.ModelDeploymentList{}
+	err := r.List(ctx, &mds)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to list model deployments for template event")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, md := range mds.Items {
+		if md.Spec.TemplateName == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&md)})
+		}
+	}
+
+	return requests
+}
+
+// updateStatus writes the status of md. It is written from a copy, as the
+// API server responds with the stored spec, which lacks the fields md
+// resolved from its template.
+func (r *ModelDeploymentReconciler) updateStatus(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	written := md.DeepCopy()
+	err := r.Status().Update(ctx, written)
+	if err != nil {
+		return err
+	}
+
+	md.ObjectMeta = written.ObjectMeta
+	md.Status = written.Status
+	return nil
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment templates", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+	var template *kaimeraaiv1.ModelDeploymentTemplate
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "templated",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:          "facebook/opt-125m",
+				TemplateName:       "h100",
+				NodeSelectorLabels: map[string]string{"team": "research"},
+				MaxModelLength:     8192,
+			},
+		}
+		template = &kaimeraaiv1.ModelDeploymentTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "h100"},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:          "meta-llama/Llama-3.1-8B-Instruct",
+				Runtime:            "gpu",
+				GPUCount:           2,
+				Replicas:           3,
+				NodeSelectorLabels: map[string]string{"pool": "h100", "team": "ml"},
+				MaxModelLength:     4096,
+				TemplateName:       "other",
+			},
+		}
+	})
+
+	It("should run the model with the template's fields under its own", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, template).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		deploy := appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, &deploy)).To(Succeed())
+		Expect(deploy.Spec.Replicas).To(HaveValue(Equal(int32(3))))
+		podSpec := deploy.Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(map[string]string{"pool": "h100", "team": "research"}))
+		container := podSpec.Containers[0]
+		Expect(container.Image).To(Equal("vllm/vllm-openai:latest"))
+		Expect(container.Command).To(ContainElement("facebook/opt-125m"))
+		Expect(container.Command).To(ContainElements("--max-model-len", "8192"))
+		Expect(container.Resources.Limits.Name("nvidia.com/gpu", resource.DecimalSI).Value()).To(Equal(int64(2)))
+
+		By("leaving the stored spec as written")
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		Expect(md.Spec.Runtime).To(BeEmpty())
+		Expect(md.Spec.NodeSelectorLabels).To(Equal(map[string]string{"team": "research"}))
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+
+		By("following changes to the template")
+		template.Spec.Replicas = 4
+		Expect(reconciler.Update(ctx, template)).To(Succeed())
+		Expect(reconciler.modelDeploymentsPerTemplate(ctx, template)).To(ConsistOf(
+			reconcile.Request{NamespacedName: key},
+		))
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, &deploy)).To(Succeed())
+		Expect(deploy.Spec.Replicas).To(HaveValue(Equal(int32(4))))
+	})
+
+	It("should report a missing template", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("TemplateNotFound"))
+		Expect(cond.Message).To(Equal(`ModelDeploymentTemplate "h100" not found`))
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).NotTo(Succeed())
+		Expect(md.Spec.TemplateName).To(Equal("h100"))
+
+		By("running once it is created")
+		Expect(reconciler.Create(ctx, template)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+	})
+
+	It("should report a merged spec the webhook would reject", func() {
+		template.Spec.Replicas = 0
+		template.Spec.Autoscaling = &kaimeraaiv1.AutoscalingSpec{MaxReplicas: 4}
+		md.Spec.Replicas = 2
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md, template).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:    scheme.Scheme,
+			Validator: &kaimeraaiv1.ModelDeploymentValidator{},
+		}
+
+		key := client.ObjectKeyFromObject(md)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, key, md)).To(Succeed())
+		cond := meta.FindStatusCondition(md.Status.Conditions, kaimeraaiv1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("InvalidTemplateSpec"))
+		Expect(cond.Message).To(ContainSubstring("spec.autoscaling, spec.replicas are mutually exclusive"))
+		Expect(reconciler.Get(ctx, key, &appsv1.Deployment{})).NotTo(Succeed())
+		Expect(md.Spec.Autoscaling).To(BeNil())
+	})
+
+	It("should merge nested fields and replace lists", func() {
+		fsGroup := int64(1000)
+		template.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{ClaimName: "models", SubPath: "shared", FSGroup: &fsGroup}
+		template.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: "example.com/quota"}, {Name: "example.com/image"}}
+		template.Spec.AutoTensorParallel = true
+		md.Spec.ModelCache = &kaimeraaiv1.ModelCacheSpec{ClaimName: "research-models"}
+		md.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: "example.com/review"}}
+
+		spec, err := kaimeraaiv1.MergeTemplateSpec(&template.Spec, &md.Spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.ModelName).To(Equal("facebook/opt-125m"))
+		Expect(spec.MaxModelLength).To(Equal(int32(8192)))
+		Expect(spec.GPUCount).To(Equal(int32(2)))
+		Expect(spec.AutoTensorParallel).To(BeTrue())
+		Expect(spec.ModelCache).To(Equal(&kaimeraaiv1.ModelCacheSpec{
+			ClaimName: "research-models",
+			SubPath:   "shared",
+			FSGroup:   &fsGroup,
+		}))
+		Expect(spec.SchedulingGates).To(Equal([]corev1.PodSchedulingGate{{Name: "example.com/review"}}))
+
+		By("not chaining templates")
+		Expect(spec.TemplateName).To(Equal("h100"))
+		md.Spec.TemplateName = ""
+		spec, err = kaimeraaiv1.MergeTemplateSpec(&template.Spec, &md.Spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.TemplateName).To(BeEmpty())
+
+		By("not modifying either spec")
+		Expect(template.Spec.NodeSelectorLabels).To(Equal(map[string]string{"pool": "h100", "team": "ml"}))
+		Expect(md.Spec.NodeSelectorLabels).To(Equal(map[string]string{"team": "research"}))
+	})
+})
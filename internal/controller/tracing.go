@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// tracerName is the instrumentation scope of the controller's spans
+const tracerName = "github.com/kaimera-ai/kaimera/internal/controller"
+
+// Attributes of the controller's spans
+const (
+	namespaceAttribute    = attribute.Key("kaimera.modeldeployment.namespace")
+	nameAttribute         = attribute.Key("kaimera.modeldeployment.name")
+	requeueAfterAttribute = attribute.Key("kaimera.reconcile.requeue_after")
+	kindAttribute         = attribute.Key("kaimera.object.kind")
+	objectNameAttribute   = attribute.Key("kaimera.object.name")
+	operationAttribute    = attribute.Key("kaimera.object.operation")
+)
+
+func (r *ModelDeploymentReconciler) tracer() trace.Tracer {
+	if r.TracerProvider == nil {
+		return otel.GetTracerProvider().Tracer(tracerName)
+	}
+
+	return r.TracerProvider.Tracer(tracerName)
+}
+
+// startObjectSpan starts the span of an operation on a child object, named
+// after the operation, e.g. apply, with the object's kind and name
+func (r *ModelDeploymentReconciler) startObjectSpan(ctx context.Context, operation string, obj client.Object) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{objectNameAttribute.String(obj.GetName())}
+	if gvk, err := apiutil.GVKForObject(obj, r.Scheme); err == nil {
+		attributes = append(attributes, kindAttribute.String(gvk.Kind))
+	}
+
+	return r.tracer().Start(ctx, operation, trace.WithAttributes(attributes...))
+}
+
+// create creates obj in a span of the reconcile
+func (r *ModelDeploymentReconciler) create(ctx context.Context, obj client.Object) (err error) {
+	ctx, span := r.startObjectSpan(ctx, "create", obj)
+	defer func() { endSpan(span, err) }()
+
+	span.SetAttributes(operationAttribute.String("create"))
+	return r.Create(ctx, obj)
+}
+
+// update updates obj in a span of the reconcile
+func (r *ModelDeploymentReconciler) update(ctx context.Context, obj client.Object) (err error) {
+	ctx, span := r.startObjectSpan(ctx, "update", obj)
+	defer func() { endSpan(span, err) }()
+
+	span.SetAttributes(operationAttribute.String("update"))
+	return r.Update(ctx, obj)
+}
+
+// endSpan records the outcome of the operation of span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment tracing", func() {
+	ctx := context.Background()
+
+	It("should emit a span per reconcile with a child span per object written", func() {
+		md := &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "traced",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+			},
+		}
+		exporter := tracetest.NewInMemoryExporter()
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme:         scheme.Scheme,
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+		}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(md)})
+		Expect(err).NotTo(HaveOccurred())
+
+		var reconciles, writes tracetest.SpanStubs
+		for _, span := range exporter.GetSpans() {
+			switch span.Name {
+			case "Reconcile":
+				reconciles = append(reconciles, span)
+			case "create", "apply":
+				writes = append(writes, span)
+			}
+		}
+		Expect(reconciles).To(HaveLen(1))
+		Expect(reconciles[0].Attributes).To(ContainElements(
+			attribute.String("kaimera.modeldeployment.namespace", "default"),
+			attribute.String("kaimera.modeldeployment.name", "traced"),
+		))
+		Expect(reconciles[0].Status.Code).To(Equal(codes.Unset))
+
+		Expect(writes).NotTo(BeEmpty())
+		for _, span := range writes {
+			Expect(span.Parent.SpanID()).To(Equal(reconciles[0].SpanContext.SpanID()))
+		}
+		Expect(writes[0].Name).To(Equal("create"))
+		Expect(writes[0].Attributes).To(ContainElements(
+			attribute.String("kaimera.object.kind", "Deployment"),
+			attribute.String("kaimera.object.name", "traced"),
+			attribute.String("kaimera.object.operation", "create"),
+		))
+	})
+})
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// warmPoolReplicas returns the size of the warm pool, 0 without one
+func warmPoolReplicas(md *kaimeraaiv1.ModelDeployment) int32 {
+	if md.Spec.WarmPool == nil {
+		return 0
+	}
+
+	return md.Spec.WarmPool.Replicas
+}
+
+// readyWarmReplicas returns how many ready replicas of dp are warm. Ready
+// replicas serve the demand, all replicas but the warm pool, first, and
+// only those beyond it are counted, up to the size of the pool.
+func readyWarmReplicas(md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) int32 {
+	size := warmPoolReplicas(md)
+	if size == 0 || dp.Spec.Replicas == nil {
+		return 0
+	}
+
+	demand := *dp.Spec.Replicas - size
+	warm := dp.Status.ReadyReplicas - demand
+	if warm < 0 {
+		return 0
+	}
+	if warm > size {
+		return size
+	}
+
+	return warm
+}
+
+// reconcileWarmPool records the ready warm replicas in status
+func (r *ModelDeploymentReconciler) reconcileWarmPool(ctx context.Context, md *kaimeraaiv1.ModelDeployment, dp *appsv1.Deployment) error {
+	warm := readyWarmReplicas(md, dp)
+	if warm == md.Status.WarmReplicas {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("warm replicas changed", "warm", warm, "size", warmPoolReplicas(md))
+	md.Status.WarmReplicas = warm
+	return r.updateStatus(ctx, md)
+}
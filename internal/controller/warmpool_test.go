@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment warm pool", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "warm",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Replicas:  3,
+				WarmPool:  &kaimeraaiv1.WarmPoolSpec{Replicas: 2},
+			},
+		}
+	})
+
+	It("should add the pool to the replicas", func() {
+		deploy, err := (&ModelDeploymentReconciler{Scheme: scheme.Scheme}).generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(5))
+
+		md.Spec.Replicas = 0
+		md.Spec.ReplicasPerNode = 1
+		md.Status.NodeReplicas = 4
+		Expect(desiredReplicas(md)).To(BeEquivalentTo(6))
+	})
+
+	It("should add the pool to both bounds of the autoscaler", func() {
+		md.Spec.Replicas = 0
+		min := int32(2)
+		md.Spec.Autoscaling = &kaimeraaiv1.AutoscalingSpec{MinReplicas: &min, MaxReplicas: 8}
+
+		hpa, err := (&ModelDeploymentReconciler{Scheme: scheme.Scheme}).generateHPA(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*hpa.Spec.MinReplicas).To(BeEquivalentTo(4))
+		Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
+
+		md.Spec.Autoscaling.MinReplicas = nil
+		Expect(minReplicas(md)).To(BeEquivalentTo(3))
+	})
+
+	DescribeTable("should count the ready replicas beyond the demand as warm",
+		func(replicas, ready, warm int32) {
+			dp := &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+			}
+			Expect(readyWarmReplicas(md, dp)).To(Equal(warm))
+		},
+		Entry("while the demand is not served", int32(5), int32(2), int32(0)),
+		Entry("once the demand is served", int32(5), int32(3), int32(0)),
+		Entry("while the pool fills", int32(5), int32(4), int32(1)),
+		Entry("with the pool full", int32(5), int32(5), int32(2)),
+		Entry("during a surge", int32(5), int32(6), int32(2)),
+	)
+
+	It("should record the warm replicas in status", func() {
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+		replicas := int32(5)
+		dp := &appsv1.Deployment{
+			Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 4},
+		}
+
+		Expect(reconciler.reconcileWarmPool(ctx, md, dp)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), md)).To(Succeed())
+		Expect(md.Status.WarmReplicas).To(BeEquivalentTo(1))
+	})
+})
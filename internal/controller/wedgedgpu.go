@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+const (
+	// wedgedGPUTaint is set on the nodes of wedged GPUs by the Taint action
+	wedgedGPUTaint = "kaimera.ai/wedged-gpu"
+
+	defaultLivenessFailureThreshold = 5
+
+	// The kubelet records probe failures as Unhealthy events on the pod
+	unhealthyReason      = "Unhealthy"
+	livenessFailedPrefix = "Liveness probe failed"
+)
+
+// reconcileWedgedGPUs reports the nodes of pods whose liveness probe failed
+// at least the threshold times, and cordons or taints one of them at a time
+// when configured and allowed by the controller. When all replicas fail,
+// the model is more likely at fault than the GPUs and nodes are left alone.
+func (r *ModelDeploymentReconciler) reconcileWedgedGPUs(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	spec := md.Spec.WedgedGPU
+	if spec == nil {
+		return nil
+	}
+	threshold := spec.LivenessFailureThreshold
+	if threshold == 0 {
+		threshold = defaultLivenessFailureThreshold
+	}
+
+	pods := corev1.PodList{}
+	err := r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{modelDeploymentLabel: md.Name})
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		return nil
+	}
+
+	events := corev1.EventList{}
+	err = r.List(ctx, &events, client.InNamespace(md.Namespace), client.MatchingFields{"reason": unhealthyReason})
+	if err != nil {
+		return err
+	}
+
+	scheduled := 0
+	var wedged []*corev1.Pod
+	failures := map[string]int32{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		scheduled++
+
+		failures[pod.Name] = livenessFailures(pod, events.Items)
+		if failures[pod.Name] >= threshold {
+			wedged = append(wedged, pod)
+		}
+	}
+	if len(wedged) == 0 {
+		return nil
+	}
+	if len(wedged) == scheduled {
+		r.warningEvent(md, "LivenessFailing", fmt.Sprintf("liveness probes of all %d replicas failed at least %d times, "+
+			"leaving their nodes as the model is more likely at fault than the GPUs", scheduled, threshold))
+		return nil
+	}
+
+	remediating, err := r.remediatingWedgedGPUNode(ctx, md)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range wedged {
+		r.warningEvent(md, "WedgedGPU", fmt.Sprintf("liveness probe of pod %q failed %d times, the GPUs of node %q may be wedged",
+			pod.Name, failures[pod.Name], pod.Spec.NodeName))
+		if remediating {
+			continue
+		}
+
+		remediating, err = r.remediateNode(ctx, md, pod.Spec.NodeName)
+		if err != nil {
+			return err
+		}
+		if remediating {
+			md.Status.WedgedGPUNode = pod.Spec.NodeName
+			err = r.updateStatus(ctx, md)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// remediatingWedgedGPUNode returns whether the node last taken out of
+// scheduling for wedged GPUs still is, forgetting it once it is back
+func (r *ModelDeploymentReconciler) remediatingWedgedGPUNode(ctx context.Context, md *kaimeraaiv1.ModelDeployment) (bool, error) {
+	if md.Status.WedgedGPUNode == "" {
+		return false, nil
+	}
+
+	node := corev1.Node{}
+	err := r.Get(ctx, client.ObjectKey{Name: md.Status.WedgedGPUNode}, &node)
+	if client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+	if err == nil && (node.Spec.Unschedulable || hasWedgedGPUTaint(&node)) {
+		return true, nil
+	}
+
+	md.Status.WedgedGPUNode = ""
+	return false, r.updateStatus(ctx, md)
+}
+
+// hasWedgedGPUTaint returns whether node is tainted by the Taint action
+func hasWedgedGPUTaint(node *corev1.Node) bool {
+	return slices.ContainsFunc(node.Spec.Taints, func(taint corev1.Taint) bool {
+		return taint.Key == wedgedGPUTaint
+	})
+}
+
+// livenessFailures counts the liveness probe failures of pod in events
+func livenessFailures(pod *corev1.Pod, events []corev1.Event) int32 {
+	var failures int32
+	for _, event := range events {
+		object := event.InvolvedObject
+		if object.Kind != "Pod" || object.Name != pod.Name || (object.UID != "" && object.UID != pod.UID) {
+			continue
+		}
+		if event.Reason != unhealthyReason || !strings.HasPrefix(event.Message, livenessFailedPrefix) {
+			continue
+		}
+
+		// Repeated events are aggregated into one with a count
+		failures += max(event.Count, 1)
+	}
+
+	return failures
+}
+
+// remediateNode cordons or taints the node of wedged GPUs, depending on the
+// action of the spec, and returns whether it is out of scheduling. Nodes are
+// left alone unless the controller allows it.
+func (r *ModelDeploymentReconciler) remediateNode(ctx context.Context, md *kaimeraaiv1.ModelDeployment, nodeName string) (bool, error) {
+	action := md.Spec.WedgedGPU.Action
+	if action == "" || action == kaimeraaiv1.WedgedGPUActionEvent {
+		return false, nil
+	}
+	if !r.AllowNodeRemediation {
+		log.FromContext(ctx).Info("node remediation is not allowed, leaving node", "node", nodeName, "action", action)
+		return false, nil
+	}
+
+	node := corev1.Node{}
+	err := r.Get(ctx, client.ObjectKey{Name: nodeName}, &node)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	patch := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	message := fmt.Sprintf("cordoned node %q of wedged GPUs", nodeName)
+	switch action {
+	case kaimeraaiv1.WedgedGPUActionCordon:
+		if node.Spec.Unschedulable {
+			return true, nil
+		}
+		node.Spec.Unschedulable = true
+	case kaimeraaiv1.WedgedGPUActionTaint:
+		if hasWedgedGPUTaint(&node) {
+			return true, nil
+		}
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+			Key:    wedgedGPUTaint,
+			Effect: corev1.TaintEffectNoSchedule,
+		})
+		message = fmt.Sprintf("tainted node %q of wedged GPUs with %s", nodeName, wedgedGPUTaint)
+	}
+
+	log.FromContext(ctx).Info("taking node of wedged gpus out of scheduling", "node", nodeName, "action", action)
+	err = r.Patch(ctx, &node, patch)
+	if err != nil {
+		return false, err
+	}
+	r.warningEvent(md, "NodeRemediated", message)
+	return true, nil
+}
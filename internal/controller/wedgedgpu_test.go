@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment wedged GPUs", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+	var pod, healthy *corev1.Pod
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "wedged",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName: "facebook/opt-125m",
+				Runtime:   "gpu",
+				WedgedGPU: &kaimeraaiv1.WedgedGPUSpec{
+					LivenessFailureThreshold: 5,
+					Action:                   kaimeraaiv1.WedgedGPUActionCordon,
+				},
+			},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "wedged-7d9f8-abcde",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "wedged", modelDeploymentLabel: "wedged"},
+				UID:       "pod-uid",
+			},
+			Spec: corev1.PodSpec{NodeName: "gpu-1"},
+		}
+		healthy = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "wedged-7d9f8-fghij",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "wedged", modelDeploymentLabel: "wedged"},
+				UID:       "healthy-uid",
+			},
+			Spec: corev1.PodSpec{NodeName: "gpu-3"},
+		}
+		node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "gpu-1"}}
+	})
+
+	podLivenessEvent := func(pod *corev1.Pod, name string, count int32) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      "Pod",
+				Namespace: "default",
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+			Reason:  "Unhealthy",
+			Message: "Liveness probe failed: Get \"http://10.0.0.1:8000/health\": context deadline exceeded",
+			Count:   count,
+			Type:    corev1.EventTypeWarning,
+		}
+	}
+	livenessEvent := func(name string, count int32) *corev1.Event {
+		return podLivenessEvent(pod, name, count)
+	}
+
+	newReconciler := func(objs ...client.Object) *ModelDeploymentReconciler {
+		return &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(append(objs, md, pod, healthy, node)...).
+				WithStatusSubresource(md).
+				WithIndex(&corev1.Event{}, "reason", func(obj client.Object) []string {
+					return []string{obj.(*corev1.Event).Reason}
+				}).
+				Build(),
+			Scheme:               scheme.Scheme,
+			AllowNodeRemediation: true,
+		}
+	}
+
+	It("should cordon the node once the liveness failures reach the threshold", func() {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := newReconciler(
+			livenessEvent("liveness-1", 3),
+			livenessEvent("liveness-2", 1),
+			&corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: "readiness", Namespace: "default"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod.Name, UID: pod.UID},
+				Reason:         "Unhealthy",
+				Message:        "Readiness probe failed: HTTP probe failed with statuscode: 503",
+				Count:          10,
+			},
+		)
+		reconciler.Recorder = recorder
+
+		By("leaving the node below the threshold")
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(recorder.Events).To(BeEmpty())
+
+		By("cordoning it at the threshold")
+		event := livenessEvent("liveness-1", 4)
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(event), event)).To(Succeed())
+		event.Count = 4
+		Expect(reconciler.Update(ctx, event)).To(Succeed())
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+		Expect(recorder.Events).To(Receive(Equal(`Warning WedgedGPU liveness probe of pod "wedged-7d9f8-abcde" failed 5 times, ` +
+			`the GPUs of node "gpu-1" may be wedged`)))
+		Expect(recorder.Events).To(Receive(Equal(`Warning NodeRemediated cordoned node "gpu-1" of wedged GPUs`)))
+	})
+
+	It("should taint the node when configured", func() {
+		md.Spec.WedgedGPU.Action = kaimeraaiv1.WedgedGPUActionTaint
+		reconciler := newReconciler(livenessEvent("liveness", 5))
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(node.Spec.Taints).To(ConsistOf(corev1.Taint{Key: wedgedGPUTaint, Effect: corev1.TaintEffectNoSchedule}))
+	})
+
+	It("should only report the node unless remediation is allowed", func() {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := newReconciler(livenessEvent("liveness", 5))
+		reconciler.Recorder = recorder
+		reconciler.AllowNodeRemediation = false
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning WedgedGPU")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should not count the failures of a previous pod of the same name", func() {
+		event := livenessEvent("liveness", 5)
+		event.InvolvedObject.UID = "previous-uid"
+		reconciler := newReconciler(event)
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+	})
+
+	It("should cordon one node at a time until it is uncordoned", func() {
+		second := pod.DeepCopy()
+		second.Name = "wedged-7d9f8-klmno"
+		second.UID = "second-uid"
+		second.Spec.NodeName = "gpu-2"
+		secondNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "gpu-2"}}
+		reconciler := newReconciler(second, secondNode,
+			livenessEvent("liveness", 5), podLivenessEvent(second, "second-liveness", 5))
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(secondNode), secondNode)).To(Succeed())
+		Expect(secondNode.Spec.Unschedulable).To(BeFalse())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), md)).To(Succeed())
+		Expect(md.Status.WedgedGPUNode).To(Equal("gpu-1"))
+
+		By("cordoning the next node once the first is back")
+		node.Spec.Unschedulable = false
+		Expect(reconciler.Update(ctx, node)).To(Succeed())
+		Expect(reconciler.Delete(ctx, pod)).To(Succeed())
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(secondNode), secondNode)).To(Succeed())
+		Expect(secondNode.Spec.Unschedulable).To(BeTrue())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(md), md)).To(Succeed())
+		Expect(md.Status.WedgedGPUNode).To(Equal("gpu-2"))
+	})
+
+	It("should leave the nodes when the liveness probes of all replicas fail", func() {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := newReconciler(livenessEvent("liveness", 5), podLivenessEvent(healthy, "healthy-liveness", 5))
+		reconciler.Recorder = recorder
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(recorder.Events).To(Receive(Equal("Warning LivenessFailing liveness probes of all 2 replicas failed at least 5 times, " +
+			"leaving their nodes as the model is more likely at fault than the GPUs")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should ignore other pods with the same app label", func() {
+		other := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "wedged-client",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "wedged"},
+				UID:       "other-uid",
+			},
+			Spec: corev1.PodSpec{NodeName: "cpu-1"},
+		}
+		otherNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cpu-1"}}
+		reconciler := newReconciler(other, otherNode, podLivenessEvent(other, "other-liveness", 5))
+
+		Expect(reconciler.reconcileWedgedGPUs(ctx, md)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(otherNode), otherNode)).To(Succeed())
+		Expect(otherNode.Spec.Unschedulable).To(BeFalse())
+	})
+
+	It("should cordon the node of a crash looping pod", func() {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{
+				Name:  "vllm",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOffReason}},
+			},
+		}
+		reconciler := newReconciler(
+			livenessEvent("liveness", 5),
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "wedged", Namespace: "default"}},
+		)
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(md)})
+		Expect(err).To(BeAssignableToTypeOf(&degradedError{}))
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+	})
+})
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+// generateTopologySpreadConstraints returns the hard zone spread constraint
+// of strict zone balance, or nil when it is off
+func generateTopologySpreadConstraints(md *kaimeraaiv1.ModelDeployment) []corev1.TopologySpreadConstraint {
+	if !md.Spec.StrictZoneBalance {
+		return nil
+	}
+
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": md.Name,
+				},
+			},
+		},
+	}
+}
+
+// reconcileZoneReplicas records how many of the model's pods are scheduled
+// in each zone
+func (r *ModelDeploymentReconciler) reconcileZoneReplicas(ctx context.Context, md *kaimeraaiv1.ModelDeployment) error {
+	pods := corev1.PodList{}
+	err := r.List(ctx, &pods, client.InNamespace(md.Namespace), client.MatchingLabels{"app": md.Name})
+	if err != nil {
+		return err
+	}
+
+	zones := map[string]string{}
+	zoneReplicas := map[string]int32{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		zone, ok := zones[pod.Spec.NodeName]
+		if !ok {
+			node := corev1.Node{}
+			err := r.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, &node)
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			zone = node.Labels[corev1.LabelTopologyZone]
+			zones[pod.Spec.NodeName] = zone
+		}
+		if zone != "" {
+			zoneReplicas[zone]++
+		}
+	}
+
+	if equality.Semantic.DeepEqual(zoneReplicas, md.Status.ZoneReplicas) ||
+		len(zoneReplicas) == 0 && len(md.Status.ZoneReplicas) == 0 {
+		return nil
+	}
+
+	md.Status.ZoneReplicas = zoneReplicas
+	return r.updateStatus(ctx, md)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kaimeraaiv1 "github.com/kaimera-ai/kaimera/api/v1"
+)
+
+var _ = Describe("ModelDeployment strict zone balance", func() {
+	ctx := context.Background()
+
+	var md *kaimeraaiv1.ModelDeployment
+
+	BeforeEach(func() {
+		md = &kaimeraaiv1.ModelDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "balanced",
+				Namespace: "default",
+			},
+			Spec: kaimeraaiv1.ModelDeploymentSpec{
+				ModelName:         "facebook/opt-125m",
+				Replicas:          5,
+				StrictZoneBalance: true,
+			},
+		}
+	})
+
+	It("should spread the pods across zones with a hard constraint", func() {
+		reconciler := &ModelDeploymentReconciler{Scheme: scheme.Scheme}
+
+		deploy, err := reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Spec.Template.Spec.TopologySpreadConstraints).To(ConsistOf(corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: deploy.Spec.Selector.MatchLabels,
+			},
+		}))
+
+		md.Spec.StrictZoneBalance = false
+		deploy, err = reconciler.generateDeployment(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+	})
+
+	It("should report the scheduled replicas per zone", func() {
+		node := func(name, zone string) *corev1.Node {
+			return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{corev1.LabelTopologyZone: zone},
+			}}
+		}
+		pod := func(name, nodeName string) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: md.Namespace,
+					Labels:    map[string]string{"app": md.Name},
+				},
+				Spec: corev1.PodSpec{NodeName: nodeName},
+			}
+		}
+
+		reconciler := &ModelDeploymentReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(md,
+					node("a-1", "zone-a"), node("a-2", "zone-a"), node("b-1", "zone-b"), node("c-1", "zone-c"),
+					pod("p1", "a-1"), pod("p2", "a-2"), pod("p3", "b-1"), pod("p4", "b-1"), pod("p5", "c-1"),
+					pod("pending", ""),
+				).
+				WithStatusSubresource(md).
+				Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		Expect(reconciler.reconcileZoneReplicas(ctx, md)).To(Succeed())
+		Expect(md.Status.ZoneReplicas).To(Equal(map[string]int32{
+			"zone-a": 2,
+			"zone-b": 2,
+			"zone-c": 1,
+		}))
+	})
+})
@@ -1,27 +1,50 @@
 package proxy
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	kaimera "github.com/kaimera-ai/kaimera/api/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	// mirrorTimeout bounds how long a mirrored request may hold on to a
+	// connection to the shadow deployment
+	mirrorTimeout = 5 * time.Minute
+	// maxMirrors bounds the mirrored requests in flight; further requests
+	// are not mirrored until one finishes
+	maxMirrors = 64
+)
+
 type ProxyServer struct {
 	client client.Client
 	logger logr.Logger
+
+	// resourceNamePrefix is the prefix of the controller's generated
+	// Service names
+	resourceNamePrefix string
+
+	mirrorClient *http.Client
+	mirrors      chan struct{}
 }
 
-func New(client client.Client, logger logr.Logger) *ProxyServer {
+func New(client client.Client, logger logr.Logger, resourceNamePrefix string) *ProxyServer {
 	return &ProxyServer{
-		client: client,
-		logger: logger,
+		client:             client,
+		logger:             logger,
+		resourceNamePrefix: resourceNamePrefix,
+		mirrorClient:       &http.Client{Timeout: mirrorTimeout},
+		mirrors:            make(chan struct{}, maxMirrors),
 	}
 }
 
@@ -51,9 +74,10 @@ func (server *ProxyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	modelDeploymentName := modelDeploymentStringParts[2]
 
 	// Find the corresponding CRD and match with its name
+	md := kaimera.ModelDeployment{}
 	err := server.client.Get(r.Context(),
 		client.ObjectKey{Namespace: namespace, Name: modelDeploymentName},
-		&kaimera.ModelDeployment{})
+		&md)
 
 	if err != nil {
 		server.logger.Info("Error retrieving model deployment", "namespace", namespace, "modelDeploymentName", modelDeploymentName, "err", err)
@@ -62,9 +86,10 @@ func (server *ProxyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Route to the service at location nameoftheservice.namespace
-	// http://mymodeldeployment.mynamespace/v1/chat
+	// http://mymodeldeployment.mynamespace:80/v1/chat
 	pathFragment := strings.Join(modelDeploymentStringParts[3:], "/")
-	targetUrl := fmt.Sprintf("http://%s.%s/%s", modelDeploymentName, namespace, pathFragment)
+	serviceName := kaimera.PrefixedName(server.resourceNamePrefix, modelDeploymentName)
+	targetUrl := fmt.Sprintf("http://%s.%s:%d/%s", serviceName, namespace, md.Spec.ServicePort(), pathFragment)
 	url, err := url.Parse(targetUrl)
 	if err != nil {
 		server.logger.Info("Unable to parse URL", "error", err)
@@ -72,10 +97,65 @@ func (server *ProxyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if timeout := md.Spec.RequestTimeout(); timeout > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	if md.Spec.Shadow != nil {
+		shadowUrl := fmt.Sprintf("http://%s.%s:%d/%s", kaimera.PrefixedName(server.resourceNamePrefix, md.ShadowName()), namespace, md.Spec.ServicePort(), pathFragment)
+		server.mirror(r, shadowUrl)
+	}
+
 	reverseProxy := NewSingleHostReverseProxy(url)
 	reverseProxy.ServeHTTP(w, r)
 }
 
+// mirror sends a copy of the request to the shadow deployment in the
+// background. Its response is discarded and errors are only logged, so the
+// shadow never affects what the client sees. The request is not mirrored
+// while maxMirrors are in flight, so a slow shadow can't pile them up.
+func (server *ProxyServer) mirror(r *http.Request, targetUrl string) {
+	select {
+	case server.mirrors <- struct{}{}:
+	default:
+		server.logger.Info("Dropping shadow request, too many in flight", "url", targetUrl)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		<-server.mirrors
+		server.logger.Info("Unable to read request body for shadow", "error", err)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if r.URL.RawQuery != "" {
+		targetUrl += "?" + r.URL.RawQuery
+	}
+	req, err := http.NewRequestWithContext(context.Background(), r.Method, targetUrl, bytes.NewReader(body))
+	if err != nil {
+		<-server.mirrors
+		server.logger.Info("Unable to create shadow request", "error", err)
+		return
+	}
+	req.Header = r.Header.Clone()
+
+	go func() {
+		defer func() { <-server.mirrors }()
+
+		resp, err := server.mirrorClient.Do(req)
+		if err != nil {
+			server.logger.Info("Shadow request failed", "url", targetUrl, "error", err)
+			return
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}()
+}
+
 func NewSingleHostReverseProxy(target *url.URL) *httputil.ReverseProxy {
 	targetQuery := target.RawQuery
 	director := func(req *http.Request) {
@@ -0,0 +1,29 @@
+// Package version holds the build information of the controller. The
+// variables are set at build time with
+//
+//	-ldflags "-X github.com/kaimera-ai/kaimera/internal/version.Version=v0.1.0 -X github.com/kaimera-ai/kaimera/internal/version.Commit=abc1234"
+package version
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// Version is the released version of the controller
+	Version = "dev"
+
+	// Commit is the git commit the controller was built from
+	Commit = "unknown"
+)
+
+// buildInfo is always 1, the build information is in its labels
+var buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "kaimera_build_info",
+	Help: "Build information of the running controller, always 1.",
+}, []string{"version", "commit"})
+
+func init() {
+	metrics.Registry.MustRegister(buildInfo)
+	buildInfo.WithLabelValues(Version, Commit).Set(1)
+}
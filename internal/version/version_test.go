@@ -0,0 +1,33 @@
+package version
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("Build info metric", func() {
+	It("should be registered with the version and commit", func() {
+		families, err := metrics.Registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+
+		labels := map[string]string{}
+		for _, family := range families {
+			if family.GetName() != "kaimera_build_info" {
+				continue
+			}
+
+			Expect(family.GetMetric()).To(HaveLen(1))
+			metric := family.GetMetric()[0]
+			Expect(metric.GetGauge().GetValue()).To(Equal(1.0))
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+		}
+
+		Expect(labels).To(Equal(map[string]string{
+			"version": Version,
+			"commit":  Commit,
+		}))
+	})
+})